@@ -0,0 +1,88 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamavChunkSize ukuran potongan INSTREAM, harus di bawah StreamMaxLength clamd
+const clamavChunkSize = 64 * 1024
+
+// ClamAVScanner mengirim isi file ke clamd lewat perintah INSTREAM (TCP, default port 3310)
+type ClamAVScanner struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewClamAVScanner(addr string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{addr: addr, timeout: timeout}
+}
+
+// Scan protokol INSTREAM: "zINSTREAM\0", lalu potongan [panjang uint32 big-endian][data], diakhiri panjang 0.
+// Balasan "stream: OK" atau "stream: <nama virus> FOUND"
+func (s *ClamAVScanner) Scan(ctx context.Context, body io.Reader) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if s.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	writer := bufio.NewWriter(conn)
+	if _, err := writer.WriteString("zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	chunk := make([]byte, clamavChunkSize)
+	var size [4]byte
+	for {
+		n, readErr := body.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			writer.Write(size[:])
+			if _, err := writer.Write(chunk[:n]); err != nil {
+				return fmt.Errorf("%w: %v", ErrUnavailable, err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	writer.Write(size[:])
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return parseClamAVReply(reply)
+}
+
+func parseClamAVReply(reply string) error {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &InfectedError{Signature: strings.TrimSuffix(result, " FOUND")}
+	}
+	// misal "INSTREAM size limit exceeded. ERROR"
+	return fmt.Errorf("%w: %s", ErrUnavailable, reply)
+}
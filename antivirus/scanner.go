@@ -0,0 +1,37 @@
+package antivirus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnavailable pemindai tidak bisa dihubungi. Upload ditolak (fail closed) supaya file tidak lolos tanpa dipindai
+var ErrUnavailable = errors.New("layanan pemindai virus tidak tersedia")
+
+// InfectedError file terdeteksi mengandung virus / malware
+type InfectedError struct {
+	Signature string
+}
+
+func (e *InfectedError) Error() string {
+	return fmt.Sprintf("file terdeteksi mengandung malware (%s)", e.Signature)
+}
+
+// Scanner memindai isi file sebelum disimpan. Implementasi lain (VirusTotal, dll) tinggal memenuhi interface ini.
+// Mengembalikan *InfectedError kalau file terinfeksi
+type Scanner interface {
+	Scan(ctx context.Context, body io.Reader) error
+}
+
+// NopScanner tidak memindai apa pun, untuk development lokal tanpa ClamAV
+type NopScanner struct{}
+
+func NewNopScanner() *NopScanner {
+	return &NopScanner{}
+}
+
+func (s *NopScanner) Scan(ctx context.Context, body io.Reader) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipe data field details yang dikenali validasi prestasi
+const (
+	FieldTypeString     = "string"
+	FieldTypeNumber     = "number"
+	FieldTypeInteger    = "integer"
+	FieldTypeDate       = "date" // YYYY-MM-DD atau RFC3339
+	FieldTypeStringList = "string_list"
+	FieldTypePeriod     = "period" // {start: date, end: date}
+	FieldTypeISSN       = "issn"
+	FieldTypeObject     = "object"
+)
+
+// AchievementFieldDefinition satu field di details untuk tipe prestasi tertentu
+type AchievementFieldDefinition struct {
+	Name     string   `json:"name"`
+	DataType string   `json:"data_type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // nilai yang diizinkan (enum), kosong berarti bebas
+}
+
+// AchievementTypeDefinition skema details untuk satu achievement_type (tabel achievement_types).
+// Key dipakai di AchievementDetail.AchievementType dan tidak bisa diubah setelah dibuat
+type AchievementTypeDefinition struct {
+	ID            uuid.UUID                    `json:"id" db:"id"`
+	Key           string                       `json:"key" db:"key"`
+	Label         string                       `json:"label" db:"label"`
+	Fields        []AchievementFieldDefinition `json:"fields" db:"fields"`
+	DefaultPoints int                          `json:"default_points" db:"default_points"`
+	IsActive      bool                         `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time                    `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time                    `json:"updated_at" db:"updated_at"`
+}
+
+type CreateAchievementTypeRequest struct {
+	Key           string                       `json:"key"`
+	Label         string                       `json:"label"`
+	Fields        []AchievementFieldDefinition `json:"fields"`
+	DefaultPoints int                          `json:"default_points"`
+}
+
+// UpdateAchievementTypeRequest - field nil tidak diubah
+type UpdateAchievementTypeRequest struct {
+	Label         *string                       `json:"label"`
+	Fields        *[]AchievementFieldDefinition `json:"fields"`
+	DefaultPoints *int                          `json:"default_points"`
+	IsActive      *bool                         `json:"is_active"`
+}
+
+// FieldError kesalahan validasi pada satu field, misal {"field": "details.rank", "message": "harus bilangan bulat"}
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -7,11 +7,23 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Status prestasi, selalu huruf kecil. Transisi yang sah diatur service.AchievementWorkflow
+const (
+	AchievementStatusDraft         = "draft"
+	AchievementStatusSubmitted     = "submitted"
+	AchievementStatusNeedsRevision = "needs_revision"
+	AchievementStatusVerified      = "verified"
+	AchievementStatusRejected      = "rejected"
+)
+
 type Attachment struct {
-	FileName   string    `bson:"fileName" json:"fileName"`
-	FileURL    string    `bson:"fileUrl" json:"fileUrl"`
-	FileType   string    `bson:"fileType" json:"fileType"`
-	UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+	FileID       string    `bson:"fileId,omitempty" json:"fileId,omitempty"` // files.id dari /achievements/upload
+	FileName     string    `bson:"fileName" json:"fileName"`
+	FileURL      string    `bson:"fileUrl" json:"fileUrl"`
+	FileType     string    `bson:"fileType" json:"fileType"`
+	ThumbnailURL string    `bson:"thumbnailUrl,omitempty" json:"thumbnailUrl,omitempty"` // preview JPEG kecil untuk daftar verifikasi
+	Hash         string    `bson:"hash,omitempty" json:"hash,omitempty"`                 // SHA-256 isi file, dipakai deteksi duplikat
+	UploadedAt   time.Time `bson:"uploadedAt" json:"uploadedAt"`
 }
 
 type AchievementDetail struct {
@@ -26,6 +38,8 @@ type AchievementDetail struct {
 
 	Tags   []string `bson:"tags" json:"tags"`
 	Points int      `bson:"points" json:"points"`
+	// ScoreBreakdown diisi service.ScoringEngine saat prestasi diverifikasi
+	ScoreBreakdown *ScoreBreakdown `bson:"score_breakdown,omitempty" json:"score_breakdown,omitempty"`
 
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
@@ -35,7 +49,7 @@ type AchievementReference struct {
 	ID                 uuid.UUID  `json:"id" db:"id"`
 	StudentID          uuid.UUID  `json:"student_id" db:"student_id"`
 	MongoAchievementID string     `json:"mongo_achievement_id" db:"mongo_achievement_id"`
-	Status             string     `json:"status" db:"status"` // draft, submitted, verified, rejected
+	Status             string     `json:"status" db:"status"` // draft, submitted, needs_revision, verified, rejected
 	SubmittedAt        time.Time  `json:"submitted_at" db:"submitted_at"`
 	VerifiedAt         *time.Time `json:"verified_at" db:"verified_at"`
 	VerifiedBy         *uuid.UUID `json:"verified_by" db:"verified_by"`
@@ -51,10 +65,43 @@ type CreateAchievementRequest struct {
 	Description string                 `json:"description"`
 	Details     map[string]interface{} `json:"details"`
 	Tags        []string               `json:"tags"`
-	Attachments []Attachment           `json:"attachments"`
+	Attachments []AttachmentInput      `json:"attachments"`
+}
+
+// AttachmentInput lampiran dari client hanya berupa fileId hasil /achievements/upload milik sendiri,
+// nama, tipe, hash dan URL diisi server dari tabel files
+type AttachmentInput struct {
+	FileID string `json:"fileId"`
 }
 
 type VerifyAchievementRequest struct {
-	Status string `json:"status"` // "verified" / "rejected"
-	Notes  string `json:"notes"`  // alasam
+	Status        string            `json:"status"`         // "verified" / "rejected" / "needs_revision"
+	Notes         string            `json:"notes"`          // alasam
+	FieldComments map[string]string `json:"field_comments"` // komentar per field, misal {"details.rank": "lampirkan sertifikat"}
+}
+
+// AchievementStatusChange satu baris riwayat di tabel achievement_status_history
+type AchievementStatusChange struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	AchievementID uuid.UUID  `json:"achievement_id" db:"achievement_id"`
+	FromStatus    string     `json:"from_status" db:"from_status"`
+	ToStatus      string     `json:"to_status" db:"to_status"`
+	ActorID       *uuid.UUID `json:"actor_id" db:"actor_id"`
+	Note          string     `json:"note" db:"note"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DuplicateCandidate prestasi lain yang kemungkinan sama dengan prestasi yang sedang diperiksa
+type DuplicateCandidate struct {
+	AchievementID uuid.UUID `json:"achievement_id"`
+	StudentID     uuid.UUID `json:"student_id"`
+	Title         string    `json:"title"`
+	Status        string    `json:"status"`
+	Score         float64   `json:"score"`   // 0-1, makin besar makin mirip
+	Reasons       []string  `json:"reasons"` // misal "judul mirip (92%)", "lampiran identik"
+}
+
+// AchievementTransitionRequest body untuk withdraw / reopen, catatan opsional
+type AchievementTransitionRequest struct {
+	Note string `json:"note"`
 }
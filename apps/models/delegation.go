@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VerificationDelegation dosen wali (AdvisorID) menitipkan hak verifikasi ke dosen lain (DelegateID)
+// selama rentang StartsAt..EndsAt, misalnya saat cuti. Keduanya mengacu ke lecturers.id.
+type VerificationDelegation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	AdvisorID  uuid.UUID  `json:"advisor_id" db:"advisor_id"`
+	DelegateID uuid.UUID  `json:"delegate_id" db:"delegate_id"`
+	StartsAt   time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at" db:"ends_at"`
+	Reason     string     `json:"reason" db:"reason"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ActiveAt true kalau delegasi belum dicabut dan t ada di dalam rentang tanggalnya
+func (d VerificationDelegation) ActiveAt(t time.Time) bool {
+	return d.RevokedAt == nil && !t.Before(d.StartsAt) && t.Before(d.EndsAt)
+}
+
+// CreateDelegationRequest - tanggal format YYYY-MM-DD, end_date ikut dihitung (inklusif)
+type CreateDelegationRequest struct {
+	DelegateID string `json:"delegate_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Reason     string `json:"reason"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoredFile metadata file yang diupload (tabel files). Isi file ada di storage.FileStore dengan key
+// berdasarkan SHA-256, sehingga upload dengan isi sama memakai satu objek yang sama
+type StoredFile struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	OwnerID      uuid.UUID `json:"owner_id" db:"owner_id"`
+	SHA256       string    `json:"sha256" db:"sha256"`
+	StorageKey   string    `json:"storage_key" db:"storage_key"`
+	OriginalName string    `json:"original_name" db:"original_name"`
+	MimeType     string    `json:"mime_type" db:"mime_type"`
+	Size         int64     `json:"size" db:"size"`
+	// ThumbnailKey key preview JPEG di storage, kosong kalau preview tidak bisa dibuat
+	ThumbnailKey string `json:"thumbnail_key,omitempty" db:"thumbnail_key"`
+	// AchievementID prestasi yang memakai file ini, nil selama belum dilampirkan (dihapus sweeper setelah FILE_ORPHAN_TTL)
+	AchievementID *uuid.UUID `json:"achievement_id" db:"achievement_id"`
+	LinkedAt      *time.Time `json:"linked_at" db:"linked_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
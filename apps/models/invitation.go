@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation undangan membuat akun dengan role tertentu (misal dosen). Token asli hanya dikirim lewat notifikasi.
+type Invitation struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Email      string     `json:"email" db:"email"`
+	FullName   string     `json:"full_name" db:"full_name"`
+	RoleID     uuid.UUID  `json:"role_id" db:"role_id"`
+	Role       *Role      `json:"role,omitempty" db:"-"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	InvitedBy  uuid.UUID  `json:"invited_by" db:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty" db:"accepted_at"`
+	AcceptedBy *uuid.UUID `json:"accepted_by,omitempty" db:"accepted_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreateInvitationRequest - RoleID kosong berarti role dosen
+type CreateInvitationRequest struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	RoleID   string `json:"role_id"`
+}
+
+// AcceptInvitationRequest - email diambil dari undangan, tidak bisa diganti
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Password string `json:"password"`
+}
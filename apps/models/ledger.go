@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis baris di point_ledger
+const (
+	LedgerEntryAward      = "award"      // prestasi diverifikasi
+	LedgerEntryAdjustment = "adjustment" // selisih setelah poin dihitung ulang
+	LedgerEntryReversal   = "reversal"   // verifikasi dicabut, menihilkan poin prestasi
+)
+
+// PointLedgerEntry satu baris buku poin mahasiswa (tabel point_ledger). Baris tidak pernah diubah atau dihapus,
+// koreksi selalu berupa baris baru sehingga total poin = SUM(points)
+type PointLedgerEntry struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	StudentID     uuid.UUID  `json:"student_id" db:"student_id"`
+	AchievementID uuid.UUID  `json:"achievement_id" db:"achievement_id"`
+	EntryType     string     `json:"entry_type" db:"entry_type"`
+	Points        int        `json:"points" db:"points"`
+	Category      string     `json:"category" db:"category"`           // achievement_type
+	AcademicYear  string     `json:"academic_year" db:"academic_year"` // tahun akademik prestasi, misal 2025/2026
+	RulesVersion  string     `json:"rules_version" db:"rules_version"`
+	Note          string     `json:"note" db:"note"`
+	CreatedBy     *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt menghitung percobaan login gagal untuk satu key (user:<id>, login:<nama> atau ip:<alamat>)
+type LoginAttempt struct {
+	Key            string     `json:"key" db:"key"`
+	Failures       int        `json:"failures" db:"failures"`
+	FirstFailureAt time.Time  `json:"first_failure_at" db:"first_failure_at"`
+	LastFailureAt  time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+}
+
+const (
+	LoginOutcomeSuccess         = "success"
+	LoginOutcomeInvalidPassword = "invalid_password"
+	LoginOutcomeUnknownUser     = "unknown_user"
+	LoginOutcomeInactive        = "inactive"
+	LoginOutcomeThrottled       = "throttled"
+	LoginOutcomeLocked          = "locked"
+	LoginOutcomeUnlocked        = "unlocked"
+	LoginOutcomeMFAPending      = "mfa_pending"
+	LoginOutcomeMFAFailed       = "mfa_failed"
+)
+
+// LoginEvent catatan audit setiap percobaan login
+type LoginEvent struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Login     string     `json:"login" db:"login"`
+	IPAddress string     `json:"ip_address" db:"ip_address"`
+	UserAgent string     `json:"user_agent" db:"user_agent"`
+	Outcome   string     `json:"outcome" db:"outcome"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type LoginEventFilter struct {
+	UserID    *uuid.UUID
+	IPAddress string
+	Limit     int
+}
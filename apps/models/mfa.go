@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA data TOTP milik user. SecretEncrypted dienkripsi AES-GCM, jangan pernah dikirim ke client.
+// Enabled masih false selama user belum konfirmasi kode pertama.
+type UserMFA struct {
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	SecretEncrypted string     `json:"-" db:"secret_encrypted"`
+	Enabled         bool       `json:"enabled" db:"enabled"`
+	LastUsedStep    int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+	DeviceLabel  string `json:"device_label"`
+}
+
+type MFADisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type RoleMFARequirementRequest struct {
+	Required bool `json:"required"`
+}
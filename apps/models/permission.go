@@ -10,6 +10,11 @@ type Permission struct{
 	Description string    `json:"description" db:"description"`
 }
 
+// Key mengembalikan pasangan resource:action, format yang dipakai middleware.RequirePermission
+func (p Permission) Key() string {
+	return p.Resource + ":" + p.Action
+}
+
 type CreatePermissionRequest struct {
 	Name        string `json:"name"`
 	Resource    string `json:"resource"`
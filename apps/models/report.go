@@ -8,10 +8,11 @@ type StatisticsResponse struct {
 }
 
 type AchievementStatistics struct {
-	Draft     int `json:"draft"`
-	Submitted int `json:"submitted"`
-	Verified  int `json:"verified"`
-	Rejected  int `json:"rejected"`
+	Draft         int `json:"draft"`
+	Submitted     int `json:"submitted"`
+	NeedsRevision int `json:"needs_revision"`
+	Verified      int `json:"verified"`
+	Rejected      int `json:"rejected"`
 }
 
 type StudentReportResponse struct {
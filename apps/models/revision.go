@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementReview hasil pemeriksaan dosen untuk satu ronde submit
+type AchievementReview struct {
+	Status        string            `bson:"status" json:"status"`
+	Notes         string            `bson:"notes" json:"notes"`
+	FieldComments map[string]string `bson:"field_comments,omitempty" json:"field_comments,omitempty"`
+	ReviewerID    string            `bson:"reviewer_id" json:"reviewer_id"`
+	ReviewedAt    time.Time         `bson:"reviewed_at" json:"reviewed_at"`
+}
+
+// AchievementRevision snapshot AchievementDetail setiap kali prestasi disubmit (collection achievement_revisions).
+// Round dimulai dari 1 dan naik setiap submit ulang.
+type AchievementRevision struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID string             `bson:"achievement_id" json:"achievement_id"`
+	Round         int                `bson:"round" json:"round"`
+	Detail        AchievementDetail  `bson:"detail" json:"detail"`
+	SubmittedBy   string             `bson:"submitted_by" json:"submitted_by"`
+	SubmittedAt   time.Time          `bson:"submitted_at" json:"submitted_at"`
+	Review        *AchievementReview `bson:"review,omitempty" json:"review,omitempty"`
+}
+
+// FieldChange satu perbedaan field antara dua snapshot. Field nested memakai titik, misal details.rank
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Change string      `json:"change"` // added, removed, changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// AchievementVersion isi prestasi sebelum diedit (collection achievement_versions).
+// Version n adalah isi sebelum perubahan ke-n, EditedBy / EditedAt mencatat siapa dan kapan perubahan itu dilakukan.
+type AchievementVersion struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID string             `bson:"achievement_id" json:"achievement_id"`
+	Version       int                `bson:"version" json:"version"`
+	Detail        AchievementDetail  `bson:"detail" json:"detail"`
+	EditedBy      string             `bson:"edited_by" json:"edited_by"`
+	EditedAt      time.Time          `bson:"edited_at" json:"edited_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type CreateRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RolePermissionRequest struct {
+	PermissionID string `json:"permission_id"`
+}
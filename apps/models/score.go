@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ScoringRules tabel aturan poin per achievement_type, dibaca dari file JSON (SCORING_RULES_FILE).
+// Poin = base_points x semua pengali factor, dibulatkan
+type ScoringRules struct {
+	Version string                     `json:"version"`
+	Types   map[string]TypeScoringRule `json:"types"`
+}
+
+type TypeScoringRule struct {
+	// BasePoints kosong berarti memakai achievement_types.default_points
+	BasePoints *float64        `json:"base_points,omitempty"`
+	Factors    []ScoringFactor `json:"factors"`
+}
+
+// ScoringFactor satu pengali berdasarkan field details.
+// Kind "lookup": nilai field (huruf kecil) dicari di Values. Kind "years": panjang period dalam tahun, dibatasi Default..Max
+type ScoringFactor struct {
+	Field   string             `json:"field"`
+	Kind    string             `json:"kind"`
+	Values  map[string]float64 `json:"values,omitempty"`
+	Default float64            `json:"default"`
+	Max     float64            `json:"max,omitempty"`
+}
+
+// ScoreBreakdown penjelasan asal poin, disimpan di dokumen prestasi (score_breakdown)
+type ScoreBreakdown struct {
+	AchievementType string        `bson:"achievement_type" json:"achievement_type"`
+	BasePoints      float64       `bson:"base_points" json:"base_points"`
+	Factors         []ScoreFactor `bson:"factors" json:"factors"`
+	Total           int           `bson:"total" json:"total"`
+	RulesVersion    string        `bson:"rules_version" json:"rules_version"`
+	ComputedAt      time.Time     `bson:"computed_at" json:"computed_at"`
+}
+
+type ScoreFactor struct {
+	Field      string      `bson:"field" json:"field"`
+	Value      interface{} `bson:"value" json:"value"`
+	Multiplier float64     `bson:"multiplier" json:"multiplier"`
+	Note       string      `bson:"note,omitempty" json:"note,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken adalah satu sesi login. Token aslinya tidak disimpan, hanya hash SHA-256.
+// FamilyID sama untuk semua token hasil rotasi dari satu login.
+type RefreshToken struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	FamilyID    uuid.UUID  `json:"family_id" db:"family_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	DeviceLabel string     `json:"device_label" db:"device_label"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	ReplacedBy  *uuid.UUID `json:"replaced_by,omitempty" db:"replaced_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
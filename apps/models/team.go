@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Peran anggota prestasi tim
+const (
+	TeamRoleLeader = "leader"
+	TeamRoleMember = "member"
+)
+
+// Status undangan anggota tim. Pelapor (pemilik achievement_references) langsung accepted
+const (
+	TeamMemberInvited  = "invited"
+	TeamMemberAccepted = "accepted"
+	TeamMemberDeclined = "declined"
+)
+
+// AchievementMember satu anggota prestasi tim (tabel achievement_members). Semua anggota berbagi
+// satu achievement_references milik pelapor dan satu dokumen MongoDB
+type AchievementMember struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	AchievementID uuid.UUID  `json:"achievement_id" db:"achievement_id"`
+	StudentID     uuid.UUID  `json:"student_id" db:"student_id"`
+	Role          string     `json:"role" db:"role"`               // leader, member
+	Status        string     `json:"status" db:"status"`           // invited, accepted, declined
+	PointShare    int        `json:"point_share" db:"point_share"` // persen poin, 0 semua berarti dibagi rata
+	InvitedBy     *uuid.UUID `json:"invited_by" db:"invited_by"`
+	RespondedAt   *time.Time `json:"responded_at" db:"responded_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type InviteTeamMemberRequest struct {
+	StudentID string `json:"student_id"`
+	Role      string `json:"role"` // default member
+}
+
+type TeamMemberAllocation struct {
+	StudentID  string `json:"student_id"`
+	Role       string `json:"role"`
+	PointShare int    `json:"point_share"`
+}
+
+// UpdateTeamMembersRequest peran dan pembagian poin untuk semua anggota yang belum menolak
+type UpdateTeamMembersRequest struct {
+	Members []TeamMemberAllocation `json:"members"`
+}
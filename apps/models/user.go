@@ -6,42 +6,75 @@ import (
 	"github.com/google/uuid"
 )
 
-
-type Role struct{
-	ID uuid.UUID `json:"id" db:"id"`
-	Name string `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+type Role struct {
+	ID          uuid.UUID    `json:"id" db:"id"`
+	Name        string       `json:"name" db:"name"`
+	Description string       `json:"description" db:"description"`
+	MFARequired bool         `json:"mfa_required" db:"mfa_required"`
+	Permissions []Permission `json:"permissions,omitempty" db:"-"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 }
 
-
 type User struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"` 
-	FullName     string    `json:"full_name" db:"full_name"`
-	RoleID       uuid.UUID `json:"role_id" db:"role_id"`
-	Role         *Role     `json:"role,omitempty" db:"-"` 
-	IsActive     bool      `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	Username           string     `json:"username" db:"username"`
+	Email              string     `json:"email" db:"email"`
+	PasswordHash       string     `json:"-" db:"password_hash"`
+	FullName           string     `json:"full_name" db:"full_name"`
+	RoleID             uuid.UUID  `json:"role_id" db:"role_id"`
+	Role               *Role      `json:"role,omitempty" db:"-"`
+	IsActive           bool       `json:"is_active" db:"is_active"`
+	DeactivatedAt      *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	DeactivationReason *string    `json:"deactivation_reason,omitempty" db:"deactivation_reason"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+type DeactivateUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RegisterRequest dipakai registrasi mandiri, role selalu role mahasiswa
 type RegisterRequest struct {
 	Username string `json:"username" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 	FullName string `json:"full_name" validate:"required"`
-	RoleID   string `json:"role_id" validate:"required"` 
 }
 
-type LoginRequest struct {
+// CreateUserRequest dipakai admin untuk membuat user dengan role apa pun
+type CreateUserRequest struct {
 	Username string `json:"username" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	FullName string `json:"full_name" validate:"required"`
+	RoleID   string `json:"role_id" validate:"required"`
+}
+
+type LoginRequest struct {
+	Username    string `json:"username" validate:"required"`
+	Password    string `json:"password" validate:"required"`
+	DeviceLabel string `json:"device_label"`
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
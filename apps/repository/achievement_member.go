@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresAchievementMemberRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAchievementMemberRepository(db *sql.DB) *PostgresAchievementMemberRepository {
+	return &PostgresAchievementMemberRepository{db: db}
+}
+
+const achievementMemberColumns = `id, achievement_id, student_id, role, status, point_share, invited_by, responded_at, created_at`
+
+func (r *PostgresAchievementMemberRepository) Create(ctx context.Context, member *models.AchievementMember) error {
+	if member.ID == uuid.Nil {
+		member.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO achievement_members (id, achievement_id, student_id, role, status, point_share, invited_by, responded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		member.ID, member.AchievementID, member.StudentID, member.Role, member.Status,
+		member.PointShare, member.InvitedBy, member.RespondedAt,
+	).Scan(&member.CreatedAt)
+}
+
+func (r *PostgresAchievementMemberRepository) Get(ctx context.Context, achievementID, studentID uuid.UUID) (*models.AchievementMember, error) {
+	query := `SELECT ` + achievementMemberColumns + ` FROM achievement_members WHERE achievement_id = $1 AND student_id = $2`
+	members, err := r.query(ctx, query, achievementID, studentID)
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	return &members[0], nil
+}
+
+func (r *PostgresAchievementMemberRepository) GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementMember, error) {
+	query := `SELECT ` + achievementMemberColumns + ` FROM achievement_members WHERE achievement_id = $1 ORDER BY created_at`
+	return r.query(ctx, query, achievementID)
+}
+
+func (r *PostgresAchievementMemberRepository) GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementMember, error) {
+	query := `SELECT ` + achievementMemberColumns + ` FROM achievement_members WHERE student_id = $1 ORDER BY created_at DESC`
+	return r.query(ctx, query, studentID)
+}
+
+func (r *PostgresAchievementMemberRepository) Update(ctx context.Context, member *models.AchievementMember) error {
+	query := `
+		UPDATE achievement_members SET role = $2, status = $3, point_share = $4, invited_by = $5, responded_at = $6
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, member.ID, member.Role, member.Status, member.PointShare, member.InvitedBy, member.RespondedAt)
+	return err
+}
+
+func (r *PostgresAchievementMemberRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM achievement_members WHERE id = $1`, id)
+	return err
+}
+
+func (r *PostgresAchievementMemberRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.AchievementMember, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.AchievementMember
+	for rows.Next() {
+		var m models.AchievementMember
+		if err := rows.Scan(&m.ID, &m.AchievementID, &m.StudentID, &m.Role, &m.Status, &m.PointShare,
+			&m.InvitedBy, &m.RespondedAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAchievementRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAchievementRevisionRepository(db *mongo.Database) *MongoAchievementRevisionRepository {
+	return &MongoAchievementRevisionRepository{collection: db.Collection("achievement_revisions")}
+}
+
+func (r *MongoAchievementRevisionRepository) Create(ctx context.Context, revision *models.AchievementRevision) error {
+	revision.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, revision)
+	return err
+}
+
+// GetByAchievementID semua ronde milik satu prestasi, urut dari ronde pertama
+func (r *MongoAchievementRevisionRepository) GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementRevision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "round", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"achievement_id": achievementID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []models.AchievementRevision
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *MongoAchievementRevisionRepository) SetReview(ctx context.Context, achievementID string, round int, review *models.AchievementReview) error {
+	filter := bson.M{"achievement_id": achievementID, "round": round}
+	_, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"review": review}})
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+)
+
+type PostgresAchievementTypeRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresAchievementTypeRepository(db *sql.DB) *PostgresAchievementTypeRepository {
+	return &PostgresAchievementTypeRepository{db: db}
+}
+
+const achievementTypeColumns = `id, key, label, fields, default_points, is_active, created_at, updated_at`
+
+// scanAchievementType - kolom fields berupa JSONB berisi []AchievementFieldDefinition
+func scanAchievementType(scanner interface{ Scan(...interface{}) error }) (*models.AchievementTypeDefinition, error) {
+	var t models.AchievementTypeDefinition
+	var fields []byte
+	if err := scanner.Scan(&t.ID, &t.Key, &t.Label, &fields, &t.DefaultPoints, &t.IsActive, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(fields) > 0 {
+		if err := json.Unmarshal(fields, &t.Fields); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func (r *PostgresAchievementTypeRepository) GetAll(ctx context.Context, includeInactive bool) ([]models.AchievementTypeDefinition, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types WHERE is_active OR $1 ORDER BY created_at, key`
+	rows, err := r.db.QueryContext(ctx, query, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.AchievementTypeDefinition
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, *t)
+	}
+	return types, rows.Err()
+}
+
+func (r *PostgresAchievementTypeRepository) GetByKey(ctx context.Context, key string) (*models.AchievementTypeDefinition, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types WHERE key = $1`
+	t, err := scanAchievementType(r.db.QueryRowContext(ctx, query, key))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *PostgresAchievementTypeRepository) Create(ctx context.Context, t *models.AchievementTypeDefinition) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO achievement_types (key, label, fields, default_points, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query, t.Key, t.Label, fields, t.DefaultPoints, t.IsActive).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *PostgresAchievementTypeRepository) Update(ctx context.Context, t *models.AchievementTypeDefinition) error {
+	fields, err := json.Marshal(t.Fields)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE achievement_types
+		SET label = $1, fields = $2, default_points = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	return r.db.QueryRowContext(ctx, query, t.Label, fields, t.DefaultPoints, t.IsActive, t.ID).Scan(&t.UpdatedAt)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAchievementVersionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAchievementVersionRepository(db *mongo.Database) *MongoAchievementVersionRepository {
+	return &MongoAchievementVersionRepository{collection: db.Collection("achievement_versions")}
+}
+
+func (r *MongoAchievementVersionRepository) Create(ctx context.Context, version *models.AchievementVersion) error {
+	version.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, version)
+	return err
+}
+
+// GetByAchievementID semua versi lama milik satu prestasi, urut dari versi pertama
+func (r *MongoAchievementVersionRepository) GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementVersion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"achievement_id": achievementID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var versions []models.AchievementVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *MongoAchievementVersionRepository) GetByVersion(ctx context.Context, achievementID string, version int) (*models.AchievementVersion, error) {
+	var result models.AchievementVersion
+	err := r.collection.FindOne(ctx, bson.M{"achievement_id": achievementID, "version": version}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -54,17 +54,22 @@ func (r *AchievementRepo) GetDetailByID(ctx context.Context, mongoID string) (*m
 func (r *AchievementRepo) CreateReference(ctx context.Context, ref *models.AchievementReference) error {
 	query := `
         INSERT INTO achievement_references (
-            student_id, mongo_achievement_id, status, submitted_at, created_at
+            id, student_id, mongo_achievement_id, status, submitted_at, created_at
         )
-        VALUES ($1, $2, $3, $4, NOW())
+        VALUES ($1, $2, $3, $4, $5, NOW())
         RETURNING id, created_at, updated_at
     `
 
 	if ref.Status == "" {
 		ref.Status = "draft"
 	}
+	// ID boleh diisi pemanggil, misal untuk URL lampiran sebelum reference tersimpan
+	if ref.ID == uuid.Nil {
+		ref.ID = uuid.New()
+	}
 
 	err := r.pgDB.QueryRowContext(ctx, query,
+		ref.ID,
 		ref.StudentID,
 		ref.MongoAchievementID,
 		ref.Status,
@@ -75,7 +80,7 @@ func (r *AchievementRepo) CreateReference(ctx context.Context, ref *models.Achie
 }
 
 func (r *AchievementRepo) GetReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error) {
-	query := `SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, rejection_note, created_at, updated_at FROM achievement_references WHERE id = $1`
+	query := `SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, rejection_note, created_at, updated_at, deleted_at FROM achievement_references WHERE id = $1`
 
 	var model models.AchievementReference
 
@@ -89,7 +94,8 @@ func (r *AchievementRepo) GetReferenceByID(ctx context.Context, id uuid.UUID) (*
 		&model.VerifiedBy,
 		&model.RejectionNote,
 		&model.CreatedAt,
-		&model.UpdatedAt)
+		&model.UpdatedAt,
+		&model.DeletedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -100,30 +106,74 @@ func (r *AchievementRepo) GetReferenceByID(ctx context.Context, id uuid.UUID) (*
 	return &model, nil
 }
 
-func (r *AchievementRepo) UpdateStatus(ctx context.Context, ref *models.AchievementReference) error {
+func (r *AchievementRepo) TransitionStatus(ctx context.Context, ref *models.AchievementReference, fromStatus string, change *models.AchievementStatusChange) (bool, error) {
+	tx, err := r.pgDB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
 	query := `
-		UPDATE achievement_references 
-		SET status = $1, verified_by = $2, verified_at = $3, rejection_note = $4, updated_at = NOW() 
-		WHERE id = $5
+		UPDATE achievement_references
+		SET status = $1, submitted_at = $2, verified_by = $3, verified_at = $4, rejection_note = $5, updated_at = NOW()
+		WHERE id = $6 AND LOWER(status) = $7 AND deleted_at IS NULL
 	`
-
-	result, err := r.pgDB.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		ref.Status,
+		ref.SubmittedAt,
 		ref.VerifiedBy,
 		ref.VerifiedAt,
 		ref.RejectionNote,
 		ref.ID,
+		fromStatus,
 	)
+	if err != nil {
+		return false, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return false, nil
+	}
 
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	historyQuery := `
+		INSERT INTO achievement_status_history (id, achievement_id, from_status, to_status, actor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	err = tx.QueryRowContext(ctx, historyQuery,
+		change.ID, change.AchievementID, change.FromStatus, change.ToStatus, change.ActorID, change.Note,
+	).Scan(&change.CreatedAt)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("no achievement status updated (id not found)")
+	return true, tx.Commit()
+}
+
+func (r *AchievementRepo) GetStatusHistory(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementStatusChange, error) {
+	query := `
+		SELECT id, achievement_id, from_status, to_status, actor_id, COALESCE(note, ''), created_at
+		FROM achievement_status_history
+		WHERE achievement_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.pgDB.QueryContext(ctx, query, achievementID)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	defer rows.Close()
+
+	var history []models.AchievementStatusChange
+	for rows.Next() {
+		var h models.AchievementStatusChange
+		if err := rows.Scan(&h.ID, &h.AchievementID, &h.FromStatus, &h.ToStatus, &h.ActorID, &h.Note, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
 }
 
 func (r *AchievementRepo) GetAllByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error) {
@@ -154,7 +204,7 @@ func (r *AchievementRepo) GetAll(ctx context.Context, status string) ([]models.A
 		query = `
             SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by, rejection_note, created_at, updated_at 
             FROM achievement_references 
-            WHERE status = $1 AND deleted_at IS NULL
+            WHERE LOWER(status) = $1 AND deleted_at IS NULL
             ORDER BY created_at DESC
         `
 		rows, err = r.pgDB.QueryContext(ctx, query, status)
@@ -218,6 +268,23 @@ func (r *AchievementRepo) UpdateDetail(ctx context.Context, mongoID string, upda
 	return err
 }
 
+// UpdateScore menyimpan poin dan rinciannya tanpa mengubah updated_at, karena bukan perubahan isi oleh mahasiswa
+func (r *AchievementRepo) UpdateScore(ctx context.Context, mongoID string, breakdown *models.ScoreBreakdown) error {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"points":          breakdown.Total,
+			"score_breakdown": breakdown,
+		},
+	}
+	_, err = r.mongoDB.Collection("achievements").UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
 func (r *AchievementRepo) GetAllDetailsFromMongo(ctx context.Context) ([]models.AchievementDetail, error) {
 	cursor, err := r.mongoDB.Collection("achievements").Find(ctx, bson.M{})
 	if err != nil {
@@ -233,6 +300,30 @@ func (r *AchievementRepo) GetAllDetailsFromMongo(ctx context.Context) ([]models.
 	return details, nil
 }
 
+func (r *AchievementRepo) GetDetailsByIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementDetail, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	if len(objIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.mongoDB.Collection("achievements").Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var details []models.AchievementDetail
+	if err = cursor.All(ctx, &details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
 func (r *AchievementRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE achievement_references SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 	result, err := r.pgDB.ExecContext(ctx, query, id)
@@ -246,15 +337,16 @@ func (r *AchievementRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *AchievementRepo) Submit(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE achievement_references SET status = 'submitted', submitted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
-	result, err := r.pgDB.ExecContext(ctx, query, id)
+func (r *AchievementRepo) DeleteReference(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pgDB.ExecContext(ctx, `DELETE FROM achievement_references WHERE id = $1`, id)
+	return err
+}
+
+func (r *AchievementRepo) DeleteDetail(ctx context.Context, mongoID string) error {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
 	if err != nil {
-		return err
-	}
-	rows, _ := result.RowsAffected()
-	if rows == 0 {
-		return errors.New("achievement gkk ditemukan ")
+		return errors.New("invalid id")
 	}
-	return nil
+	_, err = r.mongoDB.Collection("achievements").DeleteOne(ctx, bson.M{"_id": objID})
+	return err
 }
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresDelegationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresDelegationRepository(db *sql.DB) *PostgresDelegationRepository {
+	return &PostgresDelegationRepository{db: db}
+}
+
+const delegationColumns = `id, advisor_id, delegate_id, starts_at, ends_at, COALESCE(reason, ''), revoked_at, created_at`
+
+func scanDelegation(scanner interface{ Scan(...interface{}) error }) (*models.VerificationDelegation, error) {
+	var d models.VerificationDelegation
+	if err := scanner.Scan(&d.ID, &d.AdvisorID, &d.DelegateID, &d.StartsAt, &d.EndsAt, &d.Reason, &d.RevokedAt, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *PostgresDelegationRepository) Create(ctx context.Context, d *models.VerificationDelegation) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO verification_delegations (id, advisor_id, delegate_id, starts_at, ends_at, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query, d.ID, d.AdvisorID, d.DelegateID, d.StartsAt, d.EndsAt, d.Reason).Scan(&d.CreatedAt)
+}
+
+func (r *PostgresDelegationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.VerificationDelegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM verification_delegations WHERE id = $1`
+	d, err := scanDelegation(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+// GetByLecturer mengembalikan delegasi yang diberikan maupun diterima dosen, terbaru dulu
+func (r *PostgresDelegationRepository) GetByLecturer(ctx context.Context, lecturerID uuid.UUID) ([]models.VerificationDelegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM verification_delegations
+		WHERE advisor_id = $1 OR delegate_id = $1
+		ORDER BY starts_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.VerificationDelegation
+	for rows.Next() {
+		d, err := scanDelegation(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *d)
+	}
+	return result, rows.Err()
+}
+
+// FindActive mencari delegasi aktif dari advisorID ke delegateID pada waktu at
+func (r *PostgresDelegationRepository) FindActive(ctx context.Context, advisorID, delegateID uuid.UUID, at time.Time) (*models.VerificationDelegation, error) {
+	query := `SELECT ` + delegationColumns + ` FROM verification_delegations
+		WHERE advisor_id = $1 AND delegate_id = $2 AND revoked_at IS NULL
+		  AND starts_at <= $3 AND ends_at > $3
+		ORDER BY ends_at DESC
+		LIMIT 1`
+	d, err := scanDelegation(r.db.QueryRowContext(ctx, query, advisorID, delegateID, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return d, err
+}
+
+func (r *PostgresDelegationRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE verification_delegations SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
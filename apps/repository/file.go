@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresFileRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresFileRepository(db *sql.DB) *PostgresFileRepository {
+	return &PostgresFileRepository{db: db}
+}
+
+const fileColumns = `id, owner_id, sha256, storage_key, original_name, mime_type, size, thumbnail_key, achievement_id, linked_at, created_at`
+
+// rowScanner *sql.Row atau *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFile(scanner rowScanner) (*models.StoredFile, error) {
+	var f models.StoredFile
+	err := scanner.Scan(&f.ID, &f.OwnerID, &f.SHA256, &f.StorageKey, &f.OriginalName, &f.MimeType, &f.Size, &f.ThumbnailKey, &f.AchievementID, &f.LinkedAt, &f.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *PostgresFileRepository) Create(ctx context.Context, file *models.StoredFile) error {
+	if file.ID == uuid.Nil {
+		file.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO files (id, owner_id, sha256, storage_key, original_name, mime_type, size, thumbnail_key, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		file.ID, file.OwnerID, file.SHA256, file.StorageKey, file.OriginalName, file.MimeType, file.Size, file.ThumbnailKey,
+	).Scan(&file.CreatedAt)
+}
+
+func (r *PostgresFileRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StoredFile, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $1`
+
+	f, err := scanFile(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return f, err
+}
+
+func (r *PostgresFileRepository) TotalSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(size), 0) FROM files WHERE owner_id = $1`, ownerID).Scan(&total)
+	return total, err
+}
+
+// LinkToAchievement hanya mengisi file yang belum dilampirkan ke prestasi lain (atau sudah ke prestasi yang sama).
+// Semua file ditautkan dalam satu transaksi, kalau satu gagal tidak ada yang tertaut
+func (r *PostgresFileRepository) LinkToAchievement(ctx context.Context, ids []uuid.UUID, achievementID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE files SET achievement_id = $2, linked_at = COALESCE(linked_at, NOW())
+		WHERE id = $1 AND (achievement_id IS NULL OR achievement_id = $2)
+	`
+	for _, id := range ids {
+		result, err := tx.ExecContext(ctx, query, id, achievementID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresFileRepository) GetUnlinkedBefore(ctx context.Context, before time.Time) ([]models.StoredFile, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE achievement_id IS NULL AND created_at < $1 ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []models.StoredFile
+	for rows.Next() {
+		f, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *f)
+	}
+	return files, rows.Err()
+}
+
+func (r *PostgresFileRepository) CountByStorageKey(ctx context.Context, storageKey string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM files WHERE storage_key = $1`, storageKey).Scan(&count)
+	return count, err
+}
+
+func (r *PostgresFileRepository) CountByThumbnailKey(ctx context.Context, thumbnailKey string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM files WHERE thumbnail_key = $1`, thumbnailKey).Scan(&count)
+	return count, err
+}
+
+// Delete memakai kondisi achievement_id IS NULL supaya file yang baru saja dilampirkan tidak ikut terhapus sweeper
+func (r *PostgresFileRepository) Delete(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM files WHERE id = $1 AND achievement_id IS NULL`, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 	"uas-pelaporan-prestasi-mahasiswa/apps/models"
 
 	"github.com/google/uuid"
@@ -15,26 +16,98 @@ type UserRepository interface {
 	Update(ctx context.Context, User *models.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	SetActive(ctx context.Context, userID uuid.UUID, active bool, reason string) error
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error)
+	MarkRotated(ctx context.Context, id uuid.UUID, replacedBy uuid.UUID) (bool, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	InvalidateByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// TokenRevocationStore menyimpan access token (jti) yang dicabut sebelum expired dan
+// batas waktu per user: token yang diterbitkan sebelum batas itu dianggap tidak berlaku.
+type TokenRevocationStore interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeUserTokensBefore(ctx context.Context, userID uuid.UUID, before time.Time) error
+	GetUserTokensNotBefore(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+}
+
+// LoginAttemptStore menyimpan hitungan login gagal per key. RecordFailure memulai hitungan
+// dari 1 lagi kalau kegagalan pertama sudah lewat dari window.
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *models.LoginEvent) error
+	GetRecent(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error)
+}
+
+// MFARepository menyimpan secret TOTP, recovery code (hash) dan kewajiban MFA per role (roles.mfa_required)
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error)
+	SavePending(ctx context.Context, userID uuid.UUID, secretEncrypted string) error
+	Enable(ctx context.Context, userID uuid.UUID) error
+	Delete(ctx context.Context, userID uuid.UUID) error
+	MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	IsRequiredForRole(ctx context.Context, roleID uuid.UUID) (bool, error)
+	SetRoleRequirement(ctx context.Context, roleID uuid.UUID, required bool) (bool, error)
 }
 
 type RoleRepository interface {
 	GetAll(ctx context.Context) ([]models.Role, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	Create(ctx context.Context, role *models.Role) error
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountUsers(ctx context.Context, id uuid.UUID) (int, error)
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *models.Invitation) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	GetPending(ctx context.Context) ([]models.Invitation, error)
+	MarkAccepted(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 type PermissionRepository interface {
 	Create(ctx context.Context, Permission *models.Permission) error
 	GetByName(ctx context.Context, name string) (*models.Permission, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Permission, error)
 	GetAll(ctx context.Context) ([]models.Permission, error)
 	AssignToRole(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+	RevokeFromRole(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
 	GetByRoleID(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error)
+	GetByRoleName(ctx context.Context, roleName string) ([]models.Permission, error)
 }
 
 type StudentsRepository interface {
 	Create(ctx context.Context, student *models.Students) error
 	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Students, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Students, error)
-	GetAll(ctx context.Context) ([]models.Students, error)
+	GetAll(ctx context.Context, includeInactive bool) ([]models.Students, error)
 	Update(ctx context.Context, student *models.Students) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	AssignAdvisor(ctx context.Context, studentID uuid.UUID, advisorID uuid.UUID) error
@@ -57,15 +130,81 @@ type AchievementRepository interface {
 	CreateReference(ctx context.Context, ref *models.AchievementReference) error
 	GetReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error)
 
-	UpdateStatus(ctx context.Context, ref *models.AchievementReference) error
+	// TransitionStatus mengubah status hanya kalau status saat ini masih fromStatus, sekaligus mencatat riwayat.
+	// false berarti status sudah diubah request lain.
+	TransitionStatus(ctx context.Context, ref *models.AchievementReference, fromStatus string, change *models.AchievementStatusChange) (bool, error)
+	GetStatusHistory(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementStatusChange, error)
 
 	GetAll(ctx context.Context, status string) ([]models.AchievementReference, error)
 	GetAllByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error)
 
 	UpdateDetail(ctx context.Context, mongoID string, updateData *models.AchievementDetail) error
+	UpdateScore(ctx context.Context, mongoID string, breakdown *models.ScoreBreakdown) error
 
 	GetAllDetailsFromMongo(ctx context.Context) ([]models.AchievementDetail, error)
+	// GetDetailsByIDs mengambil banyak dokumen sekaligus, id yang tidak ada dilewati
+	GetDetailsByIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementDetail, error)
 
 	SoftDelete(ctx context.Context, id uuid.UUID) error
-	Submit(ctx context.Context, id uuid.UUID) error
+	// DeleteReference dan DeleteDetail menghapus permanen, hanya untuk membatalkan Create yang gagal di tengah jalan
+	DeleteReference(ctx context.Context, id uuid.UUID) error
+	DeleteDetail(ctx context.Context, mongoID string) error
+}
+
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *models.VerificationDelegation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.VerificationDelegation, error)
+	GetByLecturer(ctx context.Context, lecturerID uuid.UUID) ([]models.VerificationDelegation, error)
+	FindActive(ctx context.Context, advisorID, delegateID uuid.UUID, at time.Time) (*models.VerificationDelegation, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type AchievementRevisionRepository interface {
+	Create(ctx context.Context, revision *models.AchievementRevision) error
+	GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementRevision, error)
+	SetReview(ctx context.Context, achievementID string, round int, review *models.AchievementReview) error
+}
+
+type AchievementVersionRepository interface {
+	Create(ctx context.Context, version *models.AchievementVersion) error
+	GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementVersion, error)
+	GetByVersion(ctx context.Context, achievementID string, version int) (*models.AchievementVersion, error)
+}
+
+type AchievementTypeRepository interface {
+	GetAll(ctx context.Context, includeInactive bool) ([]models.AchievementTypeDefinition, error)
+	GetByKey(ctx context.Context, key string) (*models.AchievementTypeDefinition, error)
+	Create(ctx context.Context, achievementType *models.AchievementTypeDefinition) error
+	Update(ctx context.Context, achievementType *models.AchievementTypeDefinition) error
+}
+
+type PointLedgerRepository interface {
+	Create(ctx context.Context, entry *models.PointLedgerEntry) error
+	GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointLedgerEntry, error)
+	GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.PointLedgerEntry, error)
+}
+
+type AchievementMemberRepository interface {
+	Create(ctx context.Context, member *models.AchievementMember) error
+	Get(ctx context.Context, achievementID, studentID uuid.UUID) (*models.AchievementMember, error)
+	GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementMember, error)
+	GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementMember, error)
+	Update(ctx context.Context, member *models.AchievementMember) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type FileRepository interface {
+	Create(ctx context.Context, file *models.StoredFile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StoredFile, error)
+	// TotalSizeByOwner jumlah ukuran semua upload milik user, dipakai untuk kuota
+	TotalSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	// LinkToAchievement mengembalikan sql.ErrNoRows kalau salah satu file sudah dilampirkan ke prestasi lain,
+	// dan dalam kasus itu tidak ada file yang tertaut
+	LinkToAchievement(ctx context.Context, ids []uuid.UUID, achievementID uuid.UUID) error
+	// GetUnlinkedBefore upload yang belum pernah dilampirkan dan dibuat sebelum waktu tertentu
+	GetUnlinkedBefore(ctx context.Context, before time.Time) ([]models.StoredFile, error)
+	CountByStorageKey(ctx context.Context, storageKey string) (int, error)
+	CountByThumbnailKey(ctx context.Context, thumbnailKey string) (int, error)
+	// Delete hanya menghapus file yang belum dilampirkan. 0 berarti file sudah dilampirkan atau sudah terhapus
+	Delete(ctx context.Context, id uuid.UUID) (int64, error)
 }
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresInvitationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresInvitationRepository(db *sql.DB) *PostgresInvitationRepository {
+	return &PostgresInvitationRepository{db: db}
+}
+
+func (r *PostgresInvitationRepository) Create(ctx context.Context, inv *models.Invitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO user_invitations (id, email, full_name, role_id, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		inv.ID, inv.Email, inv.FullName, inv.RoleID, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt,
+	).Scan(&inv.CreatedAt)
+}
+
+const invitationColumns = `
+	i.id, i.email, i.full_name, i.role_id, i.token_hash, i.invited_by, i.expires_at, i.accepted_at, i.accepted_by, i.created_at,
+	r.id, r.name
+`
+
+func scanInvitation(scanner interface{ Scan(...interface{}) error }) (*models.Invitation, error) {
+	var inv models.Invitation
+	var role models.Role
+	err := scanner.Scan(
+		&inv.ID, &inv.Email, &inv.FullName, &inv.RoleID, &inv.TokenHash, &inv.InvitedBy, &inv.ExpiresAt, &inv.AcceptedAt, &inv.AcceptedBy, &inv.CreatedAt,
+		&role.ID, &role.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.Role = &role
+	return &inv, nil
+}
+
+func (r *PostgresInvitationRepository) GetByHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations i JOIN roles r ON r.id = i.role_id WHERE i.token_hash = $1`
+	inv, err := scanInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return inv, err
+}
+
+// GetPending mengambil undangan yang belum diterima dan belum kadaluwarsa
+func (r *PostgresInvitationRepository) GetPending(ctx context.Context) ([]models.Invitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM user_invitations i JOIN roles r ON r.id = i.role_id
+		WHERE i.accepted_at IS NULL AND i.expires_at > NOW()
+		ORDER BY i.created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []models.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *inv)
+	}
+	return invitations, rows.Err()
+}
+
+// MarkAccepted atomik: false kalau undangan sudah dipakai atau sudah kadaluwarsa
+func (r *PostgresInvitationRepository) MarkAccepted(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE user_invitations SET accepted_at = NOW(), accepted_by = $2
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > NOW()
+	`
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *PostgresInvitationRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_invitations WHERE id = $1 AND accepted_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+)
+
+// MemoryLoginAttemptStore cocok untuk deployment satu instance. Kalau aplikasi jalan di
+// beberapa instance, pakai PostgresLoginAttemptStore supaya hitungannya dibagi bersama.
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*models.LoginAttempt
+	window   time.Duration
+}
+
+// NewMemoryLoginAttemptStore - retention adalah berapa lama entry tanpa kegagalan baru disimpan
+func NewMemoryLoginAttemptStore(retention time.Duration) *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		attempts: make(map[string]*models.LoginAttempt),
+		window:   retention,
+	}
+}
+
+// purgeLocked membuang entry yang sudah tidak terkunci dan sudah lewat masa simpannya
+func (s *MemoryLoginAttemptStore) purgeLocked(now time.Time) {
+	for key, a := range s.attempts {
+		locked := a.LockedUntil != nil && now.Before(*a.LockedUntil)
+		if !locked && now.After(a.LastFailureAt.Add(s.window)) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+func (s *MemoryLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, exists := s.attempts[key]
+	if !exists {
+		return nil, nil
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.purgeLocked(now)
+
+	a, exists := s.attempts[key]
+	if !exists {
+		a = &models.LoginAttempt{Key: key}
+		s.attempts[key] = a
+	}
+	if a.Failures == 0 || now.After(a.FirstFailureAt.Add(window)) {
+		a.Failures = 0
+		a.FirstFailureAt = now
+	}
+	a.Failures++
+	a.LastFailureAt = now
+
+	copied := *a
+	return &copied, nil
+}
+
+func (s *MemoryLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, exists := s.attempts[key]
+	if !exists {
+		now := time.Now()
+		a = &models.LoginAttempt{Key: key, FirstFailureAt: now, LastFailureAt: now}
+		s.attempts[key] = a
+	}
+	a.LockedUntil = &until
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+type PostgresLoginAttemptStore struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginAttemptStore(db *sql.DB) *PostgresLoginAttemptStore {
+	return &PostgresLoginAttemptStore{db: db}
+}
+
+func (s *PostgresLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	query := `SELECT key, failures, first_failure_at, last_failure_at, locked_until FROM login_attempts WHERE key = $1`
+	var a models.LoginAttempt
+	err := s.db.QueryRowContext(ctx, query, key).Scan(&a.Key, &a.Failures, &a.FirstFailureAt, &a.LastFailureAt, &a.LockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *PostgresLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempt, error) {
+	// hitungan diulang dari 1 kalau kegagalan pertama sudah di luar window
+	query := `
+		INSERT INTO login_attempts (key, failures, first_failure_at, last_failure_at)
+		VALUES ($1, 1, NOW(), NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.first_failure_at < $2 THEN 1 ELSE login_attempts.failures + 1 END,
+			first_failure_at = CASE WHEN login_attempts.first_failure_at < $2 THEN NOW() ELSE login_attempts.first_failure_at END,
+			last_failure_at = NOW()
+		RETURNING key, failures, first_failure_at, last_failure_at, locked_until
+	`
+	var a models.LoginAttempt
+	err := s.db.QueryRowContext(ctx, query, key, time.Now().Add(-window)).
+		Scan(&a.Key, &a.Failures, &a.FirstFailureAt, &a.LastFailureAt, &a.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *PostgresLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `
+		INSERT INTO login_attempts (key, failures, first_failure_at, last_failure_at, locked_until)
+		VALUES ($1, 0, NOW(), NOW(), $2)
+		ON CONFLICT (key) DO UPDATE SET locked_until = EXCLUDED.locked_until
+	`
+	_, err := s.db.ExecContext(ctx, query, key, until)
+	return err
+}
+
+func (s *PostgresLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key)
+	return err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresLoginEventRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginEventRepository(db *sql.DB) *PostgresLoginEventRepository {
+	return &PostgresLoginEventRepository{db: db}
+}
+
+func (r *PostgresLoginEventRepository) Create(ctx context.Context, event *models.LoginEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO login_events (id, user_id, login, ip_address, user_agent, outcome, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		event.ID, event.UserID, event.Login, event.IPAddress, event.UserAgent, event.Outcome,
+	).Scan(&event.CreatedAt)
+}
+
+func (r *PostgresLoginEventRepository) GetRecent(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	query := `SELECT id, user_id, login, ip_address, user_agent, outcome, created_at FROM login_events WHERE 1=1`
+	var args []interface{}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if filter.IPAddress != "" {
+		args = append(args, filter.IPAddress)
+		query += fmt.Sprintf(" AND ip_address = $%d", len(args))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.LoginEvent
+	for rows.Next() {
+		var e models.LoginEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Login, &e.IPAddress, &e.UserAgent, &e.Outcome, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
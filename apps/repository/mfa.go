@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresMFARepository struct {
+	db *sql.DB
+}
+
+func NewPostgresMFARepository(db *sql.DB) *PostgresMFARepository {
+	return &PostgresMFARepository{db: db}
+}
+
+func (r *PostgresMFARepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	query := `
+		SELECT user_id, secret_encrypted, enabled, last_used_step, confirmed_at, created_at
+		FROM user_mfa WHERE user_id = $1
+	`
+	var m models.UserMFA
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&m.UserID, &m.SecretEncrypted, &m.Enabled, &m.LastUsedStep, &m.ConfirmedAt, &m.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SavePending menimpa enrollment yang belum dikonfirmasi, MFA yang sudah aktif tidak disentuh
+func (r *PostgresMFARepository) SavePending(ctx context.Context, userID uuid.UUID, secretEncrypted string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret_encrypted, enabled, last_used_step, created_at)
+		VALUES ($1, $2, FALSE, 0, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret_encrypted = EXCLUDED.secret_encrypted,
+			last_used_step = 0,
+			created_at = NOW()
+		WHERE user_mfa.enabled = FALSE
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, secretEncrypted)
+	return err
+}
+
+func (r *PostgresMFARepository) Enable(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE user_mfa SET enabled = TRUE, confirmed_at = NOW() WHERE user_id = $1`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *PostgresMFARepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkStepUsed mengembalikan false kalau kode di step itu (atau sesudahnya) sudah pernah dipakai
+func (r *PostgresMFARepository) MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	res, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *PostgresMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		query := `INSERT INTO mfa_recovery_codes (id, user_id, code_hash, created_at) VALUES ($1, $2, $3, NOW())`
+		if _, err := tx.ExecContext(ctx, query, uuid.New(), userID, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *PostgresMFARepository) IsRequiredForRole(ctx context.Context, roleID uuid.UUID) (bool, error) {
+	var required bool
+	err := r.db.QueryRowContext(ctx, `SELECT mfa_required FROM roles WHERE id = $1`, roleID).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return required, err
+}
+
+// SetRoleRequirement mengembalikan false kalau role tidak ditemukan
+func (r *PostgresMFARepository) SetRoleRequirement(ctx context.Context, roleID uuid.UUID, required bool) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE roles SET mfa_required = $2 WHERE id = $1`, roleID, required)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected == 1, err
+}
@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockAchievementMemberRepo - Mock untuk AchievementMemberRepository
+type ManualMockAchievementMemberRepo struct {
+	members []*models.AchievementMember
+}
+
+// NewManualMockAchievementMemberRepo - Constructor
+func NewManualMockAchievementMemberRepo() *ManualMockAchievementMemberRepo {
+	return &ManualMockAchievementMemberRepo{}
+}
+
+func (m *ManualMockAchievementMemberRepo) Create(ctx context.Context, member *models.AchievementMember) error {
+	member.ID = uuid.New()
+	member.CreatedAt = time.Now()
+	stored := *member
+	m.members = append(m.members, &stored)
+	return nil
+}
+
+func (m *ManualMockAchievementMemberRepo) Get(ctx context.Context, achievementID, studentID uuid.UUID) (*models.AchievementMember, error) {
+	for _, member := range m.members {
+		if member.AchievementID == achievementID && member.StudentID == studentID {
+			found := *member
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockAchievementMemberRepo) GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementMember, error) {
+	var result []models.AchievementMember
+	for _, member := range m.members {
+		if member.AchievementID == achievementID {
+			result = append(result, *member)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementMemberRepo) GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementMember, error) {
+	var result []models.AchievementMember
+	for _, member := range m.members {
+		if member.StudentID == studentID {
+			result = append(result, *member)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementMemberRepo) Update(ctx context.Context, member *models.AchievementMember) error {
+	for i, existing := range m.members {
+		if existing.ID == member.ID {
+			updated := *member
+			m.members[i] = &updated
+		}
+	}
+	return nil
+}
+
+func (m *ManualMockAchievementMemberRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, member := range m.members {
+		if member.ID == id {
+			m.members = append(m.members[:i], m.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ManualMockAchievementRepo - Mock untuk AchievementRepository (reference PostgreSQL + detail MongoDB)
+type ManualMockAchievementRepo struct {
+	references map[uuid.UUID]*models.AchievementReference
+	details    map[string]*models.AchievementDetail
+	history    map[uuid.UUID][]models.AchievementStatusChange
+	scoreErr   error
+}
+
+// NewManualMockAchievementRepo - Constructor
+func NewManualMockAchievementRepo() *ManualMockAchievementRepo {
+	return &ManualMockAchievementRepo{
+		references: make(map[uuid.UUID]*models.AchievementReference),
+		details:    make(map[string]*models.AchievementDetail),
+		history:    make(map[uuid.UUID][]models.AchievementStatusChange),
+	}
+}
+
+// AddAchievement - helper test untuk menyiapkan prestasi milik studentID dengan status tertentu
+func (m *ManualMockAchievementRepo) AddAchievement(studentID uuid.UUID, status string, detail *models.AchievementDetail) *models.AchievementReference {
+	if detail == nil {
+		detail = &models.AchievementDetail{Title: "Prestasi", AchievementType: "other"}
+	}
+	detail.StudentID = studentID.String()
+	m.CreateDetail(context.Background(), detail)
+
+	ref := &models.AchievementReference{
+		StudentID:          studentID,
+		MongoAchievementID: detail.ID.Hex(),
+		Status:             status,
+	}
+	m.CreateReference(context.Background(), ref)
+	return ref
+}
+
+func (m *ManualMockAchievementRepo) CreateDetail(ctx context.Context, detail *models.AchievementDetail) error {
+	detail.ID = primitive.NewObjectID()
+	detail.CreatedAt = time.Now()
+	detail.UpdatedAt = time.Now()
+	m.details[detail.ID.Hex()] = detail
+	return nil
+}
+
+func (m *ManualMockAchievementRepo) GetDetailByID(ctx context.Context, mongoID string) (*models.AchievementDetail, error) {
+	if detail, exists := m.details[mongoID]; exists {
+		return detail, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockAchievementRepo) CreateReference(ctx context.Context, ref *models.AchievementReference) error {
+	if ref.ID == uuid.Nil {
+		ref.ID = uuid.New()
+	}
+	if ref.Status == "" {
+		ref.Status = "draft"
+	}
+	ref.CreatedAt = time.Now()
+	ref.UpdatedAt = time.Now()
+	m.references[ref.ID] = ref
+	return nil
+}
+
+func (m *ManualMockAchievementRepo) GetReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error) {
+	if ref, exists := m.references[id]; exists {
+		return ref, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockAchievementRepo) TransitionStatus(ctx context.Context, ref *models.AchievementReference, fromStatus string, change *models.AchievementStatusChange) (bool, error) {
+	existing, exists := m.references[ref.ID]
+	if !exists || existing.DeletedAt != nil || strings.ToLower(existing.Status) != fromStatus {
+		return false, nil
+	}
+	existing.Status = ref.Status
+	existing.SubmittedAt = ref.SubmittedAt
+	existing.VerifiedBy = ref.VerifiedBy
+	existing.VerifiedAt = ref.VerifiedAt
+	existing.RejectionNote = ref.RejectionNote
+	existing.UpdatedAt = time.Now()
+
+	change.ID = uuid.New()
+	change.CreatedAt = time.Now()
+	m.history[ref.ID] = append(m.history[ref.ID], *change)
+	return true, nil
+}
+
+func (m *ManualMockAchievementRepo) GetStatusHistory(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementStatusChange, error) {
+	return m.history[achievementID], nil
+}
+
+func (m *ManualMockAchievementRepo) GetAll(ctx context.Context, status string) ([]models.AchievementReference, error) {
+	var result []models.AchievementReference
+	for _, ref := range m.references {
+		if ref.DeletedAt == nil && (status == "" || strings.ToLower(ref.Status) == status) {
+			result = append(result, *ref)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementRepo) GetAllByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error) {
+	var result []models.AchievementReference
+	for _, ref := range m.references {
+		if ref.DeletedAt == nil && ref.StudentID == studentID {
+			result = append(result, *ref)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementRepo) UpdateDetail(ctx context.Context, mongoID string, updateData *models.AchievementDetail) error {
+	detail, exists := m.details[mongoID]
+	if !exists {
+		return errors.New("detail not found")
+	}
+	detail.Title = updateData.Title
+	detail.Description = updateData.Description
+	detail.AchievementType = updateData.AchievementType
+	detail.Details = updateData.Details
+	detail.Attachments = updateData.Attachments
+	detail.Tags = updateData.Tags
+	detail.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetUpdateScoreError - helper test untuk mensimulasikan score_breakdown yang gagal disimpan ke MongoDB
+func (m *ManualMockAchievementRepo) SetUpdateScoreError(err error) {
+	m.scoreErr = err
+}
+
+func (m *ManualMockAchievementRepo) UpdateScore(ctx context.Context, mongoID string, breakdown *models.ScoreBreakdown) error {
+	if m.scoreErr != nil {
+		return m.scoreErr
+	}
+	detail, exists := m.details[mongoID]
+	if !exists {
+		return errors.New("detail not found")
+	}
+	detail.Points = breakdown.Total
+	detail.ScoreBreakdown = breakdown
+	return nil
+}
+
+func (m *ManualMockAchievementRepo) GetAllDetailsFromMongo(ctx context.Context) ([]models.AchievementDetail, error) {
+	var result []models.AchievementDetail
+	for _, detail := range m.details {
+		result = append(result, *detail)
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementRepo) GetDetailsByIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementDetail, error) {
+	var result []models.AchievementDetail
+	for _, id := range mongoIDs {
+		if detail, exists := m.details[id]; exists {
+			result = append(result, *detail)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	ref, exists := m.references[id]
+	if !exists || ref.DeletedAt != nil {
+		return errors.New("achievement gkk ditemukan atau id tidakk benar")
+	}
+	now := time.Now()
+	ref.DeletedAt = &now
+	return nil
+}
+
+func (m *ManualMockAchievementRepo) DeleteReference(ctx context.Context, id uuid.UUID) error {
+	delete(m.references, id)
+	delete(m.history, id)
+	return nil
+}
+
+func (m *ManualMockAchievementRepo) DeleteDetail(ctx context.Context, mongoID string) error {
+	delete(m.details, mongoID)
+	return nil
+}
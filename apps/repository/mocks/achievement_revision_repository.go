@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ManualMockAchievementRevisionRepo - Mock untuk AchievementRevisionRepository
+type ManualMockAchievementRevisionRepo struct {
+	revisions map[string][]models.AchievementRevision
+}
+
+// NewManualMockAchievementRevisionRepo - Constructor
+func NewManualMockAchievementRevisionRepo() *ManualMockAchievementRevisionRepo {
+	return &ManualMockAchievementRevisionRepo{
+		revisions: make(map[string][]models.AchievementRevision),
+	}
+}
+
+func (m *ManualMockAchievementRevisionRepo) Create(ctx context.Context, revision *models.AchievementRevision) error {
+	revision.ID = primitive.NewObjectID()
+	m.revisions[revision.AchievementID] = append(m.revisions[revision.AchievementID], *revision)
+	return nil
+}
+
+func (m *ManualMockAchievementRevisionRepo) GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementRevision, error) {
+	return m.revisions[achievementID], nil
+}
+
+func (m *ManualMockAchievementRevisionRepo) SetReview(ctx context.Context, achievementID string, round int, review *models.AchievementReview) error {
+	for i := range m.revisions[achievementID] {
+		if m.revisions[achievementID][i].Round == round {
+			m.revisions[achievementID][i].Review = review
+			return nil
+		}
+	}
+	return errors.New("revision not found")
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockAchievementTypeRepo - Mock untuk AchievementTypeRepository
+type ManualMockAchievementTypeRepo struct {
+	types []*models.AchievementTypeDefinition
+}
+
+// NewManualMockAchievementTypeRepo - Constructor
+func NewManualMockAchievementTypeRepo() *ManualMockAchievementTypeRepo {
+	return &ManualMockAchievementTypeRepo{}
+}
+
+func (m *ManualMockAchievementTypeRepo) GetAll(ctx context.Context, includeInactive bool) ([]models.AchievementTypeDefinition, error) {
+	var result []models.AchievementTypeDefinition
+	for _, t := range m.types {
+		if t.IsActive || includeInactive {
+			result = append(result, *t)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockAchievementTypeRepo) GetByKey(ctx context.Context, key string) (*models.AchievementTypeDefinition, error) {
+	for _, t := range m.types {
+		if t.Key == key {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockAchievementTypeRepo) Create(ctx context.Context, t *models.AchievementTypeDefinition) error {
+	if existing, _ := m.GetByKey(ctx, t.Key); existing != nil {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	copied := *t
+	m.types = append(m.types, &copied)
+	return nil
+}
+
+func (m *ManualMockAchievementTypeRepo) Update(ctx context.Context, t *models.AchievementTypeDefinition) error {
+	for i, existing := range m.types {
+		if existing.ID == t.ID {
+			t.UpdatedAt = time.Now()
+			copied := *t
+			m.types[i] = &copied
+			return nil
+		}
+	}
+	return errors.New("achievement type not found")
+}
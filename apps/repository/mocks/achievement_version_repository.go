@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ManualMockAchievementVersionRepo - Mock untuk AchievementVersionRepository
+type ManualMockAchievementVersionRepo struct {
+	versions map[string][]models.AchievementVersion
+}
+
+// NewManualMockAchievementVersionRepo - Constructor
+func NewManualMockAchievementVersionRepo() *ManualMockAchievementVersionRepo {
+	return &ManualMockAchievementVersionRepo{
+		versions: make(map[string][]models.AchievementVersion),
+	}
+}
+
+func (m *ManualMockAchievementVersionRepo) Create(ctx context.Context, version *models.AchievementVersion) error {
+	version.ID = primitive.NewObjectID()
+	m.versions[version.AchievementID] = append(m.versions[version.AchievementID], *version)
+	return nil
+}
+
+func (m *ManualMockAchievementVersionRepo) GetByAchievementID(ctx context.Context, achievementID string) ([]models.AchievementVersion, error) {
+	return m.versions[achievementID], nil
+}
+
+func (m *ManualMockAchievementVersionRepo) GetByVersion(ctx context.Context, achievementID string, version int) (*models.AchievementVersion, error) {
+	for _, v := range m.versions[achievementID] {
+		if v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockDelegationRepo - Mock untuk DelegationRepository
+type ManualMockDelegationRepo struct {
+	delegations map[uuid.UUID]*models.VerificationDelegation
+}
+
+// NewManualMockDelegationRepo - Constructor
+func NewManualMockDelegationRepo() *ManualMockDelegationRepo {
+	return &ManualMockDelegationRepo{
+		delegations: make(map[uuid.UUID]*models.VerificationDelegation),
+	}
+}
+
+func (m *ManualMockDelegationRepo) Create(ctx context.Context, d *models.VerificationDelegation) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	d.CreatedAt = time.Now()
+	m.delegations[d.ID] = d
+	return nil
+}
+
+func (m *ManualMockDelegationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.VerificationDelegation, error) {
+	if d, exists := m.delegations[id]; exists {
+		return d, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockDelegationRepo) GetByLecturer(ctx context.Context, lecturerID uuid.UUID) ([]models.VerificationDelegation, error) {
+	var result []models.VerificationDelegation
+	for _, d := range m.delegations {
+		if d.AdvisorID == lecturerID || d.DelegateID == lecturerID {
+			result = append(result, *d)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockDelegationRepo) FindActive(ctx context.Context, advisorID, delegateID uuid.UUID, at time.Time) (*models.VerificationDelegation, error) {
+	for _, d := range m.delegations {
+		if d.AdvisorID == advisorID && d.DelegateID == delegateID && d.ActiveAt(at) {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockDelegationRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	if d, exists := m.delegations[id]; exists && d.RevokedAt == nil {
+		now := time.Now()
+		d.RevokedAt = &now
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockFileRepo - Mock untuk FileRepository. Dijaga mutex karena sweeper bisa berjalan bersamaan dengan upload
+type ManualMockFileRepo struct {
+	mu    sync.Mutex
+	files map[uuid.UUID]*models.StoredFile
+}
+
+// NewManualMockFileRepo - Constructor
+func NewManualMockFileRepo() *ManualMockFileRepo {
+	return &ManualMockFileRepo{files: make(map[uuid.UUID]*models.StoredFile)}
+}
+
+func (m *ManualMockFileRepo) Create(ctx context.Context, file *models.StoredFile) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file.ID = uuid.New()
+	file.CreatedAt = time.Now()
+	stored := *file
+	m.files[file.ID] = &stored
+	return nil
+}
+
+func (m *ManualMockFileRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.StoredFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if file, exists := m.files[id]; exists {
+		found := *file
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockFileRepo) TotalSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total int64
+	for _, file := range m.files {
+		if file.OwnerID == ownerID {
+			total += file.Size
+		}
+	}
+	return total, nil
+}
+
+func (m *ManualMockFileRepo) LinkToAchievement(ctx context.Context, ids []uuid.UUID, achievementID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		file, exists := m.files[id]
+		if !exists || (file.AchievementID != nil && *file.AchievementID != achievementID) {
+			return sql.ErrNoRows
+		}
+	}
+	now := time.Now()
+	for _, id := range ids {
+		file := m.files[id]
+		if file.LinkedAt == nil {
+			file.LinkedAt = &now
+		}
+		file.AchievementID = &achievementID
+	}
+	return nil
+}
+
+func (m *ManualMockFileRepo) GetUnlinkedBefore(ctx context.Context, before time.Time) ([]models.StoredFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var files []models.StoredFile
+	for _, file := range m.files {
+		if file.AchievementID == nil && file.CreatedAt.Before(before) {
+			files = append(files, *file)
+		}
+	}
+	return files, nil
+}
+
+func (m *ManualMockFileRepo) CountByStorageKey(ctx context.Context, storageKey string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, file := range m.files {
+		if file.StorageKey == storageKey {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *ManualMockFileRepo) CountByThumbnailKey(ctx context.Context, thumbnailKey string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, file := range m.files {
+		if file.ThumbnailKey == thumbnailKey {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *ManualMockFileRepo) Delete(ctx context.Context, id uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file, exists := m.files[id]
+	if !exists || file.AchievementID != nil {
+		return 0, nil
+	}
+	delete(m.files, id)
+	return 1, nil
+}
+
+// SetCreatedAt - helper test untuk memundurkan waktu upload
+func (m *ManualMockFileRepo) SetCreatedAt(id uuid.UUID, createdAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if file, exists := m.files[id]; exists {
+		file.CreatedAt = createdAt
+	}
+}
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockInvitationRepo - Mock untuk InvitationRepository
+type ManualMockInvitationRepo struct {
+	invitations map[uuid.UUID]*models.Invitation
+}
+
+// NewManualMockInvitationRepo - Constructor
+func NewManualMockInvitationRepo() *ManualMockInvitationRepo {
+	return &ManualMockInvitationRepo{
+		invitations: make(map[uuid.UUID]*models.Invitation),
+	}
+}
+
+func (m *ManualMockInvitationRepo) Create(ctx context.Context, inv *models.Invitation) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+	inv.CreatedAt = time.Now()
+	m.invitations[inv.ID] = inv
+	return nil
+}
+
+func (m *ManualMockInvitationRepo) GetByHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	for _, inv := range m.invitations {
+		if inv.TokenHash == tokenHash {
+			return inv, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockInvitationRepo) GetPending(ctx context.Context) ([]models.Invitation, error) {
+	var result []models.Invitation
+	for _, inv := range m.invitations {
+		if inv.AcceptedAt == nil && time.Now().Before(inv.ExpiresAt) {
+			result = append(result, *inv)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockInvitationRepo) MarkAccepted(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	inv, exists := m.invitations[id]
+	if !exists || inv.AcceptedAt != nil || time.Now().After(inv.ExpiresAt) {
+		return false, nil
+	}
+	now := time.Now()
+	inv.AcceptedAt = &now
+	inv.AcceptedBy = &userID
+	return true, nil
+}
+
+func (m *ManualMockInvitationRepo) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	inv, exists := m.invitations[id]
+	if !exists || inv.AcceptedAt != nil {
+		return false, nil
+	}
+	delete(m.invitations, id)
+	return true, nil
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockLoginEventRepo - Mock untuk LoginEventRepository
+type ManualMockLoginEventRepo struct {
+	Events []models.LoginEvent
+}
+
+// NewManualMockLoginEventRepo - Constructor
+func NewManualMockLoginEventRepo() *ManualMockLoginEventRepo {
+	return &ManualMockLoginEventRepo{}
+}
+
+func (m *ManualMockLoginEventRepo) Create(ctx context.Context, event *models.LoginEvent) error {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	event.CreatedAt = time.Now()
+	m.Events = append(m.Events, *event)
+	return nil
+}
+
+func (m *ManualMockLoginEventRepo) GetRecent(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	var result []models.LoginEvent
+	for i := len(m.Events) - 1; i >= 0; i-- {
+		e := m.Events[i]
+		if filter.UserID != nil && (e.UserID == nil || *e.UserID != *filter.UserID) {
+			continue
+		}
+		if filter.IPAddress != "" && e.IPAddress != filter.IPAddress {
+			continue
+		}
+		result = append(result, e)
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
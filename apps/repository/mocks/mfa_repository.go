@@ -0,0 +1,100 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockMFARepo - Mock untuk MFARepository
+type ManualMockMFARepo struct {
+	mfa           map[uuid.UUID]*models.UserMFA
+	recoveryCodes map[uuid.UUID]map[string]bool // userID -> hash -> sudah dipakai
+	requiredRoles map[uuid.UUID]bool
+}
+
+// NewManualMockMFARepo - Constructor
+func NewManualMockMFARepo() *ManualMockMFARepo {
+	return &ManualMockMFARepo{
+		mfa:           make(map[uuid.UUID]*models.UserMFA),
+		recoveryCodes: make(map[uuid.UUID]map[string]bool),
+		requiredRoles: make(map[uuid.UUID]bool),
+	}
+}
+
+// RegisterRole - helper test supaya SetRoleRequirement menemukan role-nya
+func (m *ManualMockMFARepo) RegisterRole(roleID uuid.UUID) {
+	m.requiredRoles[roleID] = false
+}
+
+func (m *ManualMockMFARepo) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserMFA, error) {
+	if rec, exists := m.mfa[userID]; exists {
+		copied := *rec
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockMFARepo) SavePending(ctx context.Context, userID uuid.UUID, secretEncrypted string) error {
+	if rec, exists := m.mfa[userID]; exists && rec.Enabled {
+		return nil
+	}
+	m.mfa[userID] = &models.UserMFA{UserID: userID, SecretEncrypted: secretEncrypted, CreatedAt: time.Now()}
+	return nil
+}
+
+func (m *ManualMockMFARepo) Enable(ctx context.Context, userID uuid.UUID) error {
+	if rec, exists := m.mfa[userID]; exists {
+		now := time.Now()
+		rec.Enabled = true
+		rec.ConfirmedAt = &now
+	}
+	return nil
+}
+
+func (m *ManualMockMFARepo) Delete(ctx context.Context, userID uuid.UUID) error {
+	delete(m.mfa, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+func (m *ManualMockMFARepo) MarkStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	rec, exists := m.mfa[userID]
+	if !exists || rec.LastUsedStep >= step {
+		return false, nil
+	}
+	rec.LastUsedStep = step
+	return true, nil
+}
+
+func (m *ManualMockMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	codes := make(map[string]bool)
+	for _, h := range codeHashes {
+		codes[h] = false
+	}
+	m.recoveryCodes[userID] = codes
+	return nil
+}
+
+func (m *ManualMockMFARepo) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	used, exists := m.recoveryCodes[userID][codeHash]
+	if !exists || used {
+		return false, nil
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *ManualMockMFARepo) IsRequiredForRole(ctx context.Context, roleID uuid.UUID) (bool, error) {
+	return m.requiredRoles[roleID], nil
+}
+
+func (m *ManualMockMFARepo) SetRoleRequirement(ctx context.Context, roleID uuid.UUID, required bool) (bool, error) {
+	if _, exists := m.requiredRoles[roleID]; !exists {
+		return false, nil
+	}
+	m.requiredRoles[roleID] = required
+	return true, nil
+}
@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockPasswordResetRepo - Mock untuk PasswordResetRepository
+type ManualMockPasswordResetRepo struct {
+	tokens map[uuid.UUID]*models.PasswordResetToken
+}
+
+// NewManualMockPasswordResetRepo - Constructor
+func NewManualMockPasswordResetRepo() *ManualMockPasswordResetRepo {
+	return &ManualMockPasswordResetRepo{
+		tokens: make(map[uuid.UUID]*models.PasswordResetToken),
+	}
+}
+
+func (m *ManualMockPasswordResetRepo) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = time.Now()
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *ManualMockPasswordResetRepo) GetByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockPasswordResetRepo) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	t, exists := m.tokens[id]
+	if !exists || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return false, nil
+	}
+	now := time.Now()
+	t.UsedAt = &now
+	return true, nil
+}
+
+func (m *ManualMockPasswordResetRepo) InvalidateByUserID(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"errors"
+	"strings"
 	"uas-pelaporan-prestasi-mahasiswa/apps/models"
 
 	"github.com/google/uuid"
@@ -12,6 +13,7 @@ import (
 type ManualMockPermissionRepo struct {
 	permissions     map[string]*models.Permission
 	rolePermissions map[uuid.UUID][]uuid.UUID // roleID -> []permissionID
+	roleNames       map[string]uuid.UUID      // nama role (lowercase) -> roleID
 }
 
 // NewManualMockPermissionRepo - Constructor
@@ -19,9 +21,15 @@ func NewManualMockPermissionRepo() *ManualMockPermissionRepo {
 	return &ManualMockPermissionRepo{
 		permissions:     make(map[string]*models.Permission),
 		rolePermissions: make(map[uuid.UUID][]uuid.UUID),
+		roleNames:       make(map[string]uuid.UUID),
 	}
 }
 
+// RegisterRole - Daftarkan nama role supaya GetByRoleName bisa resolve ke roleID
+func (m *ManualMockPermissionRepo) RegisterRole(roleID uuid.UUID, name string) {
+	m.roleNames[strings.ToLower(name)] = roleID
+}
+
 
 func (m *ManualMockPermissionRepo) Create(ctx context.Context, perm *models.Permission) error {
 	if perm.Name == "" {
@@ -50,6 +58,16 @@ func (m *ManualMockPermissionRepo) GetByName(ctx context.Context, name string) (
 }
 
 
+func (m *ManualMockPermissionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
+	for _, p := range m.permissions {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+
 func (m *ManualMockPermissionRepo) GetAll(ctx context.Context) ([]models.Permission, error) {
 	var result []models.Permission
 	for _, p := range m.permissions {
@@ -85,3 +103,35 @@ func (m *ManualMockPermissionRepo) GetByRoleID(ctx context.Context, roleID uuid.
 	}
 	return result, nil
 }
+
+
+func (m *ManualMockPermissionRepo) GetByRoleName(ctx context.Context, roleName string) ([]models.Permission, error) {
+	roleID, exists := m.roleNames[strings.ToLower(roleName)]
+	if !exists {
+		return nil, nil
+	}
+	return m.GetByRoleID(ctx, roleID)
+}
+
+func (m *ManualMockPermissionRepo) RevokeFromRole(ctx context.Context, roleID uuid.UUID, permID uuid.UUID) error {
+	kept := m.rolePermissions[roleID][:0]
+	for _, pid := range m.rolePermissions[roleID] {
+		if pid != permID {
+			kept = append(kept, pid)
+		}
+	}
+	m.rolePermissions[roleID] = kept
+	return nil
+}
+
+func (m *ManualMockPermissionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	for name, p := range m.permissions {
+		if p.ID == id {
+			delete(m.permissions, name)
+		}
+	}
+	for roleID := range m.rolePermissions {
+		m.RevokeFromRole(ctx, roleID, id)
+	}
+	return nil
+}
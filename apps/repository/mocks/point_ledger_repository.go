@@ -0,0 +1,66 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockPointLedgerRepo - Mock untuk PointLedgerRepository
+type ManualMockPointLedgerRepo struct {
+	entries   []models.PointLedgerEntry
+	createErr error
+	failAfter int
+}
+
+// NewManualMockPointLedgerRepo - Constructor
+func NewManualMockPointLedgerRepo() *ManualMockPointLedgerRepo {
+	return &ManualMockPointLedgerRepo{}
+}
+
+// SetCreateError - helper test untuk mensimulasikan point_ledger yang gagal ditulis
+func (m *ManualMockPointLedgerRepo) SetCreateError(err error) {
+	m.createErr = err
+	m.failAfter = 0
+}
+
+// SetCreateErrorAfter - seperti SetCreateError, tetapi baru gagal setelah n entri berhasil ditulis
+func (m *ManualMockPointLedgerRepo) SetCreateErrorAfter(n int, err error) {
+	m.createErr = err
+	m.failAfter = n
+}
+
+func (m *ManualMockPointLedgerRepo) Create(ctx context.Context, entry *models.PointLedgerEntry) error {
+	if m.createErr != nil {
+		if m.failAfter == 0 {
+			return m.createErr
+		}
+		m.failAfter--
+	}
+	entry.ID = uuid.New()
+	entry.CreatedAt = time.Now()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *ManualMockPointLedgerRepo) GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointLedgerEntry, error) {
+	var result []models.PointLedgerEntry
+	for _, e := range m.entries {
+		if e.StudentID == studentID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockPointLedgerRepo) GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.PointLedgerEntry, error) {
+	var result []models.PointLedgerEntry
+	for _, e := range m.entries {
+		if e.AchievementID == achievementID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
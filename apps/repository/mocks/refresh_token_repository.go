@@ -0,0 +1,97 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockRefreshTokenRepo - Mock untuk RefreshTokenRepository
+type ManualMockRefreshTokenRepo struct {
+	tokens map[uuid.UUID]*models.RefreshToken
+}
+
+// NewManualMockRefreshTokenRepo - Constructor
+func NewManualMockRefreshTokenRepo() *ManualMockRefreshTokenRepo {
+	return &ManualMockRefreshTokenRepo{
+		tokens: make(map[uuid.UUID]*models.RefreshToken),
+	}
+}
+
+func (m *ManualMockRefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	for _, t := range m.tokens {
+		if t.TokenHash == token.TokenHash {
+			return errors.New("token hash already exists")
+		}
+	}
+
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = time.Now()
+
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *ManualMockRefreshTokenRepo) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockRefreshTokenRepo) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error) {
+	var result []models.RefreshToken
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.RevokedAt == nil && t.ExpiresAt.After(time.Now()) {
+			result = append(result, *t)
+		}
+	}
+	return result, nil
+}
+
+func (m *ManualMockRefreshTokenRepo) MarkRotated(ctx context.Context, id uuid.UUID, replacedBy uuid.UUID) (bool, error) {
+	t, exists := m.tokens[id]
+	if !exists || t.RevokedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	t.RevokedAt = &now
+	t.ReplacedBy = &replacedBy
+	return true, nil
+}
+
+func (m *ManualMockRefreshTokenRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	if t, exists := m.tokens[id]; exists && t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+func (m *ManualMockRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *ManualMockRefreshTokenRepo) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+// ManualMockRoleRepo - Mock untuk RoleRepository
+type ManualMockRoleRepo struct {
+	roles      map[uuid.UUID]*models.Role
+	userCounts map[uuid.UUID]int
+}
+
+// NewManualMockRoleRepo - Constructor
+func NewManualMockRoleRepo() *ManualMockRoleRepo {
+	return &ManualMockRoleRepo{
+		roles:      make(map[uuid.UUID]*models.Role),
+		userCounts: make(map[uuid.UUID]int),
+	}
+}
+
+// AddRole - helper test untuk menyiapkan role
+func (m *ManualMockRoleRepo) AddRole(name string) *models.Role {
+	role := &models.Role{ID: uuid.New(), Name: name, CreatedAt: time.Now()}
+	m.roles[role.ID] = role
+	return role
+}
+
+// SetUserCount - helper test untuk mensimulasikan user yang memakai role
+func (m *ManualMockRoleRepo) SetUserCount(roleID uuid.UUID, count int) {
+	m.userCounts[roleID] = count
+}
+
+func (m *ManualMockRoleRepo) GetAll(ctx context.Context) ([]models.Role, error) {
+	var result []models.Role
+	for _, r := range m.roles {
+		result = append(result, *r)
+	}
+	return result, nil
+}
+
+func (m *ManualMockRoleRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	if r, exists := m.roles[id]; exists {
+		return r, nil
+	}
+	return nil, nil
+}
+
+func (m *ManualMockRoleRepo) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	for _, r := range m.roles {
+		if strings.EqualFold(r.Name, name) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *ManualMockRoleRepo) Create(ctx context.Context, role *models.Role) error {
+	if existing, _ := m.GetByName(ctx, role.Name); existing != nil {
+		return errors.New("role already exists")
+	}
+	role.ID = uuid.New()
+	role.CreatedAt = time.Now()
+	m.roles[role.ID] = role
+	return nil
+}
+
+func (m *ManualMockRoleRepo) Update(ctx context.Context, role *models.Role) error {
+	existing, exists := m.roles[role.ID]
+	if !exists {
+		return errors.New("role not found")
+	}
+	existing.Name = role.Name
+	existing.Description = role.Description
+	return nil
+}
+
+func (m *ManualMockRoleRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(m.roles, id)
+	return nil
+}
+
+func (m *ManualMockRoleRepo) CountUsers(ctx context.Context, id uuid.UUID) (int, error) {
+	return m.userCounts[id], nil
+}
@@ -10,18 +10,25 @@ import (
 
 // ManualMockStudentRepo - Mock untuk StudentsRepository
 type ManualMockStudentRepo struct {
-	students map[uuid.UUID]*models.Students
-	byUserID map[uuid.UUID]*models.Students
+	students      map[uuid.UUID]*models.Students
+	byUserID      map[uuid.UUID]*models.Students
+	inactiveUsers map[uuid.UUID]bool
 }
 
 // NewManualMockStudentRepo - Constructor
 func NewManualMockStudentRepo() *ManualMockStudentRepo {
 	return &ManualMockStudentRepo{
-		students: make(map[uuid.UUID]*models.Students),
-		byUserID: make(map[uuid.UUID]*models.Students),
+		students:      make(map[uuid.UUID]*models.Students),
+		byUserID:      make(map[uuid.UUID]*models.Students),
+		inactiveUsers: make(map[uuid.UUID]bool),
 	}
 }
 
+// SetUserActive - Simulasi kolom users.is_active yang di-join oleh GetAll
+func (m *ManualMockStudentRepo) SetUserActive(userID uuid.UUID, active bool) {
+	m.inactiveUsers[userID] = !active
+}
+
 
 func (m *ManualMockStudentRepo) Create(ctx context.Context, student *models.Students) error {
 	if student.StudentID == "" {
@@ -66,9 +73,12 @@ func (m *ManualMockStudentRepo) GetByID(ctx context.Context, id uuid.UUID) (*mod
 }
 
 
-func (m *ManualMockStudentRepo) GetAll(ctx context.Context) ([]models.Students, error) {
+func (m *ManualMockStudentRepo) GetAll(ctx context.Context, includeInactive bool) ([]models.Students, error) {
 	var result []models.Students
 	for _, s := range m.students {
+		if !includeInactive && m.inactiveUsers[s.UserID] {
+			continue
+		}
 		result = append(result, *s)
 	}
 	return result, nil
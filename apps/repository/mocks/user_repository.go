@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"errors"
+	"time"
 	"uas-pelaporan-prestasi-mahasiswa/apps/models" // Pastikan path ini sesuai go.mod kamu
 
 	"github.com/google/uuid"
@@ -129,4 +130,34 @@ func (m *ManualMockUserRepo) UpdateRole(ctx context.Context, uid uuid.UUID, rid
 		}
 	}
 	return errors.New("user not found")
-}
\ No newline at end of file
+}
+
+
+func (m *ManualMockUserRepo) SetActive(ctx context.Context, uid uuid.UUID, active bool, reason string) error {
+	for _, u := range m.users {
+		if u.ID == uid {
+			u.IsActive = active
+			if active {
+				u.DeactivatedAt = nil
+				u.DeactivationReason = nil
+			} else {
+				now := time.Now()
+				u.DeactivatedAt = &now
+				u.DeactivationReason = &reason
+			}
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
+
+
+func (m *ManualMockUserRepo) UpdatePassword(ctx context.Context, uid uuid.UUID, passwordHash string) error {
+	for _, u := range m.users {
+		if u.ID == uid {
+			u.PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return errors.New("user not found")
+}
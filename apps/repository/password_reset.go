@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresPasswordResetRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPasswordResetRepository(db *sql.DB) *PostgresPasswordResetRepository {
+	return &PostgresPasswordResetRepository{db: db}
+}
+
+func (r *PostgresPasswordResetRepository) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, t.UserID, t.TokenHash, t.ExpiresAt).Scan(&t.ID, &t.CreatedAt)
+}
+
+func (r *PostgresPasswordResetRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, used_at, created_at
+	          FROM password_reset_tokens WHERE token_hash = $1`
+
+	var t models.PasswordResetToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed return false kalau token sudah dipakai duluan oleh request lain
+func (r *PostgresPasswordResetRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// InvalidateByUserID menandai semua token reset user yang belum terpakai sebagai terpakai
+func (r *PostgresPasswordResetRepository) InvalidateByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
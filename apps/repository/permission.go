@@ -50,11 +50,11 @@ func (r *PostgresPermissionRepository) GetAll(ctx context.Context) ([]models.Per
 }
 
 func ( r *PostgresPermissionRepository) GetByName(ctx context.Context , name string) (*models.Permission, error){
-	query := `SELECT name, resource, action, description 
+	query := `SELECT id, name, resource, action, COALESCE(description, '')
 			FROM permissions
 			where name = $1`
 	var p models.Permission
-	err := r.db.QueryRowContext(ctx, query, name).Scan(p.ID,p.Name, p.Resource, p.Action, p.Description)
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil 
@@ -65,6 +65,19 @@ func ( r *PostgresPermissionRepository) GetByName(ctx context.Context , name str
 	return  &p, nil
 }
 
+func (r *PostgresPermissionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
+	query := `SELECT id, name, resource, action, COALESCE(description, '') FROM permissions WHERE id = $1`
+	var p models.Permission
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 func ( r *PostgresPermissionRepository)AssignToRole(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
 	query := `INSERT INTO role_permissions(role_id, permission_id)	
 			  VALUES ($1,$2)
@@ -76,10 +89,11 @@ func ( r *PostgresPermissionRepository)AssignToRole(ctx context.Context, roleID
 
 func (r *PostgresPermissionRepository) GetByRoleID(ctx context.Context, roleID uuid.UUID) ([]models.Permission, error) {
     query := `
-        SELECT p.id, p.name 
+        SELECT p.id, p.name, p.resource, p.action, COALESCE(p.description, '')
         FROM permissions p
         JOIN role_permissions rp ON p.id = rp.permission_id
         WHERE rp.role_id = $1
+        ORDER BY p.resource, p.action
     `
     
     rows, err := r.db.QueryContext(ctx, query, roleID)
@@ -91,7 +105,7 @@ func (r *PostgresPermissionRepository) GetByRoleID(ctx context.Context, roleID u
     var permissions []models.Permission
     for rows.Next() {
         var p models.Permission
-        if err := rows.Scan(&p.ID, &p.Name); err != nil {
+        if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
             return nil, err
         }
         permissions = append(permissions, p)
@@ -99,3 +113,53 @@ func (r *PostgresPermissionRepository) GetByRoleID(ctx context.Context, roleID u
     return permissions, nil
 }
 
+
+func (r *PostgresPermissionRepository) GetByRoleName(ctx context.Context, roleName string) ([]models.Permission, error) {
+	query := `
+		SELECT p.id, p.name, p.resource, p.action
+		FROM permissions p
+		JOIN role_permissions rp ON p.id = rp.permission_id
+		JOIN roles r ON r.id = rp.role_id
+		WHERE LOWER(r.name) = LOWER($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, roleName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, rows.Err()
+}
+
+// RevokeFromRole kebalikan AssignToRole
+func (r *PostgresPermissionRepository) RevokeFromRole(ctx context.Context, roleID uuid.UUID, permissionID uuid.UUID) error {
+	query := `DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`
+	_, err := r.db.ExecContext(ctx, query, roleID, permissionID)
+	return err
+}
+
+// Delete menghapus permission beserta relasinya di role_permissions
+func (r *PostgresPermissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE permission_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM permissions WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresPointLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPointLedgerRepository(db *sql.DB) *PostgresPointLedgerRepository {
+	return &PostgresPointLedgerRepository{db: db}
+}
+
+const pointLedgerColumns = `id, student_id, achievement_id, entry_type, points, category, academic_year, COALESCE(rules_version, ''), COALESCE(note, ''), created_by, created_at`
+
+func (r *PostgresPointLedgerRepository) Create(ctx context.Context, entry *models.PointLedgerEntry) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	query := `
+		INSERT INTO point_ledger (id, student_id, achievement_id, entry_type, points, category, academic_year, rules_version, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		entry.ID, entry.StudentID, entry.AchievementID, entry.EntryType, entry.Points,
+		entry.Category, entry.AcademicYear, entry.RulesVersion, entry.Note, entry.CreatedBy,
+	).Scan(&entry.CreatedAt)
+}
+
+func (r *PostgresPointLedgerRepository) GetByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointLedgerEntry, error) {
+	query := `SELECT ` + pointLedgerColumns + ` FROM point_ledger WHERE student_id = $1 ORDER BY created_at`
+	return r.query(ctx, query, studentID)
+}
+
+func (r *PostgresPointLedgerRepository) GetByAchievementID(ctx context.Context, achievementID uuid.UUID) ([]models.PointLedgerEntry, error) {
+	query := `SELECT ` + pointLedgerColumns + ` FROM point_ledger WHERE achievement_id = $1 ORDER BY created_at`
+	return r.query(ctx, query, achievementID)
+}
+
+func (r *PostgresPointLedgerRepository) query(ctx context.Context, query string, arg interface{}) ([]models.PointLedgerEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.PointLedgerEntry
+	for rows.Next() {
+		var e models.PointLedgerEntry
+		if err := rows.Scan(&e.ID, &e.StudentID, &e.AchievementID, &e.EntryType, &e.Points, &e.Category,
+			&e.AcademicYear, &e.RulesVersion, &e.Note, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
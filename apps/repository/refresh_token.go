@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, device_label, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		t.ID,
+		t.UserID,
+		t.FamilyID,
+		t.TokenHash,
+		t.DeviceLabel,
+		t.ExpiresAt,
+	).Scan(&t.CreatedAt)
+}
+
+func (r *PostgresRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, device_label, expires_at, revoked_at, replaced_by, created_at
+	          FROM refresh_tokens WHERE token_hash = $1`
+
+	var t models.RefreshToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.DeviceLabel, &t.ExpiresAt, &t.RevokedAt, &t.ReplacedBy, &t.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, device_label, expires_at, revoked_at, replaced_by, created_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.RefreshToken
+	for rows.Next() {
+		var t models.RefreshToken
+		if err := rows.Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.DeviceLabel, &t.ExpiresAt, &t.RevokedAt, &t.ReplacedBy, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// MarkRotated mencabut token lama dan mencatat penggantinya. Return false kalau token
+// sudah dicabut duluan (dipakai dua kali), tanda ada reuse.
+func (r *PostgresRefreshTokenRepository) MarkRotated(ctx context.Context, id uuid.UUID, replacedBy uuid.UUID) (bool, error) {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1 WHERE id = $2 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, replacedBy, id)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
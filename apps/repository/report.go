@@ -39,6 +39,7 @@ func (r *ReportRepo) GetStatistics(ctx context.Context) (*models.StatisticsRespo
 		SELECT 
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'draft' THEN 1 ELSE 0 END), 0) as draft,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'submitted' THEN 1 ELSE 0 END), 0) as submitted,
+			COALESCE(SUM(CASE WHEN LOWER(status) = 'needs_revision' THEN 1 ELSE 0 END), 0) as needs_revision,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'verified' THEN 1 ELSE 0 END), 0) as verified,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'rejected' THEN 1 ELSE 0 END), 0) as rejected
 		FROM achievement_references 
@@ -47,6 +48,7 @@ func (r *ReportRepo) GetStatistics(ctx context.Context) (*models.StatisticsRespo
 	err = r.pgDB.QueryRowContext(ctx, query).Scan(
 		&stats.AchievementStats.Draft,
 		&stats.AchievementStats.Submitted,
+		&stats.AchievementStats.NeedsRevision,
 		&stats.AchievementStats.Verified,
 		&stats.AchievementStats.Rejected,
 	)
@@ -66,6 +68,7 @@ func (r *ReportRepo) GetAchievementStatsByStudentID(ctx context.Context, student
 			COUNT(*) as total,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'draft' THEN 1 ELSE 0 END), 0) as draft,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'submitted' THEN 1 ELSE 0 END), 0) as submitted,
+			COALESCE(SUM(CASE WHEN LOWER(status) = 'needs_revision' THEN 1 ELSE 0 END), 0) as needs_revision,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'verified' THEN 1 ELSE 0 END), 0) as verified,
 			COALESCE(SUM(CASE WHEN LOWER(status) = 'rejected' THEN 1 ELSE 0 END), 0) as rejected
 		FROM achievement_references 
@@ -75,6 +78,7 @@ func (r *ReportRepo) GetAchievementStatsByStudentID(ctx context.Context, student
 		&total,
 		&stats.Draft,
 		&stats.Submitted,
+		&stats.NeedsRevision,
 		&stats.Verified,
 		&stats.Rejected,
 	)
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/google/uuid"
+)
+
+type PostgresRoleRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRoleRepository(db *sql.DB) *PostgresRoleRepository {
+	return &PostgresRoleRepository{db: db}
+}
+
+const roleColumns = `id, name, COALESCE(description, ''), COALESCE(mfa_required, FALSE), created_at`
+
+func scanRole(scanner interface{ Scan(...interface{}) error }) (*models.Role, error) {
+	var role models.Role
+	if err := scanner.Scan(&role.ID, &role.Name, &role.Description, &role.MFARequired, &role.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *PostgresRoleRepository) GetAll(ctx context.Context) ([]models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *PostgresRoleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE id = $1`
+	return r.scanOne(ctx, query, id)
+}
+
+// GetByName tidak case-sensitive, sama seperti pengecekan role di middleware
+func (r *PostgresRoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	query := `SELECT ` + roleColumns + ` FROM roles WHERE LOWER(name) = LOWER($1)`
+	return r.scanOne(ctx, query, name)
+}
+
+func (r *PostgresRoleRepository) scanOne(ctx context.Context, query string, arg interface{}) (*models.Role, error) {
+	role, err := scanRole(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return role, err
+}
+
+func (r *PostgresRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	query := `
+		INSERT INTO roles (name, description)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, role.Name, role.Description).Scan(&role.ID, &role.CreatedAt)
+}
+
+func (r *PostgresRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	query := `UPDATE roles SET name = $1, description = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, role.Name, role.Description, role.ID)
+	return err
+}
+
+// Delete ikut menghapus relasi role_permissions. Pastikan CountUsers = 0 sebelum memanggil ini.
+func (r *PostgresRoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresRoleRepository) CountUsers(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role_id = $1`, id).Scan(&count)
+	return count, err
+}
@@ -66,9 +66,12 @@ func (r *PostStudentRepository) GetByID(ctx context.Context, id uuid.UUID) (*mod
 	return &s, nil
 }
 
-func (r *PostStudentRepository) GetAll(ctx context.Context) ([]models.Students, error) {
-	query := `SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at FROM students`
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *PostStudentRepository) GetAll(ctx context.Context, includeInactive bool) ([]models.Students, error) {
+	query := `SELECT s.id, s.user_id, s.student_id, s.program_study, s.academic_year, s.advisor_id, s.created_at
+	          FROM students s
+	          JOIN users u ON u.id = s.user_id
+	          WHERE $1 OR u.is_active = TRUE`
+	rows, err := r.db.QueryContext(ctx, query, includeInactive)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryTokenRevocationStore cocok untuk deployment satu instance. Data hilang saat restart,
+// pakai PostgresTokenRevocationStore kalau aplikasi jalan lebih dari satu instance.
+type MemoryTokenRevocationStore struct {
+	maxTokenTTL time.Duration
+
+	mu      sync.Mutex
+	revoked map[string]time.Time    // jti -> expiresAt
+	cutoffs map[uuid.UUID]time.Time // userID -> notBefore
+}
+
+// NewMemoryTokenRevocationStore - maxTokenTTL adalah umur access token terpanjang,
+// setelah lewat dari itu batas per user sudah tidak berguna dan boleh dibuang
+func NewMemoryTokenRevocationStore(maxTokenTTL time.Duration) *MemoryTokenRevocationStore {
+	return &MemoryTokenRevocationStore{
+		maxTokenTTL: maxTokenTTL,
+		revoked:     make(map[string]time.Time),
+		cutoffs:     make(map[uuid.UUID]time.Time),
+	}
+}
+
+// purgeLocked membuang entry yang sudah kadaluwarsa, dipanggil dengan mu sudah di-lock
+func (s *MemoryTokenRevocationStore) purgeLocked(now time.Time) {
+	for jti, exp := range s.revoked {
+		if now.After(exp) {
+			delete(s.revoked, jti)
+		}
+	}
+	for userID, notBefore := range s.cutoffs {
+		if now.After(notBefore.Add(s.maxTokenTTL)) {
+			delete(s.cutoffs, userID)
+		}
+	}
+}
+
+func (s *MemoryTokenRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeLocked(time.Now())
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryTokenRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, exists := s.revoked[jti]
+	return exists && time.Now().Before(exp), nil
+}
+
+func (s *MemoryTokenRevocationStore) RevokeUserTokensBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeLocked(time.Now())
+	if current, exists := s.cutoffs[userID]; !exists || before.After(current) {
+		s.cutoffs[userID] = before
+	}
+	return nil
+}
+
+func (s *MemoryTokenRevocationStore) GetUserTokensNotBefore(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notBefore, exists := s.cutoffs[userID]
+	if !exists {
+		return nil, nil
+	}
+	return &notBefore, nil
+}
+
+type PostgresTokenRevocationStore struct {
+	db *sql.DB
+}
+
+func NewPostgresTokenRevocationStore(db *sql.DB) *PostgresTokenRevocationStore {
+	return &PostgresTokenRevocationStore{db: db}
+}
+
+func (s *PostgresTokenRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	if _, err := s.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		return err
+	}
+
+	// sekalian bersihkan token yang memang sudah expired
+	_, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	return err
+}
+
+func (s *PostgresTokenRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > NOW())`
+	var revoked bool
+	err := s.db.QueryRowContext(ctx, query, jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (s *PostgresTokenRevocationStore) RevokeUserTokensBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	query := `
+		INSERT INTO user_token_cutoffs (user_id, not_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET not_before = GREATEST(user_token_cutoffs.not_before, EXCLUDED.not_before)
+	`
+	_, err := s.db.ExecContext(ctx, query, userID, before)
+	return err
+}
+
+func (s *PostgresTokenRevocationStore) GetUserTokensNotBefore(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	query := `SELECT not_before FROM user_token_cutoffs WHERE user_id = $1`
+	var notBefore time.Time
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&notBefore)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &notBefore, nil
+}
@@ -18,7 +18,7 @@ func NewUserRepository(db *sql.DB) *PostUserRepository {
 }
 
 func (r *PostUserRepository)GetByUsernameOrEmail(ctx context.Context, login string) (*models.User, error){
-	query := `SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.is_active, u.role_id,
+	query := `SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.is_active, u.deactivated_at, u.deactivation_reason, u.role_id,
 		       r.id, r.name, r.description
 			   FROM users u
 			   LEFT JOIN roles r ON u.role_id = r.id
@@ -28,7 +28,7 @@ func (r *PostUserRepository)GetByUsernameOrEmail(ctx context.Context, login stri
 	var role models.Role
 
 	err := r.db.QueryRowContext(ctx, query, login).Scan(
-		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName, &user.IsActive, &user.RoleID,
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName, &user.IsActive, &user.DeactivatedAt, &user.DeactivationReason, &user.RoleID,
 		&role.ID, &role.Name, &role.Description,
 	)
 
@@ -45,7 +45,7 @@ func (r *PostUserRepository)GetByUsernameOrEmail(ctx context.Context, login stri
 
 func (r *PostUserRepository)GetAll(ctx context.Context) ([]models.User, error) {
 query := `
-		SELECT u.id, u.username, u.email, u.full_name, u.is_active, u.role_id,
+		SELECT u.id, u.username, u.email, u.full_name, u.is_active, u.deactivated_at, u.deactivation_reason, u.role_id,
 		       r.id, r.name
 		FROM users u
 		LEFT JOIN roles r ON u.role_id = r.id
@@ -62,7 +62,7 @@ query := `
 		var u models.User
 		var role models.Role
 		
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.IsActive, &u.RoleID, &role.ID, &role.Name); err != nil {
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.FullName, &u.IsActive, &u.DeactivatedAt, &u.DeactivationReason, &u.RoleID, &role.ID, &role.Name); err != nil {
 			return nil, err
 		}
 		u.Role = &role
@@ -73,7 +73,7 @@ query := `
 
 func( r *PostUserRepository)GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 query := `
-		SELECT u.id, u.username, u.email, u.full_name, u.is_active, u.role_id,
+		SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.is_active, u.deactivated_at, u.deactivation_reason, u.role_id,
 		       r.id, r.name, r.description
 		FROM users u
 		LEFT JOIN roles r ON u.role_id = r.id
@@ -83,7 +83,7 @@ query := `
 	var role models.Role
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.FullName, &user.IsActive, &user.RoleID,
+		&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.FullName, &user.IsActive, &user.DeactivatedAt, &user.DeactivationReason, &user.RoleID,
 		&role.ID, &role.Name, &role.Description,
 	)
 
@@ -98,7 +98,7 @@ func (r *PostUserRepository) Create(ctx context.Context, User *models.User) erro
 	query := `
 		INSERT INTO users (username, email, password_hash, full_name, role_id)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING created_at, updated_at, is_active
+		RETURNING id, created_at, updated_at, is_active
 	`
 	err := r.db.QueryRowContext(ctx, query,
 		User.Username,     
@@ -106,7 +106,7 @@ func (r *PostUserRepository) Create(ctx context.Context, User *models.User) erro
 		User.PasswordHash, 
 		User.FullName,     
 		User.RoleID,       
-	).Scan(&User.CreatedAt, &User.UpdatedAt, &User.IsActive)
+	).Scan(&User.ID, &User.CreatedAt, &User.UpdatedAt, &User.IsActive)
 
 	return err
 }
@@ -132,4 +132,38 @@ func (r *PostUserRepository)UpdateRole(ctx context.Context, userID uuid.UUID, ro
 	query := "UPDATE users SET role_id = $1, updated_at = NOW() WHERE id = $2"
 	_, err := r.db.ExecContext(ctx, query, roleID, userID)
 	return err
-}
\ No newline at end of file
+}
+
+// SetActive menonaktifkan / mengaktifkan kembali akun. Alasan dan waktu hanya disimpan saat dinonaktifkan.
+func (r *PostUserRepository) SetActive(ctx context.Context, userID uuid.UUID, active bool, reason string) error {
+	query := `
+		UPDATE users
+		SET is_active = $1,
+		    deactivated_at = CASE WHEN $1 THEN NULL ELSE NOW() END,
+		    deactivation_reason = CASE WHEN $1 THEN NULL ELSE NULLIF($2, '') END,
+		    updated_at = NOW()
+		WHERE id = $3
+	`
+	result, err := r.db.ExecContext(ctx, query, active, reason, userID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("user tidak di temukan")
+	}
+	return nil
+}
+
+func (r *PostUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return errors.New("user tidak di temukan")
+	}
+	return nil
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// attachmentReference - lampiran boleh diakses pemilik / anggota tim, dosen wali pemilik (atau dosen yang diberi delegasi),
+// dan admin. Lebih ketat dari readableReference karena lampiran berisi dokumen pribadi (sertifikat, KTM, dll)
+func (s *AchievementService) attachmentReference(c *fiber.Ctx) (*models.AchievementReference, error) {
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	ctx := c.Context()
+
+	if !middleware.IsSuperRole(c) {
+		if student, err := s.studentRepo.GetByUserID(ctx, userUUID); err == nil && student != nil {
+			return s.studentReference(c, "melihat lampiran", true)
+		}
+	}
+
+	achievUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
+	if err != nil || ref == nil || ref.DeletedAt != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
+	}
+	if middleware.IsSuperRole(c) {
+		return ref, nil
+	}
+
+	owner, err := s.studentRepo.GetByID(ctx, ref.StudentID)
+	if err != nil || owner == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Mahasiswa pemilik prestasi tidak ditemukan"})
+	}
+	_, _, err = s.verifiers.Authorize(ctx, userUUID, owner, time.Now())
+	if errors.Is(err, ErrNotLecturer) || errors.Is(err, ErrNotAdvisor) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak melihat lampiran prestasi ini"})
+	}
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek hak akses lampiran"})
+	}
+	return ref, nil
+}
+
+// findAttachment mengambil metadata file dari param :fileId, hanya kalau file memang lampiran prestasi :id.
+// nil berarti respons error sudah dikirim
+func (s *AchievementService) findAttachment(c *fiber.Ctx) (*models.StoredFile, error) {
+	ref, err := s.attachmentReference(c)
+	if ref == nil {
+		return nil, err
+	}
+
+	fileID, err := uuid.Parse(c.Params("fileId"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID file tidak valid"})
+	}
+
+	ctx := c.Context()
+	detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || detail == nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+	attached := false
+	for _, att := range detail.Attachments {
+		if att.FileID == fileID.String() {
+			attached = true
+			break
+		}
+	}
+	if !attached {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Lampiran tidak ditemukan pada prestasi ini"})
+	}
+
+	file, err := s.files.fileRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data file"})
+	}
+	if file == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "File tidak ditemukan"})
+	}
+	return file, nil
+}
+
+// DownloadAttachment godoc
+// @Summary      Download lampiran prestasi
+// @Description  Mengunduh lampiran prestasi. Hanya pemilik / anggota tim, dosen wali pemilik (atau delegasinya), dan admin
+// @Tags         Achievements
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        fileId path string true "ID file (UUID)"
+// @Param        inline query bool false "Tampilkan di browser (Content-Disposition inline)"
+// @Success      200  {file}    file "Isi file"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak melihat lampiran"
+// @Failure      404  {object}  map[string]interface{} "Prestasi atau lampiran tidak ditemukan"
+// @Router       /achievements/{id}/attachments/{fileId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	file, err := s.findAttachment(c)
+	if file == nil {
+		return err
+	}
+	return s.files.Send(c, file)
+}
+
+// DownloadAttachmentThumbnail godoc
+// @Summary      Preview lampiran prestasi
+// @Description  Thumbnail JPEG lampiran (gambar, atau gambar hasil scan di dalam PDF) untuk ditampilkan di daftar verifikasi. Hak akses sama dengan download lampiran
+// @Tags         Achievements
+// @Produce      jpeg
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        fileId path string true "ID file (UUID)"
+// @Success      200  {file}    file "Thumbnail JPEG"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak melihat lampiran"
+// @Failure      404  {object}  map[string]interface{} "Lampiran atau preview tidak ditemukan"
+// @Router       /achievements/{id}/attachments/{fileId}/thumbnail [get]
+func (s *AchievementService) DownloadAttachmentThumbnail(c *fiber.Ctx) error {
+	file, err := s.findAttachment(c)
+	if file == nil {
+		return err
+	}
+	return s.files.SendThumbnail(c, file)
+}
+
+// GetAttachmentSignedURL godoc
+// @Summary      Signed URL lampiran prestasi
+// @Description  Membuat URL download lampiran tanpa login yang berlaku singkat (FILE_SIGNED_URL_TTL), untuk disematkan di laporan
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        fileId path string true "ID file (UUID)"
+// @Success      200  {object}  map[string]interface{} "url dan expires_at"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak melihat lampiran"
+// @Failure      404  {object}  map[string]interface{} "Prestasi atau lampiran tidak ditemukan"
+// @Router       /achievements/{id}/attachments/{fileId}/signed-url [get]
+func (s *AchievementService) GetAttachmentSignedURL(c *fiber.Ctx) error {
+	file, err := s.findAttachment(c)
+	if file == nil {
+		return err
+	}
+	signedURL, expiresAt := s.files.SignedURL(file.ID)
+	return c.JSON(fiber.Map{
+		"message": "Signed URL lampiran",
+		"data": fiber.Map{
+			"url":        signedURL,
+			"expires_at": expiresAt,
+		},
+	})
+}
+
+// resolveAttachments mengubah fileId dari client menjadi Attachment. Setiap file harus upload milik userID dan belum
+// dilampirkan ke prestasi lain. Lampiran yang sudah ada di current dipakai apa adanya (uploadedAt tidak berubah).
+// nil berarti respons error sudah dikirim
+func (s *AchievementService) resolveAttachments(c *fiber.Ctx, userID uuid.UUID, achievementID uuid.UUID, inputs []models.AttachmentInput, current []models.Attachment) ([]models.Attachment, error) {
+	existing := make(map[string]models.Attachment, len(current))
+	for _, att := range current {
+		if att.FileID != "" {
+			existing[att.FileID] = att
+		}
+	}
+
+	attachments := make([]models.Attachment, 0, len(inputs))
+	seen := make(map[uuid.UUID]bool, len(inputs))
+	var errs []models.FieldError
+	for i, input := range inputs {
+		field := fmt.Sprintf("attachments[%d].fileId", i)
+		fileID, err := uuid.Parse(input.FileID)
+		if err != nil {
+			errs = append(errs, models.FieldError{Field: field, Message: "harus berisi fileId dari /achievements/upload"})
+			continue
+		}
+		if seen[fileID] {
+			errs = append(errs, models.FieldError{Field: field, Message: "file yang sama dilampirkan lebih dari sekali"})
+			continue
+		}
+		seen[fileID] = true
+
+		if att, ok := existing[fileID.String()]; ok {
+			attachments = append(attachments, att)
+			continue
+		}
+		file, err := s.files.fileRepo.GetByID(c.Context(), fileID)
+		if err != nil {
+			return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data file"})
+		}
+		if file == nil || file.OwnerID != userID {
+			errs = append(errs, models.FieldError{Field: field, Message: "file tidak ditemukan atau bukan upload milik Anda"})
+			continue
+		}
+		if file.AchievementID != nil && *file.AchievementID != achievementID {
+			errs = append(errs, models.FieldError{Field: field, Message: "file sudah dilampirkan ke prestasi lain"})
+			continue
+		}
+		attachments = append(attachments, models.Attachment{
+			FileID:       file.ID.String(),
+			FileName:     file.OriginalName,
+			FileURL:      s.files.AttachmentURL(achievementID, file.ID.String()),
+			FileType:     file.MimeType,
+			ThumbnailURL: s.files.AttachmentThumbnailURL(achievementID, file),
+			Hash:         file.SHA256,
+			UploadedAt:   file.CreatedAt,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Lampiran tidak valid", "errors": errs})
+	}
+	return attachments, nil
+}
+
+// linkAttachments menandai file sebagai milik prestasi supaya tidak dihapus sweeper dan tidak bisa dilampirkan ke prestasi lain.
+// File yang sudah dilepas dari prestasi tetap tertaut karena masih dipakai versi lama
+func (s *AchievementService) linkAttachments(ctx context.Context, achievementID uuid.UUID, attachments []models.Attachment) error {
+	fileIDs := make([]uuid.UUID, 0, len(attachments))
+	for _, att := range attachments {
+		fileID, err := uuid.Parse(att.FileID)
+		if err != nil {
+			continue // lampiran lama sebelum ada tabel files
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+	if len(fileIDs) == 0 {
+		return nil
+	}
+	return s.files.fileRepo.LinkToAchievement(ctx, fileIDs, achievementID)
+}
+
+// respondLinkError 409 kalau file keburu dilampirkan ke prestasi lain oleh request lain setelah resolveAttachments
+func respondLinkError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(409).JSON(fiber.Map{"error": "File sudah dilampirkan ke prestasi lain"})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Gagal menautkan file ke prestasi"})
+}
+
+// saveAttachments menyimpan daftar lampiran baru sebagai perubahan prestasi (versi lama diarsipkan seperti Update)
+func (s *AchievementService) saveAttachments(c *fiber.Ctx, ref *models.AchievementReference, previous *models.AchievementDetail, attachments []models.Attachment) (*models.AchievementVersion, error) {
+	ctx := c.Context()
+	userUUID, _ := currentUserID(c)
+	version, err := s.archiveVersion(ctx, ref, previous, userUUID)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan versi sebelumnya"})
+	}
+
+	updated := *previous
+	updated.Attachments = attachments
+	if err := s.achievementRepo.UpdateDetail(ctx, ref.MongoAchievementID, &updated); err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal update data: " + err.Error()})
+	}
+	if err := s.linkAttachments(ctx, ref.ID, attachments); err != nil {
+		return nil, respondLinkError(c, err)
+	}
+	return version, nil
+}
+
+// AddAttachment godoc
+// @Summary      Tambah satu lampiran prestasi
+// @Description  Melampirkan file hasil /achievements/upload (milik sendiri) ke prestasi berstatus draft atau needs_revision
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.AttachmentInput true "fileId hasil upload"
+// @Success      201  {object}  map[string]interface{} "Lampiran ditambahkan"
+// @Failure      400  {object}  map[string]interface{} "fileId tidak valid, bukan milik sendiri, atau status tidak bisa diedit"
+// @Failure      403  {object}  map[string]interface{} "Bukan prestasi milik sendiri"
+// @Failure      409  {object}  map[string]interface{} "File sudah terlampir di prestasi ini"
+// @Router       /achievements/{id}/attachments [post]
+func (s *AchievementService) AddAttachment(c *fiber.Ctx) error {
+	ref, err := s.ownedReference(c, "mengedit")
+	if ref == nil {
+		return err
+	}
+	if !CanEditAchievement(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi berstatus draft atau needs_revision yang bisa diedit. Tarik (withdraw) atau buka ulang (reopen) dulu"})
+	}
+
+	var req models.AttachmentInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	ctx := c.Context()
+	previous, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || previous == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+	for _, att := range previous.Attachments {
+		if att.FileID != "" && att.FileID == req.FileID {
+			return c.Status(409).JSON(fiber.Map{"error": "File sudah terlampir di prestasi ini"})
+		}
+	}
+
+	userUUID, _ := currentUserID(c)
+	added, err := s.resolveAttachments(c, userUUID, ref.ID, []models.AttachmentInput{req}, nil)
+	if added == nil {
+		return err
+	}
+	attachments := append(append([]models.Attachment{}, previous.Attachments...), added...)
+	version, err := s.saveAttachments(c, ref, previous, attachments)
+	if version == nil {
+		return err
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Lampiran berhasil ditambahkan",
+		"version": version.Version + 1,
+		"data":    added[0],
+	})
+}
+
+// RemoveAttachment godoc
+// @Summary      Hapus satu lampiran prestasi
+// @Description  Melepas lampiran dari prestasi berstatus draft atau needs_revision. File tetap disimpan karena masih dipakai versi lama prestasi
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        fileId path string true "ID file (UUID)"
+// @Success      200  {object}  map[string]interface{} "Lampiran dihapus"
+// @Failure      400  {object}  map[string]interface{} "Status tidak bisa diedit"
+// @Failure      403  {object}  map[string]interface{} "Bukan prestasi milik sendiri"
+// @Failure      404  {object}  map[string]interface{} "Lampiran tidak ditemukan"
+// @Router       /achievements/{id}/attachments/{fileId} [delete]
+func (s *AchievementService) RemoveAttachment(c *fiber.Ctx) error {
+	ref, err := s.ownedReference(c, "mengedit")
+	if ref == nil {
+		return err
+	}
+	if !CanEditAchievement(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi berstatus draft atau needs_revision yang bisa diedit. Tarik (withdraw) atau buka ulang (reopen) dulu"})
+	}
+
+	previous, err := s.achievementRepo.GetDetailByID(c.Context(), ref.MongoAchievementID)
+	if err != nil || previous == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+
+	fileID := c.Params("fileId")
+	attachments := make([]models.Attachment, 0, len(previous.Attachments))
+	for _, att := range previous.Attachments {
+		if att.FileID != fileID {
+			attachments = append(attachments, att)
+		}
+	}
+	if len(attachments) == len(previous.Attachments) {
+		return c.Status(404).JSON(fiber.Map{"error": "Lampiran tidak ditemukan pada prestasi ini"})
+	}
+
+	version, err := s.saveAttachments(c, ref, previous, attachments)
+	if version == nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"message": "Lampiran berhasil dihapus",
+		"version": version.Version + 1,
+	})
+}
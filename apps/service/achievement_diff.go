@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+)
+
+// DiffAchievementDetails membandingkan isi dua snapshot prestasi per field.
+// Field di dalam details diratakan memakai titik (details.rank), urutan hasil stabil berdasarkan nama field.
+func DiffAchievementDetails(before, after *models.AchievementDetail) []models.FieldChange {
+	a := flattenDetail(before)
+	b := flattenDetail(after)
+
+	fields := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		fields[k] = true
+	}
+	for k := range b {
+		fields[k] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	changes := []models.FieldChange{}
+	for _, name := range names {
+		oldValue, inOld := a[name]
+		newValue, inNew := b[name]
+		switch {
+		case inOld && !inNew:
+			changes = append(changes, models.FieldChange{Field: name, Change: "removed", Before: oldValue})
+		case !inOld && inNew:
+			changes = append(changes, models.FieldChange{Field: name, Change: "added", After: newValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, models.FieldChange{Field: name, Change: "changed", Before: oldValue, After: newValue})
+		}
+	}
+	return changes
+}
+
+// flattenDetail mengubah snapshot jadi map field -> nilai. Nilai dilewatkan JSON dulu supaya
+// tipe dari MongoDB (int32, primitive.A, dst) dan dari request sebanding.
+func flattenDetail(detail *models.AchievementDetail) map[string]interface{} {
+	result := make(map[string]interface{})
+	if detail == nil {
+		return result
+	}
+
+	top := map[string]interface{}{
+		"achievement_type": detail.AchievementType,
+		"title":            detail.Title,
+		"description":      detail.Description,
+		"tags":             detail.Tags,
+		"attachments":      detail.Attachments,
+	}
+	for k, v := range top {
+		if normalized := normalizeJSON(v); !isEmptyValue(normalized) {
+			result[k] = normalized
+		}
+	}
+
+	if details, ok := normalizeJSON(detail.Details).(map[string]interface{}); ok {
+		flattenInto(result, "details", details)
+	}
+	return result
+}
+
+func flattenInto(result map[string]interface{}, prefix string, values map[string]interface{}) {
+	for k, v := range values {
+		key := prefix + "." + k
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenInto(result, key, nested)
+			continue
+		}
+		if !isEmptyValue(v) {
+			result[key] = v
+		}
+	}
+}
+
+func normalizeJSON(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// isEmptyValue - string kosong, null dan list kosong dianggap field tidak diisi
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case []interface{}:
+		return len(val) == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	}
+	return false
+}
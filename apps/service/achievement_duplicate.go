@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// DuplicateThreshold skor minimal supaya prestasi lain dianggap kemungkinan duplikat.
+// Judul identik + tipe sama sudah cukup (0.7), judul yang hanya mirip perlu tanggal / penyelenggara yang sama
+const DuplicateThreshold = 0.65
+
+// bobot setiap sinyal, lampiran identik langsung dianggap duplikat
+const (
+	duplicateWeightTitle     = 0.6
+	duplicateWeightType      = 0.1
+	duplicateWeightDate      = 0.2
+	duplicateWeightOrganizer = 0.1
+)
+
+// duplicateOrganizerFields field details yang berisi penyelenggara / penerbit, dipakai yang pertama terisi
+var duplicateOrganizerFields = []string{"organizer", "issuedBy", "publisher", "organizationName"}
+
+// NormalizeTitle huruf kecil, tanda baca jadi spasi, spasi ganda dirapikan: "Juara 1 - GEMASTIK!" -> "juara 1 gemastik"
+func NormalizeTitle(title string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, title)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+// TitleSimilarity koefisien Dice bigram huruf dari judul yang sudah dinormalisasi, 0 (beda) sampai 1 (sama).
+// Tahan terhadap salah ketik dan urutan kata yang sedikit berbeda
+func TitleSimilarity(a, b string) float64 {
+	a, b = NormalizeTitle(a), NormalizeTitle(b)
+	if a == b {
+		return 1
+	}
+	bigramsA, bigramsB := titleBigrams(a), titleBigrams(b)
+	if len(bigramsA) == 0 || len(bigramsB) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(bigramsA))
+	for _, bg := range bigramsA {
+		counts[bg]++
+	}
+	shared := 0
+	for _, bg := range bigramsB {
+		if counts[bg] > 0 {
+			counts[bg]--
+			shared++
+		}
+	}
+	return float64(2*shared) / float64(len(bigramsA)+len(bigramsB))
+}
+
+func titleBigrams(s string) []string {
+	runes := []rune(s)
+	var bigrams []string
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams = append(bigrams, string(runes[i:i+2]))
+	}
+	return bigrams
+}
+
+// eventDateKey tanggal kegiatan dalam bentuk YYYY-MM-DD, atau rentang period. Kosong kalau tidak ada
+func eventDateKey(details map[string]interface{}) string {
+	if t, ok := parseDetailDate(details["eventDate"]); ok {
+		return t.Format("2006-01-02")
+	}
+	if period, ok := details["period"].(map[string]interface{}); ok {
+		start, okStart := parseDetailDate(period["start"])
+		end, okEnd := parseDetailDate(period["end"])
+		if okStart && okEnd {
+			return start.Format("2006-01-02") + "/" + end.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+func organizerKey(details map[string]interface{}) string {
+	for _, field := range duplicateOrganizerFields {
+		if value, ok := details[field].(string); ok && NormalizeTitle(value) != "" {
+			return NormalizeTitle(value)
+		}
+	}
+	return ""
+}
+
+// CompareAchievements menghitung skor kemiripan dua prestasi beserta alasannya
+func CompareAchievements(a, b *models.AchievementDetail) (float64, []string) {
+	reasons := []string{}
+
+	hashes := make(map[string]bool)
+	for _, att := range a.Attachments {
+		if att.Hash != "" {
+			hashes[att.Hash] = true
+		}
+	}
+	for _, att := range b.Attachments {
+		if hashes[att.Hash] {
+			return 1, append(reasons, "lampiran identik ("+att.FileName+")")
+		}
+	}
+
+	score := 0.0
+	if similarity := TitleSimilarity(a.Title, b.Title); similarity >= 0.5 {
+		score += similarity * duplicateWeightTitle
+		reasons = append(reasons, fmt.Sprintf("judul mirip (%d%%)", int(math.Round(similarity*100))))
+	}
+	if NormalizeAchievementType(a.AchievementType) == NormalizeAchievementType(b.AchievementType) {
+		score += duplicateWeightType
+		reasons = append(reasons, "tipe sama")
+	}
+
+	detailsA, _ := normalizeJSON(a.Details).(map[string]interface{})
+	detailsB, _ := normalizeJSON(b.Details).(map[string]interface{})
+	if date := eventDateKey(detailsA); date != "" && date == eventDateKey(detailsB) {
+		score += duplicateWeightDate
+		reasons = append(reasons, "tanggal kegiatan sama")
+	}
+	if organizer := organizerKey(detailsA); organizer != "" && organizer == organizerKey(detailsB) {
+		score += duplicateWeightOrganizer
+		reasons = append(reasons, "penyelenggara sama")
+	}
+
+	return math.Round(score*100) / 100, reasons
+}
+
+// DuplicateDetector mencari prestasi yang kemungkinan dilaporkan dua kali
+type DuplicateDetector struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewDuplicateDetector(achievementRepo repository.AchievementRepository) *DuplicateDetector {
+	return &DuplicateDetector{achievementRepo: achievementRepo}
+}
+
+// ForStudent membandingkan detail dengan prestasi lain milik mahasiswa yang sama
+func (d *DuplicateDetector) ForStudent(ctx context.Context, detail *models.AchievementDetail, studentID uuid.UUID, excludeID uuid.UUID) ([]models.DuplicateCandidate, error) {
+	refs, err := d.achievementRepo.GetAllByStudentID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return d.match(ctx, detail, refs, excludeID)
+}
+
+// AcrossStudents membandingkan detail dengan seluruh prestasi, misal dua anggota tim yang melaporkan lomba yang sama
+func (d *DuplicateDetector) AcrossStudents(ctx context.Context, detail *models.AchievementDetail, excludeID uuid.UUID) ([]models.DuplicateCandidate, error) {
+	refs, err := d.achievementRepo.GetAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return d.match(ctx, detail, refs, excludeID)
+}
+
+func (d *DuplicateDetector) match(ctx context.Context, detail *models.AchievementDetail, refs []models.AchievementReference, excludeID uuid.UUID) ([]models.DuplicateCandidate, error) {
+	byMongoID := make(map[string]models.AchievementReference, len(refs))
+	mongoIDs := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.ID == excludeID {
+			continue
+		}
+		byMongoID[ref.MongoAchievementID] = ref
+		mongoIDs = append(mongoIDs, ref.MongoAchievementID)
+	}
+
+	others, err := d.achievementRepo.GetDetailsByIDs(ctx, mongoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []models.DuplicateCandidate{}
+	for i := range others {
+		score, reasons := CompareAchievements(detail, &others[i])
+		if score < DuplicateThreshold {
+			continue
+		}
+		ref := byMongoID[others[i].ID.Hex()]
+		candidates = append(candidates, models.DuplicateCandidate{
+			AchievementID: ref.ID,
+			StudentID:     ref.StudentID,
+			Title:         others[i].Title,
+			Status:        NormalizeStatus(ref.Status),
+			Score:         score,
+			Reasons:       reasons,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates, nil
+}
+
+// withDuplicateWarning menambahkan peringatan ke respons Create / Submit. Deteksi duplikat hanya peringatan,
+// kegagalannya tidak membatalkan request
+func (s *AchievementService) withDuplicateWarning(ctx context.Context, response fiber.Map, detail *models.AchievementDetail, ref *models.AchievementReference) fiber.Map {
+	candidates, err := s.duplicates.ForStudent(ctx, detail, ref.StudentID, ref.ID)
+	if err != nil || len(candidates) == 0 {
+		return response
+	}
+	response["warning"] = "Prestasi ini mirip dengan prestasi lain yang sudah kamu laporkan, pastikan tidak dilaporkan dua kali"
+	response["possible_duplicates"] = candidates
+	return response
+}
+
+// GetPossibleDuplicates godoc
+// @Summary      Kemungkinan duplikat prestasi
+// @Description  Mencari prestasi lain (semua mahasiswa) yang mirip: judul, tipe, tanggal kegiatan, penyelenggara, atau lampiran identik. Untuk verifikator sebelum memverifikasi
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Daftar kemungkinan duplikat, skor tertinggi lebih dulu"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal mencari duplikat"
+// @Router       /achievements/{id}/possible-duplicates [get]
+func (s *AchievementService) GetPossibleDuplicates(c *fiber.Ctx) error {
+	achievUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	ctx := c.Context()
+	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
+	if err != nil || ref == nil || ref.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
+	}
+	detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || detail == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+
+	candidates, err := s.duplicates.AcrossStudents(ctx, detail, ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencari duplikat prestasi"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Kemungkinan duplikat prestasi",
+		"threshold": DuplicateThreshold,
+		"data":      candidates,
+	})
+}
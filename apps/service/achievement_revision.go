@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// revisableFields field prestasi yang boleh diberi komentar revisi, selain details.<nama>
+var revisableFields = map[string]bool{
+	"achievement_type": true,
+	"title":            true,
+	"description":      true,
+	"tags":             true,
+	"attachments":      true,
+}
+
+func isRevisableField(field string) bool {
+	return revisableFields[field] || (strings.HasPrefix(field, "details.") && len(field) > len("details."))
+}
+
+// revisionLimitReached - sudah berapa kali dosen meminta revisi dibanding batas di AchievementOptions
+func (s *AchievementService) revisionLimitReached(revisions []models.AchievementRevision) bool {
+	if s.options.MaxResubmissions <= 0 {
+		return false
+	}
+	requested := 0
+	for _, revision := range revisions {
+		if revision.Review != nil && revision.Review.Status == models.AchievementStatusNeedsRevision {
+			requested++
+		}
+	}
+	return requested >= s.options.MaxResubmissions
+}
+
+// snapshotRevision menyimpan isi prestasi saat disubmit sebagai ronde berikutnya
+func (s *AchievementService) snapshotRevision(ctx context.Context, ref *models.AchievementReference, detail *models.AchievementDetail, submittedBy uuid.UUID) (*models.AchievementRevision, error) {
+	existing, err := s.revisionRepo.GetByAchievementID(ctx, ref.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	round := 1
+	if len(existing) > 0 {
+		round = existing[len(existing)-1].Round + 1
+	}
+
+	revision := &models.AchievementRevision{
+		AchievementID: ref.ID.String(),
+		Round:         round,
+		Detail:        *detail,
+		SubmittedBy:   submittedBy.String(),
+		SubmittedAt:   time.Now(),
+	}
+	if err := s.revisionRepo.Create(ctx, revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// GetRevisions godoc
+// @Summary      Daftar ronde revisi prestasi
+// @Description  Mengambil snapshot isi prestasi setiap kali disubmit beserta review dosen (status, catatan, komentar per field). Mahasiswa hanya bisa melihat prestasi miliknya
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Daftar ronde revisi"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Router       /achievements/{id}/revisions [get]
+func (s *AchievementService) GetRevisions(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	revisions, err := s.revisionRepo.GetByAchievementID(c.Context(), ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat revisi"})
+	}
+	if revisions == nil {
+		revisions = []models.AchievementRevision{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Riwayat revisi prestasi",
+		"data": fiber.Map{
+			"status":            ref.Status,
+			"max_resubmissions": s.options.MaxResubmissions,
+			"revisions":         revisions,
+		},
+	})
+}
+
+// DiffRevisions godoc
+// @Summary      Bandingkan dua ronde revisi
+// @Description  Menampilkan field yang berubah antara dua ronde submit. Default membandingkan dua ronde terakhir. Review ronde asal ikut dikembalikan supaya komentar dosen bisa dicocokkan dengan perubahannya
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        from query int false "Ronde asal"
+// @Param        to query int false "Ronde tujuan"
+// @Success      200  {object}  map[string]interface{} "Daftar perubahan per field"
+// @Failure      400  {object}  map[string]interface{} "Ronde tidak valid atau belum ada revisi"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi atau ronde tidak ditemukan"
+// @Router       /achievements/{id}/revisions/diff [get]
+func (s *AchievementService) DiffRevisions(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	revisions, err := s.revisionRepo.GetByAchievementID(c.Context(), ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat revisi"})
+	}
+	if len(revisions) < 2 && (c.Query("from") == "" || c.Query("to") == "") {
+		return c.Status(400).JSON(fiber.Map{"error": "Belum ada revisi untuk dibandingkan"})
+	}
+
+	to := 0
+	if len(revisions) > 0 {
+		to = revisions[len(revisions)-1].Round
+	}
+	to, err1 := strconv.Atoi(c.Query("to", strconv.Itoa(to)))
+	from, err2 := strconv.Atoi(c.Query("from", strconv.Itoa(to-1)))
+	if err1 != nil || err2 != nil || from == to {
+		return c.Status(400).JSON(fiber.Map{"error": "Parameter from / to harus nomor ronde yang berbeda"})
+	}
+
+	var before, after *models.AchievementRevision
+	for i := range revisions {
+		switch revisions[i].Round {
+		case from:
+			before = &revisions[i]
+		case to:
+			after = &revisions[i]
+		}
+	}
+	if before == nil || after == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Ronde revisi tidak ditemukan"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Perbandingan ronde revisi",
+		"data": fiber.Map{
+			"from":    from,
+			"to":      to,
+			"review":  before.Review,
+			"changes": DiffAchievementDetails(&before.Detail, &after.Detail),
+		},
+	})
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+)
+
+// commonAchievementFields field details yang boleh ada di semua tipe prestasi
+var commonAchievementFields = []models.AchievementFieldDefinition{
+	{Name: "eventDate", DataType: models.FieldTypeDate},
+	{Name: "location", DataType: models.FieldTypeString},
+	{Name: "organizer", DataType: models.FieldTypeString},
+	{Name: "score", DataType: models.FieldTypeNumber},
+	{Name: "customFields", DataType: models.FieldTypeObject},
+}
+
+// DefaultAchievementTypes skema bawaan sesuai struktur details di readme, ditanam ke achievement_types oleh SeedAchievementTypes
+func DefaultAchievementTypes() []models.AchievementTypeDefinition {
+	return []models.AchievementTypeDefinition{
+		{Key: "academic", Label: "Akademik", DefaultPoints: 10, IsActive: true},
+		{Key: "competition", Label: "Kompetisi", DefaultPoints: 20, IsActive: true, Fields: []models.AchievementFieldDefinition{
+			{Name: "competitionName", DataType: models.FieldTypeString, Required: true},
+			{Name: "competitionLevel", DataType: models.FieldTypeString, Required: true, Options: []string{"international", "national", "regional", "local"}},
+			{Name: "rank", DataType: models.FieldTypeInteger},
+			{Name: "medalType", DataType: models.FieldTypeString},
+		}},
+		{Key: "publication", Label: "Publikasi", DefaultPoints: 25, IsActive: true, Fields: []models.AchievementFieldDefinition{
+			{Name: "publicationType", DataType: models.FieldTypeString, Required: true, Options: []string{"journal", "conference", "book"}},
+			{Name: "publicationTitle", DataType: models.FieldTypeString, Required: true},
+			{Name: "authors", DataType: models.FieldTypeStringList, Required: true},
+			{Name: "publisher", DataType: models.FieldTypeString},
+			{Name: "issn", DataType: models.FieldTypeISSN},
+		}},
+		{Key: "organization", Label: "Organisasi", DefaultPoints: 15, IsActive: true, Fields: []models.AchievementFieldDefinition{
+			{Name: "organizationName", DataType: models.FieldTypeString, Required: true},
+			{Name: "position", DataType: models.FieldTypeString, Required: true},
+			{Name: "period", DataType: models.FieldTypePeriod, Required: true},
+		}},
+		{Key: "certification", Label: "Sertifikasi", DefaultPoints: 15, IsActive: true, Fields: []models.AchievementFieldDefinition{
+			{Name: "certificationName", DataType: models.FieldTypeString, Required: true},
+			{Name: "issuedBy", DataType: models.FieldTypeString, Required: true},
+			{Name: "certificationNumber", DataType: models.FieldTypeString, Required: true},
+			{Name: "validUntil", DataType: models.FieldTypeDate},
+		}},
+		{Key: "other", Label: "Lainnya", DefaultPoints: 5, IsActive: true},
+	}
+}
+
+// SeedAchievementTypes menambahkan tipe bawaan yang belum ada. Tipe yang sudah ada tidak disentuh
+// supaya perubahan admin (label, field, poin) tidak tertimpa setiap start.
+func SeedAchievementTypes(ctx context.Context, typeRepo repository.AchievementTypeRepository) ([]string, error) {
+	var created []string
+	for _, t := range DefaultAchievementTypes() {
+		existing, err := typeRepo.GetByKey(ctx, t.Key)
+		if err != nil {
+			return created, err
+		}
+		if existing != nil {
+			continue
+		}
+		if err := typeRepo.Create(ctx, &t); err != nil {
+			return created, fmt.Errorf("seed tipe %s: %w", t.Key, err)
+		}
+		created = append(created, t.Key)
+	}
+	return created, nil
+}
+
+// AchievementValidator memeriksa title, achievement_type dan details prestasi terhadap skema di achievement_types
+type AchievementValidator struct {
+	typeRepo repository.AchievementTypeRepository
+}
+
+func NewAchievementValidator(typeRepo repository.AchievementTypeRepository) *AchievementValidator {
+	return &AchievementValidator{typeRepo: typeRepo}
+}
+
+// Validate mengembalikan daftar kesalahan per field, kosong berarti valid. error hanya untuk kegagalan baca registry
+func (v *AchievementValidator) Validate(ctx context.Context, detail *models.AchievementDetail) ([]models.FieldError, error) {
+	var errs []models.FieldError
+	if strings.TrimSpace(detail.Title) == "" {
+		errs = append(errs, models.FieldError{Field: "title", Message: "wajib diisi"})
+	}
+
+	typeDef, err := v.typeRepo.GetByKey(ctx, NormalizeAchievementType(detail.AchievementType))
+	if err != nil {
+		return nil, err
+	}
+	if typeDef == nil || !typeDef.IsActive {
+		active, err := v.typeRepo.GetAll(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(active))
+		for _, t := range active {
+			keys = append(keys, t.Key)
+		}
+		return append(errs, models.FieldError{Field: "achievement_type", Message: "harus salah satu dari: " + strings.Join(keys, ", ")}), nil
+	}
+
+	return append(errs, validateDetails(*typeDef, detail.Details)...), nil
+}
+
+func NormalizeAchievementType(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func validateDetails(typeDef models.AchievementTypeDefinition, details map[string]interface{}) []models.FieldError {
+	var errs []models.FieldError
+	values, _ := normalizeJSON(details).(map[string]interface{})
+
+	known := make(map[string]bool)
+	for _, field := range append(append([]models.AchievementFieldDefinition{}, typeDef.Fields...), commonAchievementFields...) {
+		known[field.Name] = true
+		value, present := values[field.Name]
+		if !present || isEmptyValue(value) {
+			if field.Required {
+				errs = append(errs, models.FieldError{Field: "details." + field.Name, Message: "wajib diisi"})
+			}
+			continue
+		}
+		if msg := checkFieldValue(field, value); msg != "" {
+			errs = append(errs, models.FieldError{Field: "details." + field.Name, Message: msg})
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, models.FieldError{Field: "details." + name, Message: "field tidak dikenal untuk tipe " + typeDef.Key + ", gunakan customFields"})
+	}
+	return errs
+}
+
+// checkFieldValue - value sudah melewati normalizeJSON, jadi angka selalu float64
+func checkFieldValue(field models.AchievementFieldDefinition, value interface{}) string {
+	switch field.DataType {
+	case models.FieldTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return "harus berupa teks"
+		}
+		if len(field.Options) > 0 && !containsString(field.Options, s) {
+			return "harus salah satu dari: " + strings.Join(field.Options, ", ")
+		}
+	case models.FieldTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return "harus berupa angka"
+		}
+	case models.FieldTypeInteger:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return "harus bilangan bulat"
+		}
+		if n < 1 {
+			return "minimal 1"
+		}
+	case models.FieldTypeDate:
+		if _, ok := parseDetailDate(value); !ok {
+			return "format tanggal harus YYYY-MM-DD"
+		}
+	case models.FieldTypeStringList:
+		list, ok := value.([]interface{})
+		if !ok {
+			return "harus berupa daftar teks"
+		}
+		for _, item := range list {
+			if s, ok := item.(string); !ok || strings.TrimSpace(s) == "" {
+				return "harus berupa daftar teks yang tidak kosong"
+			}
+		}
+	case models.FieldTypePeriod:
+		period, ok := value.(map[string]interface{})
+		if !ok {
+			return "harus berupa {start, end}"
+		}
+		start, okStart := parseDetailDate(period["start"])
+		end, okEnd := parseDetailDate(period["end"])
+		if !okStart || !okEnd {
+			return "start dan end wajib tanggal YYYY-MM-DD"
+		}
+		if end.Before(start) {
+			return "end tidak boleh sebelum start"
+		}
+	case models.FieldTypeISSN:
+		s, ok := value.(string)
+		if !ok || !ValidISSN(s) {
+			return "ISSN tidak valid (format NNNN-NNNC dengan check digit)"
+		}
+	case models.FieldTypeObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "harus berupa object"
+		}
+	default:
+		return fmt.Sprintf("tipe data %q tidak dikenal", field.DataType)
+	}
+	return ""
+}
+
+func parseDetailDate(value interface{}) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// ValidISSN memeriksa format dan check digit ISSN (ISO 3297), tanda hubung opsional
+func ValidISSN(issn string) bool {
+	issn = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(issn), "-", ""))
+	if len(issn) != 8 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		if issn[i] < '0' || issn[i] > '9' {
+			return false
+		}
+		sum += int(issn[i]-'0') * (8 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return issn[7] == 'X'
+	}
+	return int(issn[7]-'0') == check
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecomputeScores godoc
+// @Summary      Hitung ulang poin prestasi
+// @Description  Menghitung ulang poin semua prestasi verified memakai aturan scoring yang sedang aktif, misal setelah SCORING_RULES_FILE diubah. Selisih poin dicatat sebagai adjustment di point_ledger. dry_run=true hanya melaporkan perubahan
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run query bool false "Hanya hitung tanpa menyimpan"
+// @Success      200  {object}  map[string]interface{} "Ringkasan hitung ulang"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data prestasi"
+// @Router       /achievements/scores/recompute [post]
+func (s *AchievementService) RecomputeScores(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run")
+	ctx := c.Context()
+	actorID, _ := currentUserID(c)
+
+	refs, err := s.achievementRepo.GetAll(ctx, models.AchievementStatusVerified)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data prestasi"})
+	}
+
+	changed := []fiber.Map{}
+	failed := []fiber.Map{}
+	for _, ref := range refs {
+		detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+		if err != nil || detail == nil {
+			failed = append(failed, fiber.Map{"id": ref.ID, "error": "detail MongoDB tidak ditemukan"})
+			continue
+		}
+
+		score, err := s.scorer.Score(ctx, detail)
+		if err != nil {
+			failed = append(failed, fiber.Map{"id": ref.ID, "error": err.Error()})
+			continue
+		}
+		unchanged := detail.ScoreBreakdown != nil && detail.Points == score.Total && detail.ScoreBreakdown.RulesVersion == score.RulesVersion
+
+		if !dryRun {
+			if !unchanged {
+				if err := s.achievementRepo.UpdateScore(ctx, ref.MongoAchievementID, score); err != nil {
+					failed = append(failed, fiber.Map{"id": ref.ID, "error": err.Error()})
+					continue
+				}
+			}
+			// ledger tetap disamakan walau poin tidak berubah, untuk prestasi yang diverifikasi sebelum ada ledger
+			allocations, err := s.team.Allocate(ctx, &ref, score.Total)
+			if err == nil {
+				err = s.ledger.Award(ctx, &ref, detail, score, allocations, actorID)
+			}
+			if err != nil {
+				failed = append(failed, fiber.Map{"id": ref.ID, "error": err.Error()})
+				continue
+			}
+		}
+		if unchanged {
+			continue
+		}
+		changed = append(changed, fiber.Map{"id": ref.ID, "before": detail.Points, "after": score.Total})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Hitung ulang poin selesai",
+		"data": fiber.Map{
+			"rules_version": s.scorer.RulesVersion(),
+			"dry_run":       dryRun,
+			"processed":     len(refs),
+			"changed":       changed,
+			"failed":        failed,
+		},
+	})
+}
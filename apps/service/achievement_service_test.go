@@ -0,0 +1,1124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/antivirus"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// achievementFixture - mahasiswa dengan dosen wali, satu dosen lain, dan repo prestasi kosong
+type achievementFixture struct {
+	achievementRepo *mocks.ManualMockAchievementRepo
+	studentRepo     *mocks.ManualMockStudentRepo
+	lectureRepo     *mocks.ManualMockLectureRepo
+	delegationRepo  *mocks.ManualMockDelegationRepo
+	typeRepo        *mocks.ManualMockAchievementTypeRepo
+	revisionRepo    *mocks.ManualMockAchievementRevisionRepo
+	versionRepo     *mocks.ManualMockAchievementVersionRepo
+	ledgerRepo      *mocks.ManualMockPointLedgerRepo
+	memberRepo      *mocks.ManualMockAchievementMemberRepo
+	fileRepo        *mocks.ManualMockFileRepo
+	files           *service.FileStorage
+	service         *service.AchievementService
+	student         *models.Students
+	advisor         *models.Lecture
+	otherLecturer   *models.Lecture
+}
+
+func newAchievementFixture() *achievementFixture {
+	f := &achievementFixture{
+		achievementRepo: mocks.NewManualMockAchievementRepo(),
+		studentRepo:     mocks.NewManualMockStudentRepo(),
+		lectureRepo:     mocks.NewManualMockLectureRepo(),
+		delegationRepo:  mocks.NewManualMockDelegationRepo(),
+		typeRepo:        mocks.NewManualMockAchievementTypeRepo(),
+		revisionRepo:    mocks.NewManualMockAchievementRevisionRepo(),
+		versionRepo:     mocks.NewManualMockAchievementVersionRepo(),
+		ledgerRepo:      mocks.NewManualMockPointLedgerRepo(),
+		memberRepo:      mocks.NewManualMockAchievementMemberRepo(),
+		fileRepo:        mocks.NewManualMockFileRepo(),
+	}
+
+	f.advisor = &models.Lecture{UserID: uuid.New(), LecturerID: "NIDN-001"}
+	f.otherLecturer = &models.Lecture{UserID: uuid.New(), LecturerID: "NIDN-002"}
+	f.lectureRepo.Create(context.Background(), f.advisor)
+	f.lectureRepo.Create(context.Background(), f.otherLecturer)
+
+	f.student = &models.Students{UserID: uuid.New(), StudentID: "NIM-001", AcademicYear: "2024", AdvisorID: &f.advisor.ID}
+	f.studentRepo.Create(context.Background(), f.student)
+
+	service.SeedAchievementTypes(context.Background(), f.typeRepo)
+	return f.withFiles(antivirus.NewNopScanner(), service.FileStorageOptions{})
+}
+
+// withFiles membangun ulang service dengan FileStorage lain (pemindai virus, batas ukuran, kuota)
+func (f *achievementFixture) withFiles(scanner antivirus.Scanner, options service.FileStorageOptions) *achievementFixture {
+	options.BaseURL = "http://api.test/api/v1"
+	options.SigningKey = []byte("rahasia-file")
+	f.files = service.NewFileStorage(storage.NewLocalStore(filepath.Join(os.TempDir(), "prestasi-uploads-test")), f.fileRepo, scanner, options)
+	f.service = service.NewAchievementService(f.achievementRepo, f.studentRepo, service.NewVerificationAuthority(f.lectureRepo, f.delegationRepo), service.NewAchievementValidator(f.typeRepo), service.NewScoringEngine(service.DefaultScoringRules(), f.typeRepo), service.NewPointLedger(f.ledgerRepo), f.revisionRepo, f.versionRepo, f.memberRepo, f.files, service.AchievementOptions{MaxResubmissions: 2})
+	return f
+}
+
+// appAs - app yang menjalankan handler sebagai userID
+func appAs(userID uuid.UUID, path string, method string, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Add(method, path, func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID.String())
+		return c.Next()
+	}, handler)
+	return app
+}
+
+func TestVerify_AdvisorScope(t *testing.T) {
+	f := newAchievementFixture()
+
+	tests := []struct {
+		name           string
+		status         string
+		verifier       uuid.UUID
+		body           models.VerifyAchievementRequest
+		expectedStatus int
+	}{
+		{"Dosen Wali Verifikasi", "submitted", f.advisor.UserID, models.VerifyAchievementRequest{Status: "verified"}, 200},
+		{"Dosen Wali Tolak Dengan Alasan", "submitted", f.advisor.UserID, models.VerifyAchievementRequest{Status: "rejected", Notes: "Sertifikat buram"}, 200},
+		{"Dosen Lain Ditolak", "submitted", f.otherLecturer.UserID, models.VerifyAchievementRequest{Status: "verified"}, 403},
+		{"Bukan Dosen", "submitted", f.student.UserID, models.VerifyAchievementRequest{Status: "verified"}, 403},
+		{"Masih Draft", "draft", f.advisor.UserID, models.VerifyAchievementRequest{Status: "verified"}, 400},
+		{"Sudah Verified", "verified", f.advisor.UserID, models.VerifyAchievementRequest{Status: "rejected", Notes: "ulang"}, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := f.achievementRepo.AddAchievement(f.student.ID, tt.status, nil)
+			app := appAs(tt.verifier, "/achievements/:id/verify", "PATCH", f.service.Verify)
+
+			status, body := decodeBody(t, app, "PATCH", "/achievements/"+ref.ID.String()+"/verify", tt.body)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+}
+
+func TestVerifyRevoke_SoftDeleted(t *testing.T) {
+	f := newAchievementFixture()
+	submitted := f.achievementRepo.AddAchievement(f.student.ID, "submitted", nil)
+	verified := f.achievementRepo.AddAchievement(f.student.ID, "verified", nil)
+	f.achievementRepo.SoftDelete(context.Background(), submitted.ID)
+	f.achievementRepo.SoftDelete(context.Background(), verified.ID)
+
+	verify := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	if status, body := decodeBody(t, verify, "PATCH", "/achievements/"+submitted.ID.String()+"/verify", models.VerifyAchievementRequest{Status: "verified"}); status != 404 {
+		t.Errorf("Verify prestasi terhapus harus 404, got %d (%v)", status, body)
+	}
+	revoke := appAs(f.advisor.UserID, "/achievements/:id/revoke", "PATCH", f.service.Revoke)
+	if status, body := decodeBody(t, revoke, "PATCH", "/achievements/"+verified.ID.String()+"/revoke", models.AchievementTransitionRequest{Note: "Sertifikat palsu"}); status != 404 {
+		t.Errorf("Revoke prestasi terhapus harus 404, got %d (%v)", status, body)
+	}
+}
+
+func TestVerify_Delegation(t *testing.T) {
+	f := newAchievementFixture()
+	ctx := context.Background()
+
+	// delegasi yang sudah berakhir tidak berlaku
+	f.delegationRepo.Create(ctx, &models.VerificationDelegation{
+		AdvisorID:  f.advisor.ID,
+		DelegateID: f.otherLecturer.ID,
+		StartsAt:   time.Now().AddDate(0, 0, -10),
+		EndsAt:     time.Now().AddDate(0, 0, -3),
+	})
+
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", nil)
+	path := "/achievements/" + ref.ID.String() + "/verify"
+	app := appAs(f.otherLecturer.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+
+	if status, _ := decodeBody(t, app, "PATCH", path, models.VerifyAchievementRequest{Status: "verified"}); status != 403 {
+		t.Fatalf("Delegasi kadaluwarsa harus ditolak, got %d", status)
+	}
+
+	// advisor membuat delegasi aktif lewat endpoint
+	delegationService := service.NewDelegationService(f.lectureRepo, f.delegationRepo)
+	delegationApp := appAs(f.advisor.UserID, "/delegations", "POST", delegationService.Create)
+	status, body := decodeBody(t, delegationApp, "POST", "/delegations", models.CreateDelegationRequest{
+		DelegateID: f.otherLecturer.ID.String(),
+		StartDate:  time.Now().Format("2006-01-02"),
+		EndDate:    time.Now().AddDate(0, 0, 7).Format("2006-01-02"),
+		Reason:     "Cuti konferensi",
+	})
+	if status != 201 {
+		t.Fatalf("Gagal membuat delegasi: %d %v", status, body)
+	}
+
+	status, body = decodeBody(t, app, "PATCH", path, models.VerifyAchievementRequest{Status: "verified"})
+	if status != 200 {
+		t.Fatalf("Dosen penerima delegasi harus bisa verifikasi, got %d %v", status, body)
+	}
+	if body["delegation_id"] == nil {
+		t.Error("Respons harus menyertakan delegation_id")
+	}
+
+	stored, _ := f.achievementRepo.GetReferenceByID(ctx, ref.ID)
+	if stored.Status != "verified" || stored.VerifiedBy == nil || *stored.VerifiedBy != f.otherLecturer.UserID {
+		t.Errorf("Status/verified_by tidak tersimpan: %+v", stored)
+	}
+}
+
+func TestCreateDelegation_Validation(t *testing.T) {
+	f := newAchievementFixture()
+	delegationService := service.NewDelegationService(f.lectureRepo, f.delegationRepo)
+	app := appAs(f.advisor.UserID, "/delegations", "POST", delegationService.Create)
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name           string
+		body           models.CreateDelegationRequest
+		expectedStatus int
+	}{
+		{"Ke Diri Sendiri", models.CreateDelegationRequest{DelegateID: f.advisor.ID.String(), StartDate: today, EndDate: today}, 400},
+		{"Tanggal Terbalik", models.CreateDelegationRequest{DelegateID: f.otherLecturer.ID.String(), StartDate: "2030-01-10", EndDate: "2030-01-01"}, 400},
+		{"Sudah Lewat", models.CreateDelegationRequest{DelegateID: f.otherLecturer.ID.String(), StartDate: "2020-01-01", EndDate: "2020-01-05"}, 400},
+		{"Format Tanggal Salah", models.CreateDelegationRequest{DelegateID: f.otherLecturer.ID.String(), StartDate: "10/01/2030", EndDate: "2030-01-12"}, 400},
+		{"Dosen Penerima Tidak Ada", models.CreateDelegationRequest{DelegateID: uuid.New().String(), StartDate: today, EndDate: today}, 404},
+		{"Hari Ini Saja", models.CreateDelegationRequest{DelegateID: f.otherLecturer.ID.String(), StartDate: today, EndDate: today}, 201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, app, "POST", "/delegations", tt.body)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+}
+
+func TestAchievementWorkflow_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{"draft", "submitted", true},
+		{"Draft", "submitted", true},
+		{"submitted", "verified", true},
+		{"submitted", "rejected", true},
+		{"submitted", "draft", true},
+		{"rejected", "draft", true},
+		{"submitted", "needs_revision", true},
+		{"needs_revision", "submitted", true},
+		{"draft", "verified", false},
+		{"needs_revision", "verified", false},
+		{"rejected", "submitted", false},
+		{"verified", "draft", false},
+		{"verified", "rejected", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if got := service.CanTransition(tt.from, tt.to); got != tt.allowed {
+				t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestAchievementLifecycle_History(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "Draft", nil)
+	base := "/achievements/" + ref.ID.String()
+
+	studentApp := fiber.New()
+	studentApp.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", f.student.UserID.String())
+		return c.Next()
+	})
+	studentApp.Patch("/achievements/:id/submit", f.service.Submit)
+	studentApp.Patch("/achievements/:id/withdraw", f.service.Withdraw)
+	studentApp.Patch("/achievements/:id/reopen", f.service.Reopen)
+	studentApp.Put("/achievements/:id", f.service.Update)
+	studentApp.Get("/achievements/:id/history", f.service.GetHistory)
+	advisorApp := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+
+	steps := []struct {
+		name           string
+		app            *fiber.App
+		method, path   string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Submit Draft", studentApp, "PATCH", base + "/submit", nil, 200},
+		{"Edit Saat Submitted Ditolak", studentApp, "PUT", base, models.CreateAchievementRequest{Title: "Ubah"}, 400},
+		{"Withdraw", studentApp, "PATCH", base + "/withdraw", models.AchievementTransitionRequest{Note: "Lupa lampiran"}, 200},
+		{"Reopen Draft Ditolak", studentApp, "PATCH", base + "/reopen", nil, 400},
+		{"Submit Ulang", studentApp, "PATCH", base + "/submit", nil, 200},
+		{"Dosen Menolak", advisorApp, "PATCH", base + "/verify", models.VerifyAchievementRequest{Status: "Rejected", Notes: "Tanggal salah"}, 200},
+		{"Submit Langsung Dari Rejected Ditolak", studentApp, "PATCH", base + "/submit", nil, 400},
+		{"Reopen", studentApp, "PATCH", base + "/reopen", nil, 200},
+	}
+	for _, step := range steps {
+		status, body := decodeBody(t, step.app, step.method, step.path, step.body)
+		if status != step.expectedStatus {
+			t.Fatalf("%s: expected %d, got %d (%v)", step.name, step.expectedStatus, status, body)
+		}
+	}
+
+	history, _ := f.achievementRepo.GetStatusHistory(context.Background(), ref.ID)
+	want := []string{"draft>submitted", "submitted>draft", "draft>submitted", "submitted>rejected", "rejected>draft"}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d riwayat, got %d", len(want), len(history))
+	}
+	for i, h := range history {
+		if got := h.FromStatus + ">" + h.ToStatus; got != want[i] {
+			t.Errorf("Riwayat ke-%d: expected %s, got %s", i, want[i], got)
+		}
+	}
+	if history[1].Note != "Lupa lampiran" || *history[3].ActorID != f.advisor.UserID {
+		t.Errorf("Catatan/aktor tidak tercatat: %+v", history)
+	}
+
+	stored, _ := f.achievementRepo.GetReferenceByID(context.Background(), ref.ID)
+	if stored.Status != "draft" || stored.RejectionNote == nil {
+		t.Errorf("Setelah reopen status harus draft dan catatan penolakan tetap ada: %+v", stored)
+	}
+
+	status, body := decodeBody(t, studentApp, "GET", base+"/history", nil)
+	if status != 200 {
+		t.Fatalf("GET history gagal: %d %v", status, body)
+	}
+}
+
+func TestGetAll_StatusFilterIgnoresCase(t *testing.T) {
+	f := newAchievementFixture()
+	f.achievementRepo.AddAchievement(f.student.ID, "Submitted", nil)
+	f.achievementRepo.AddAchievement(f.student.ID, "submitted", nil)
+	f.achievementRepo.AddAchievement(f.student.ID, "draft", nil)
+
+	app := appAs(f.advisor.UserID, "/achievements", "GET", f.service.GetAll)
+	status, body := decodeBody(t, app, "GET", "/achievements?status=SUBMITTED", nil)
+	if status != 200 || len(body["data"].([]interface{})) != 2 {
+		t.Errorf("Filter status harus mengabaikan huruf besar, got %d %v", status, body)
+	}
+}
+
+func TestGetByID_OwnerOnly(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "Draft", nil)
+	other := &models.Students{UserID: uuid.New(), StudentID: "NIM-002", AcademicYear: "2024"}
+	f.studentRepo.Create(context.Background(), other)
+	deleted := f.achievementRepo.AddAchievement(f.student.ID, "Draft", nil)
+	f.achievementRepo.SoftDelete(context.Background(), deleted.ID)
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		id             string
+		expectedStatus int
+	}{
+		{"Pemilik", f.student.UserID, ref.ID.String(), 200},
+		{"Mahasiswa Lain", other.UserID, ref.ID.String(), 403},
+		{"ID Tidak Ada", f.student.UserID, uuid.New().String(), 404},
+		{"Sudah Dihapus", f.student.UserID, deleted.ID.String(), 404},
+		{"ID Tidak Valid", f.student.UserID, "bukan-uuid", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := appAs(tt.userID, "/achievements/:id", "GET", f.service.GetByID)
+			status, body := decodeBody(t, app, "GET", "/achievements/"+tt.id, nil)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+}
+
+func TestRevisionCycle_ResubmitAndDiff(t *testing.T) {
+	f := newAchievementFixture()
+	competition := map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "organizer": "Kemdikbud"}
+	withRank := func(rank int) map[string]interface{} {
+		details := map[string]interface{}{"rank": rank}
+		for k, v := range competition {
+			details[k] = v
+		}
+		return details
+	}
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "draft", &models.AchievementDetail{
+		Title:           "Juara Lomba",
+		AchievementType: "competition",
+		Details:         withRank(2),
+	})
+	base := "/achievements/" + ref.ID.String()
+
+	studentApp := fiber.New()
+	studentApp.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", f.student.UserID.String())
+		return c.Next()
+	})
+	studentApp.Patch("/achievements/:id/submit", f.service.Submit)
+	studentApp.Put("/achievements/:id", f.service.Update)
+	studentApp.Get("/achievements/:id/revisions", f.service.GetRevisions)
+	studentApp.Get("/achievements/:id/revisions/diff", f.service.DiffRevisions)
+	advisorApp := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+
+	revise := models.VerifyAchievementRequest{Status: "needs_revision", FieldComments: map[string]string{"details.rank": "Peringkat tidak sesuai sertifikat"}}
+	edit := models.CreateAchievementRequest{Title: "Juara Lomba", Type: "competition", Details: withRank(1)}
+
+	steps := []struct {
+		name           string
+		app            *fiber.App
+		method, path   string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Submit Ronde 1", studentApp, "PATCH", base + "/submit", nil, 200},
+		{"Revisi Tanpa Catatan Ditolak", advisorApp, "PATCH", base + "/verify", models.VerifyAchievementRequest{Status: "needs_revision"}, 400},
+		{"Komentar Field Tidak Dikenal", advisorApp, "PATCH", base + "/verify", models.VerifyAchievementRequest{Status: "needs_revision", FieldComments: map[string]string{"nilai": "x"}}, 400},
+		{"Minta Revisi 1", advisorApp, "PATCH", base + "/verify", revise, 200},
+		{"Edit Saat Needs Revision", studentApp, "PUT", base, edit, 200},
+		{"Submit Ronde 2", studentApp, "PATCH", base + "/submit", nil, 200},
+		{"Minta Revisi 2", advisorApp, "PATCH", base + "/verify", revise, 200},
+		{"Submit Ronde 3", studentApp, "PATCH", base + "/submit", nil, 200},
+		{"Batas Revisi Tercapai", advisorApp, "PATCH", base + "/verify", revise, 400},
+		{"Tetap Bisa Verified", advisorApp, "PATCH", base + "/verify", models.VerifyAchievementRequest{Status: "verified"}, 200},
+	}
+	for _, step := range steps {
+		status, body := decodeBody(t, step.app, step.method, step.path, step.body)
+		if status != step.expectedStatus {
+			t.Fatalf("%s: expected %d, got %d (%v)", step.name, step.expectedStatus, status, body)
+		}
+	}
+
+	revisions, _ := f.revisionRepo.GetByAchievementID(context.Background(), ref.ID.String())
+	if len(revisions) != 3 {
+		t.Fatalf("Expected 3 ronde revisi, got %d", len(revisions))
+	}
+	if r := revisions[0].Review; r == nil || r.Status != "needs_revision" || r.FieldComments["details.rank"] == "" {
+		t.Errorf("Review ronde 1 tidak tersimpan: %+v", r)
+	}
+	if r := revisions[2].Review; r == nil || r.Status != "verified" {
+		t.Errorf("Review ronde terakhir harus verified: %+v", r)
+	}
+
+	status, body := decodeBody(t, studentApp, "GET", base+"/revisions/diff?from=1&to=2", nil)
+	if status != 200 {
+		t.Fatalf("GET diff gagal: %d %v", status, body)
+	}
+	changes := body["data"].(map[string]interface{})["changes"].([]interface{})
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 perubahan, got %v", changes)
+	}
+	change := changes[0].(map[string]interface{})
+	if change["field"] != "details.rank" || change["before"] != float64(2) || change["after"] != float64(1) {
+		t.Errorf("Perubahan tidak sesuai: %v", change)
+	}
+
+	status, _ = decodeBody(t, studentApp, "GET", base+"/revisions/diff?from=1&to=9", nil)
+	if status != 404 {
+		t.Errorf("Ronde tidak ada harus 404, got %d", status)
+	}
+}
+
+func TestAchievementVersions(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "draft", &models.AchievementDetail{
+		Title:           "Juara 2 Lomba Debat",
+		AchievementType: "other",
+		Tags:            []string{"debat"},
+	})
+	base := "/achievements/" + ref.ID.String()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", f.student.UserID.String())
+		return c.Next()
+	})
+	app.Put("/achievements/:id", f.service.Update)
+	app.Get("/achievements/:id/versions", f.service.GetVersions)
+	app.Get("/achievements/:id/versions/diff", f.service.DiffVersions)
+	app.Get("/achievements/:id/versions/:n", f.service.GetVersion)
+
+	edits := []models.CreateAchievementRequest{
+		{Title: "Juara 1 Lomba Debat", Type: "other", Tags: []string{"debat"}},
+		{Title: "Juara 1 Lomba Debat", Type: "other", Tags: []string{"debat"}}, // tidak ada perubahan, tidak membuat versi
+		{Title: "Juara 1 Lomba Debat", Type: "other", Tags: []string{"debat", "nasional"}, Description: "Tingkat nasional"},
+	}
+	for i, edit := range edits {
+		if status, body := decodeBody(t, app, "PUT", base, edit); status != 200 {
+			t.Fatalf("Edit %d gagal: %d %v", i+1, status, body)
+		}
+	}
+
+	status, body := decodeBody(t, app, "GET", base+"/versions", nil)
+	if status != 200 {
+		t.Fatalf("GET versions gagal: %d %v", status, body)
+	}
+	data := body["data"].(map[string]interface{})
+	versions := data["versions"].([]interface{})
+	if len(versions) != 2 || data["current"] != float64(3) {
+		t.Fatalf("Expected 2 versi lama dan current 3, got %v", data)
+	}
+	if v := versions[0].(map[string]interface{}); v["edited_by"] != f.student.UserID.String() {
+		t.Errorf("Editor tidak tercatat: %v", v)
+	}
+
+	status, body = decodeBody(t, app, "GET", base+"/versions/1", nil)
+	detail := body["data"].(map[string]interface{})["detail"].(map[string]interface{})
+	if status != 200 || detail["title"] != "Juara 2 Lomba Debat" {
+		t.Errorf("Versi 1 harus isi awal, got %d %v", status, detail)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedFields []string
+	}{
+		{"Default Versi Lama Terakhir vs Sekarang", "", 200, []string{"description", "tags"}},
+		{"Versi Awal vs Sekarang", "?from=1&to=3", 200, []string{"description", "tags", "title"}},
+		{"Versi Tidak Ada", "?from=1&to=7", 404, nil},
+		{"Parameter Sama", "?from=2&to=2", 400, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, app, "GET", base+"/versions/diff"+tt.query, nil)
+			if status != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+			if tt.expectedFields == nil {
+				return
+			}
+			changes := body["data"].(map[string]interface{})["changes"].([]interface{})
+			if len(changes) != len(tt.expectedFields) {
+				t.Fatalf("Expected perubahan %v, got %v", tt.expectedFields, changes)
+			}
+			for i, field := range tt.expectedFields {
+				if got := changes[i].(map[string]interface{})["field"]; got != field {
+					t.Errorf("Perubahan ke-%d: expected %s, got %v", i, field, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAchievementValidator(t *testing.T) {
+	typeRepo := mocks.NewManualMockAchievementTypeRepo()
+	service.SeedAchievementTypes(context.Background(), typeRepo)
+	validator := service.NewAchievementValidator(typeRepo)
+
+	tests := []struct {
+		name           string
+		detail         models.AchievementDetail
+		expectedFields []string
+	}{
+		{"Kompetisi Valid", models.AchievementDetail{Title: "Juara", AchievementType: "Competition", Details: map[string]interface{}{
+			"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1, "eventDate": "2026-05-01",
+		}}, nil},
+		{"Tipe Tidak Dikenal", models.AchievementDetail{Title: "Juara", AchievementType: "olahraga"}, []string{"achievement_type"}},
+		{"Judul Dan Field Wajib Kosong", models.AchievementDetail{AchievementType: "competition"}, []string{"title", "details.competitionName", "details.competitionLevel"}},
+		{"Enum Dan Angka Salah", models.AchievementDetail{Title: "Juara", AchievementType: "competition", Details: map[string]interface{}{
+			"competitionName": "Gemastik", "competitionLevel": "provinsi", "rank": 1.5,
+		}}, []string{"details.competitionLevel", "details.rank"}},
+		{"Publikasi ISSN Valid", models.AchievementDetail{Title: "Paper", AchievementType: "publication", Details: map[string]interface{}{
+			"publicationType": "journal", "publicationTitle": "Deteksi", "authors": []string{"Budi"}, "issn": "0317-8471",
+		}}, nil},
+		{"Publikasi ISSN Check Digit Salah", models.AchievementDetail{Title: "Paper", AchievementType: "publication", Details: map[string]interface{}{
+			"publicationType": "journal", "publicationTitle": "Deteksi", "authors": []string{"Budi"}, "issn": "0317-8472",
+		}}, []string{"details.issn"}},
+		{"Organisasi Periode Terbalik", models.AchievementDetail{Title: "Ketua", AchievementType: "organization", Details: map[string]interface{}{
+			"organizationName": "BEM", "position": "Ketua", "period": map[string]interface{}{"start": "2025-01-01", "end": "2024-01-01"},
+		}}, []string{"details.period"}},
+		{"Sertifikasi Tanggal Salah Dan Field Asing", models.AchievementDetail{Title: "TOEFL", AchievementType: "certification", Details: map[string]interface{}{
+			"certificationName": "TOEFL", "issuedBy": "ETS", "certificationNumber": "123", "validUntil": "31/12/2027", "nilai": 600,
+		}}, []string{"details.validUntil", "details.nilai"}},
+		{"Lainnya Dengan CustomFields", models.AchievementDetail{Title: "Relawan", AchievementType: "other", Details: map[string]interface{}{
+			"customFields": map[string]interface{}{"jam": 40},
+		}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := validator.Validate(context.Background(), &tt.detail)
+			if err != nil {
+				t.Fatalf("Validate error: %v", err)
+			}
+			if len(errs) != len(tt.expectedFields) {
+				t.Fatalf("Expected error %v, got %+v", tt.expectedFields, errs)
+			}
+			for i, field := range tt.expectedFields {
+				if errs[i].Field != field {
+					t.Errorf("Error ke-%d: expected %s, got %s", i, field, errs[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAchievement_FieldErrors(t *testing.T) {
+	f := newAchievementFixture()
+	app := appAs(f.student.UserID, "/achievements", "POST", f.service.Create)
+
+	status, body := decodeBody(t, app, "POST", "/achievements", models.CreateAchievementRequest{
+		Title: "Juara", Type: "competition", Details: map[string]interface{}{"competitionName": "Gemastik"},
+	})
+	if status != 400 {
+		t.Fatalf("Expected 400, got %d (%v)", status, body)
+	}
+	errs, _ := body["errors"].([]interface{})
+	if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "details.competitionLevel" {
+		t.Errorf("Expected error details.competitionLevel, got %v", body["errors"])
+	}
+
+	status, body = decodeBody(t, app, "POST", "/achievements", models.CreateAchievementRequest{
+		Title: "Juara", Type: "competition", Details: map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "local"},
+	})
+	if status != 201 {
+		t.Errorf("Expected 201, got %d (%v)", status, body)
+	}
+}
+
+func TestAchievementTypes_CustomTypeUsedByValidation(t *testing.T) {
+	f := newAchievementFixture()
+	typeService := service.NewAchievementTypeService(f.typeRepo)
+
+	admin := fiber.New()
+	admin.Post("/achievement-types", typeService.Create)
+	admin.Put("/achievement-types/:key", typeService.Update)
+	admin.Delete("/achievement-types/:key", typeService.Delete)
+	admin.Get("/achievement-types", typeService.GetAll)
+
+	tests := []struct {
+		name           string
+		method, path   string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Key Tidak Valid", "POST", "/achievement-types", models.CreateAchievementTypeRequest{Key: "Paten!", Label: "Paten"}, 400},
+		{"Tipe Data Tidak Dikenal", "POST", "/achievement-types", models.CreateAchievementTypeRequest{Key: "patent", Label: "Paten", Fields: []models.AchievementFieldDefinition{
+			{Name: "patentNumber", DataType: "uuid"},
+		}}, 400},
+		{"Nama Field Bentrok Dengan Field Umum", "POST", "/achievement-types", models.CreateAchievementTypeRequest{Key: "patent", Label: "Paten", Fields: []models.AchievementFieldDefinition{
+			{Name: "location", DataType: models.FieldTypeString},
+		}}, 400},
+		{"Key Bawaan Sudah Ada", "POST", "/achievement-types", models.CreateAchievementTypeRequest{Key: "competition", Label: "Lomba"}, 409},
+		{"Buat Tipe Paten", "POST", "/achievement-types", models.CreateAchievementTypeRequest{Key: "patent", Label: "Paten", DefaultPoints: 40, Fields: []models.AchievementFieldDefinition{
+			{Name: "patentNumber", DataType: models.FieldTypeString, Required: true},
+			{Name: "status", DataType: models.FieldTypeString, Required: true, Options: []string{"granted", "pending"}},
+		}}, 201},
+		{"Ubah Tipe Tidak Ada", "PUT", "/achievement-types/hki", models.UpdateAchievementTypeRequest{}, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, admin, tt.method, tt.path, tt.body)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+
+	studentApp := appAs(f.student.UserID, "/achievements", "POST", f.service.Create)
+	patent := models.CreateAchievementRequest{Title: "Paten Alat", Type: "patent", Details: map[string]interface{}{"patentNumber": "P0001", "status": "granted"}}
+	if status, body := decodeBody(t, studentApp, "POST", "/achievements", patent); status != 201 {
+		t.Fatalf("Prestasi tipe baru harus diterima: %d %v", status, body)
+	}
+	patent.Details = map[string]interface{}{"patentNumber": "P0002", "status": "ditolak"}
+	if status, _ := decodeBody(t, studentApp, "POST", "/achievements", patent); status != 400 {
+		t.Errorf("Enum field buatan admin harus divalidasi, got %d", status)
+	}
+
+	if status, _ := decodeBody(t, admin, "DELETE", "/achievement-types/patent", nil); status != 200 {
+		t.Fatalf("Nonaktifkan tipe gagal: %d", status)
+	}
+	patent.Details = map[string]interface{}{"patentNumber": "P0003", "status": "pending"}
+	if status, _ := decodeBody(t, studentApp, "POST", "/achievements", patent); status != 400 {
+		t.Errorf("Tipe nonaktif tidak boleh dipakai prestasi baru, got %d", status)
+	}
+
+	_, body := decodeBody(t, admin, "GET", "/achievement-types", nil)
+	if types := body["data"].([]interface{}); len(types) != len(service.DefaultAchievementTypes()) {
+		t.Errorf("Tipe nonaktif tidak boleh tampil di daftar, got %d tipe", len(types))
+	}
+}
+
+func TestScoringEngine(t *testing.T) {
+	typeRepo := mocks.NewManualMockAchievementTypeRepo()
+	service.SeedAchievementTypes(context.Background(), typeRepo)
+	engine := service.NewScoringEngine(service.DefaultScoringRules(), typeRepo)
+
+	tests := []struct {
+		name          string
+		detail        models.AchievementDetail
+		expectedTotal int
+	}{
+		{"Kompetisi Nasional Juara 1", models.AchievementDetail{AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "national", "rank": 1}}, 60},
+		{"Kompetisi Internasional Peringkat 5", models.AchievementDetail{AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "international", "rank": 5}}, 45},
+		{"Publikasi Jurnal", models.AchievementDetail{AchievementType: "publication", Details: map[string]interface{}{"publicationType": "journal"}}, 38},
+		{"Ketua Organisasi 2 Tahun", models.AchievementDetail{AchievementType: "organization", Details: map[string]interface{}{
+			"position": "Ketua", "period": map[string]interface{}{"start": "2024-01-01", "end": "2026-01-01"},
+		}}, 60},
+		{"Anggota Organisasi 5 Tahun Dibatasi", models.AchievementDetail{AchievementType: "organization", Details: map[string]interface{}{
+			"position": "Anggota", "period": map[string]interface{}{"start": "2020-01-01", "end": "2025-01-01"},
+		}}, 45},
+		{"Tipe Tanpa Aturan Hanya Poin Dasar", models.AchievementDetail{AchievementType: "other"}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown, err := engine.Score(context.Background(), &tt.detail)
+			if err != nil {
+				t.Fatalf("Score error: %v", err)
+			}
+			if breakdown.Total != tt.expectedTotal {
+				t.Errorf("Expected %d poin, got %d (%+v)", tt.expectedTotal, breakdown.Total, breakdown)
+			}
+		})
+	}
+
+	if _, err := engine.Score(context.Background(), &models.AchievementDetail{AchievementType: "olahraga"}); err == nil {
+		t.Error("Tipe tidak terdaftar harus error")
+	}
+}
+
+func TestVerify_StoresScoreAndRecompute(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", &models.AchievementDetail{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+	})
+
+	app := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	status, body := decodeBody(t, app, "PATCH", "/achievements/"+ref.ID.String()+"/verify", models.VerifyAchievementRequest{Status: "verified"})
+	if status != 200 || body["points"] != float64(60) {
+		t.Fatalf("Verify harus menyimpan 60 poin, got %d %v", status, body)
+	}
+
+	detail, _ := f.achievementRepo.GetDetailByID(context.Background(), ref.MongoAchievementID)
+	if detail.Points != 60 || detail.ScoreBreakdown == nil || len(detail.ScoreBreakdown.Factors) != 2 {
+		t.Fatalf("Poin / rincian tidak tersimpan: %+v", detail)
+	}
+
+	rules := service.DefaultScoringRules()
+	rules.Version = "2026-2"
+	rules.Types["competition"].Factors[0].Values["national"] = 2.5
+	rescored := service.NewAchievementService(f.achievementRepo, f.studentRepo, service.NewVerificationAuthority(f.lectureRepo, f.delegationRepo),
+		service.NewAchievementValidator(f.typeRepo), service.NewScoringEngine(rules, f.typeRepo), service.NewPointLedger(f.ledgerRepo), f.revisionRepo, f.versionRepo, f.memberRepo, f.files, service.AchievementOptions{})
+	recompute := appAs(uuid.New(), "/achievements/scores/recompute", "POST", rescored.RecomputeScores)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedPoints int
+	}{
+		{"Dry Run Tidak Menyimpan", "?dry_run=true", 60},
+		{"Hitung Ulang", "", 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, recompute, "POST", "/achievements/scores/recompute"+tt.query, nil)
+			changed := body["data"].(map[string]interface{})["changed"].([]interface{})
+			if status != 200 || len(changed) != 1 {
+				t.Fatalf("Expected 1 prestasi berubah, got %d %v", status, body)
+			}
+			if detail.Points != tt.expectedPoints {
+				t.Errorf("Expected %d poin tersimpan, got %d", tt.expectedPoints, detail.Points)
+			}
+		})
+	}
+
+	entries, _ := f.ledgerRepo.GetByAchievementID(context.Background(), ref.ID)
+	if len(entries) != 2 || entries[1].EntryType != models.LedgerEntryAdjustment || entries[1].Points != 15 {
+		t.Errorf("Hitung ulang harus menulis adjustment +15 di ledger: %+v", entries)
+	}
+}
+
+func TestVerify_LedgerFailureRollsBack(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", &models.AchievementDetail{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+	})
+	f.ledgerRepo.SetCreateError(errors.New("koneksi terputus"))
+
+	app := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	path := "/achievements/" + ref.ID.String() + "/verify"
+	if status, body := decodeBody(t, app, "PATCH", path, models.VerifyAchievementRequest{Status: "verified"}); status != 500 {
+		t.Fatalf("Expected 500 saat ledger gagal, got %d %v", status, body)
+	}
+
+	stored, _ := f.achievementRepo.GetReferenceByID(context.Background(), ref.ID)
+	if stored.Status != models.AchievementStatusSubmitted || stored.VerifiedBy != nil {
+		t.Fatalf("Status harus kembali submitted, got %+v", stored)
+	}
+	history, _ := f.achievementRepo.GetStatusHistory(context.Background(), ref.ID)
+	if len(history) != 2 || history[1].ToStatus != models.AchievementStatusSubmitted {
+		t.Errorf("Pembatalan harus tercatat di riwayat, got %+v", history)
+	}
+	if detail, _ := f.achievementRepo.GetDetailByID(context.Background(), ref.MongoAchievementID); detail.ScoreBreakdown != nil || detail.Points != 0 {
+		t.Errorf("Poin tidak boleh tersimpan saat verifikasi dibatalkan, got %+v", detail)
+	}
+
+	f.ledgerRepo.SetCreateError(nil)
+	if status, body := decodeBody(t, app, "PATCH", path, models.VerifyAchievementRequest{Status: "verified"}); status != 200 {
+		t.Fatalf("Verifikasi ulang harus berhasil, got %d %v", status, body)
+	}
+	entries, _ := f.ledgerRepo.GetByAchievementID(context.Background(), ref.ID)
+	if len(entries) != 1 || entries[0].Points != 60 {
+		t.Errorf("Expected satu award 60 poin, got %+v", entries)
+	}
+}
+
+func TestVerify_ScoreSaveFailureRollsBack(t *testing.T) {
+	tests := []struct {
+		name          string
+		ledgerFails   bool
+		expectedError string
+	}{
+		{"Rollback Berhasil", false, "Gagal menyimpan poin prestasi, verifikasi dibatalkan. Silakan coba lagi"},
+		{"Rollback Gagal", true, "Verifikasi gagal dan tidak bisa dibatalkan sepenuhnya, hubungi admin untuk mengecek status dan poin prestasi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture()
+			ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", &models.AchievementDetail{
+				Title:           "Juara 1 Gemastik",
+				AchievementType: "competition",
+				Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+			})
+			f.achievementRepo.SetUpdateScoreError(errors.New("mongo tidak tersedia"))
+			if tt.ledgerFails {
+				// award tertulis, reversal gagal
+				f.ledgerRepo.SetCreateErrorAfter(1, errors.New("koneksi terputus"))
+			}
+
+			app := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+			status, body := decodeBody(t, app, "PATCH", "/achievements/"+ref.ID.String()+"/verify", models.VerifyAchievementRequest{Status: "verified"})
+			if status != 500 || body["error"] != tt.expectedError {
+				t.Fatalf("Expected 500 %q, got %d %v", tt.expectedError, status, body)
+			}
+
+			stored, _ := f.achievementRepo.GetReferenceByID(context.Background(), ref.ID)
+			if stored.Status != models.AchievementStatusSubmitted {
+				t.Errorf("Status harus kembali submitted, got %s", stored.Status)
+			}
+			entries, _ := f.ledgerRepo.GetByAchievementID(context.Background(), ref.ID)
+			balance := 0
+			for _, e := range entries {
+				balance += e.Points
+			}
+			if !tt.ledgerFails && balance != 0 {
+				t.Errorf("Poin di ledger harus dibalik, saldo %d", balance)
+			}
+		})
+	}
+}
+
+func TestRevoke_LedgerFailureKeepsVerified(t *testing.T) {
+	f := newAchievementFixture()
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", &models.AchievementDetail{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+	})
+	verify := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	if status, body := decodeBody(t, verify, "PATCH", "/achievements/"+ref.ID.String()+"/verify", models.VerifyAchievementRequest{Status: "verified"}); status != 200 {
+		t.Fatalf("Verify gagal: %d %v", status, body)
+	}
+
+	f.ledgerRepo.SetCreateError(errors.New("koneksi terputus"))
+	revoke := appAs(f.advisor.UserID, "/achievements/:id/revoke", "PATCH", f.service.Revoke)
+	path := "/achievements/" + ref.ID.String() + "/revoke"
+	if status, body := decodeBody(t, revoke, "PATCH", path, models.AchievementTransitionRequest{Note: "Sertifikat palsu"}); status != 500 {
+		t.Fatalf("Expected 500 saat reversal gagal, got %d %v", status, body)
+	}
+	stored, _ := f.achievementRepo.GetReferenceByID(context.Background(), ref.ID)
+	if stored.Status != models.AchievementStatusVerified || stored.RejectionNote != nil {
+		t.Fatalf("Status harus kembali verified, got %+v", stored)
+	}
+
+	f.ledgerRepo.SetCreateError(nil)
+	if status, body := decodeBody(t, revoke, "PATCH", path, models.AchievementTransitionRequest{Note: "Sertifikat palsu"}); status != 200 {
+		t.Fatalf("Pencabutan ulang harus berhasil, got %d %v", status, body)
+	}
+	entries, _ := f.ledgerRepo.GetByAchievementID(context.Background(), ref.ID)
+	if len(entries) != 2 || entries[0].Points+entries[1].Points != 0 {
+		t.Errorf("Expected award lalu reversal, got %+v", entries)
+	}
+}
+
+func TestAcademicYearOf(t *testing.T) {
+	tests := []struct {
+		date     string
+		expected string
+	}{
+		{"2025-08-01", "2025/2026"},
+		{"2025-12-31", "2025/2026"},
+		{"2026-07-31", "2025/2026"},
+		{"2026-08-17", "2026/2027"},
+	}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		if got := service.AcademicYearOf(date); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.date, tt.expected, got)
+		}
+	}
+}
+
+func TestPointLedger_YearWithoutEventDate(t *testing.T) {
+	ledgerRepo := mocks.NewManualMockPointLedgerRepo()
+	ledger := service.NewPointLedger(ledgerRepo)
+	verifiedAt := time.Date(2024, time.October, 20, 0, 0, 0, 0, time.UTC)
+	studentID := uuid.New()
+	ref := &models.AchievementReference{ID: uuid.New(), StudentID: studentID, VerifiedAt: &verifiedAt}
+	detail := &models.AchievementDetail{AchievementType: "certification"}
+	ctx := context.Background()
+
+	award := func(category string, points int) {
+		score := &models.ScoreBreakdown{AchievementType: category, Total: points}
+		if err := ledger.Award(ctx, ref, detail, score, []service.PointAllocation{{StudentID: studentID, Points: points}}, uuid.New()); err != nil {
+			t.Fatalf("Award gagal: %v", err)
+		}
+	}
+	award("certification", 15)
+	// hitung ulang bertahun-tahun kemudian dengan tipe yang sudah diganti tetap masuk baris award-nya
+	award("competition", 40)
+	if err := ledger.Reverse(ctx, ref, uuid.New(), "dicabut"); err != nil {
+		t.Fatalf("Reverse gagal: %v", err)
+	}
+
+	entries, _ := ledgerRepo.GetByAchievementID(ctx, ref.ID)
+	if len(entries) != 3 {
+		t.Fatalf("Expected award, adjustment, reversal, got %+v", entries)
+	}
+	for _, e := range entries {
+		if e.AcademicYear != "2024/2025" || e.Category != "certification" {
+			t.Errorf("%s harus di 2024/2025 kategori certification, got %s %s", e.EntryType, e.AcademicYear, e.Category)
+		}
+	}
+}
+
+func TestTranscript_LedgerAwardsAndRevoke(t *testing.T) {
+	f := newAchievementFixture()
+	verify := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	revoke := appAs(f.advisor.UserID, "/achievements/:id/revoke", "PATCH", f.service.Revoke)
+	transcriptService := service.NewTranscriptService(f.studentRepo, f.ledgerRepo, f.achievementRepo, f.typeRepo)
+	transcript := appAs(f.student.UserID, "/students/current/transcript", "GET", transcriptService.GetCurrent)
+
+	details := []*models.AchievementDetail{
+		{Title: "Juara 1 Gemastik", AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "national", "rank": 1, "eventDate": "2024-10-20"}},
+		{Title: "Sertifikasi Cloud", AchievementType: "certification", Details: map[string]interface{}{"eventDate": "2025-03-02"}},
+		{Title: "Juara 3 Lomba Kampus", AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "local", "rank": 3, "eventDate": "2025-09-01"}},
+	}
+	var refs []*models.AchievementReference
+	for _, d := range details {
+		ref := f.achievementRepo.AddAchievement(f.student.ID, "submitted", d)
+		if status, body := decodeBody(t, verify, "PATCH", "/achievements/"+ref.ID.String()+"/verify", models.VerifyAchievementRequest{Status: "verified"}); status != 200 {
+			t.Fatalf("Verify gagal: %d %v", status, body)
+		}
+		refs = append(refs, ref)
+	}
+
+	// 2024/2025: 60 (competition) + 15 (certification), 2025/2026: 20
+	status, body := decodeBody(t, transcript, "GET", "/students/current/transcript", nil)
+	data := body["data"].(map[string]interface{})
+	years := data["years"].([]interface{})
+	if status != 200 || data["total_points"] != float64(95) || len(years) != 2 {
+		t.Fatalf("Expected total 95 di 2 tahun akademik, got %d %v", status, body)
+	}
+	first := years[0].(map[string]interface{})
+	if first["academic_year"] != "2024/2025" || first["year_of_study"] != float64(1) || first["points"] != float64(75) || len(first["categories"].([]interface{})) != 2 {
+		t.Errorf("Ringkasan tahun pertama salah: %v", first)
+	}
+
+	tests := []struct {
+		name           string
+		note           string
+		expectedStatus int
+	}{
+		{"Tanpa Alasan", "", 400},
+		{"Cabut Verifikasi", "Sertifikat palsu", 200},
+		{"Sudah Dicabut", "ulang", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, revoke, "PATCH", "/achievements/"+refs[0].ID.String()+"/revoke", models.AchievementTransitionRequest{Note: tt.note})
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+
+	entries, _ := f.ledgerRepo.GetByAchievementID(context.Background(), refs[0].ID)
+	if len(entries) != 2 || entries[1].EntryType != models.LedgerEntryReversal || entries[1].Points != -60 || entries[1].AcademicYear != "2024/2025" {
+		t.Fatalf("Pencabutan harus menulis reversal -60: %+v", entries)
+	}
+
+	_, body = decodeBody(t, transcript, "GET", "/students/current/transcript", nil)
+	data = body["data"].(map[string]interface{})
+	if data["total_points"] != float64(35) {
+		t.Errorf("Total setelah pencabutan harus 35, got %v", data["total_points"])
+	}
+}
+
+func TestCompareAchievements(t *testing.T) {
+	base := &models.AchievementDetail{
+		Title:           "Juara 1 GEMASTIK 2025",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"eventDate": "2025-10-20", "organizer": "Puspresnas"},
+		Attachments:     []models.Attachment{{FileName: "sertifikat.pdf", Hash: "abc123"}},
+	}
+
+	tests := []struct {
+		name        string
+		other       models.AchievementDetail
+		isDuplicate bool
+	}{
+		{"Judul Sama Beda Format", models.AchievementDetail{Title: "juara 1 - Gemastik 2025!", AchievementType: "competition"}, true},
+		{"Judul Salah Ketik Tanggal Sama", models.AchievementDetail{Title: "Juara 1 Gemastk 2025", AchievementType: "competition", Details: map[string]interface{}{"eventDate": "2025-10-20T00:00:00Z"}}, true},
+		{"Lampiran Identik", models.AchievementDetail{Title: "Sertifikat", AchievementType: "other", Attachments: []models.Attachment{{FileName: "scan.pdf", Hash: "abc123"}}}, true},
+		{"Lomba Lain Penyelenggara Sama", models.AchievementDetail{Title: "Finalis KMIPN", AchievementType: "competition", Details: map[string]interface{}{"eventDate": "2025-10-20", "organizer": "Puspresnas"}}, false},
+		{"Judul Sama Tipe Beda", models.AchievementDetail{Title: "Juara 1 Gemastik 2025", AchievementType: "certification"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reasons := service.CompareAchievements(base, &tt.other)
+			if (score >= service.DuplicateThreshold) != tt.isDuplicate {
+				t.Errorf("Expected duplikat=%v, got skor %.2f %v", tt.isDuplicate, score, reasons)
+			}
+		})
+	}
+}
+
+func TestDuplicateDetection_CreateWarningAndVerifierSearch(t *testing.T) {
+	f := newAchievementFixture()
+	teammate := &models.Students{UserID: uuid.New(), StudentID: "NIM-002", AdvisorID: &f.advisor.ID}
+	f.studentRepo.Create(context.Background(), teammate)
+
+	details := map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "eventDate": "2025-10-20"}
+	f.achievementRepo.AddAchievement(f.student.ID, "verified", &models.AchievementDetail{Title: "Juara 1 Gemastik", AchievementType: "competition", Details: details})
+	teammateRef := f.achievementRepo.AddAchievement(teammate.ID, "submitted", &models.AchievementDetail{Title: "Juara 1 Gemastik", AchievementType: "competition", Details: details})
+
+	create := appAs(f.student.UserID, "/achievements", "POST", f.service.Create)
+	tests := []struct {
+		name           string
+		title          string
+		expectedWarned bool
+	}{
+		{"Lomba Yang Sama", "Juara 1 GEMASTIK", true},
+		{"Lomba Lain", "Finalis Hackathon Nasional", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.CreateAchievementRequest{Title: tt.title, Type: "competition", Details: map[string]interface{}{"competitionName": "X", "competitionLevel": "national"}}
+			status, body := decodeBody(t, create, "POST", "/achievements", req)
+			if status != 201 {
+				t.Fatalf("Duplikat hanya peringatan, expected 201 got %d (%v)", status, body)
+			}
+			if _, warned := body["warning"]; warned != tt.expectedWarned {
+				t.Errorf("Expected warning=%v, got %v", tt.expectedWarned, body)
+			}
+		})
+	}
+
+	// verifikator melihat kemiripan lintas mahasiswa
+	search := appAs(f.advisor.UserID, "/achievements/:id/possible-duplicates", "GET", f.service.GetPossibleDuplicates)
+	status, body := decodeBody(t, search, "GET", "/achievements/"+teammateRef.ID.String()+"/possible-duplicates", nil)
+	candidates, _ := body["data"].([]interface{})
+	if status != 200 || len(candidates) != 2 {
+		t.Fatalf("Expected 2 kemungkinan duplikat milik mahasiswa lain, got %d %v", status, body)
+	}
+	if candidates[0].(map[string]interface{})["student_id"] != f.student.ID.String() {
+		t.Errorf("Kandidat harus milik mahasiswa pertama: %v", candidates[0])
+	}
+}
+
+func TestTeamAchievement_InvitationsAllocationAndVerify(t *testing.T) {
+	f := newAchievementFixture()
+	teammate := &models.Students{UserID: uuid.New(), StudentID: "NIM-002", AcademicYear: "2024"}
+	f.studentRepo.Create(context.Background(), teammate)
+
+	ref := f.achievementRepo.AddAchievement(f.student.ID, "draft", &models.AchievementDetail{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1, "eventDate": "2025-10-20"},
+	})
+	id := ref.ID.String()
+
+	owner := fiber.New()
+	owner.Use(func(c *fiber.Ctx) error { c.Locals("user_id", f.student.UserID.String()); return c.Next() })
+	owner.Post("/achievements/:id/members", f.service.InviteMember)
+	owner.Put("/achievements/:id/members", f.service.UpdateMembers)
+	owner.Patch("/achievements/:id/submit", f.service.Submit)
+
+	member := fiber.New()
+	member.Use(func(c *fiber.Ctx) error { c.Locals("user_id", teammate.UserID.String()); return c.Next() })
+	member.Get("/achievements/invitations", f.service.GetInvitations)
+	member.Get("/achievements/achiev", f.service.GetMyAchievment)
+	member.Get("/achievements/:id/members", f.service.GetMembers)
+	member.Patch("/achievements/:id/invitation/accept", f.service.AcceptInvitation)
+
+	allocation := func(ownerShare, memberShare int) models.UpdateTeamMembersRequest {
+		return models.UpdateTeamMembersRequest{Members: []models.TeamMemberAllocation{
+			{StudentID: f.student.ID.String(), Role: "leader", PointShare: ownerShare},
+			{StudentID: teammate.ID.String(), Role: "member", PointShare: memberShare},
+		}}
+	}
+
+	steps := []struct {
+		name           string
+		app            *fiber.App
+		method, path   string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Undang Diri Sendiri", owner, "POST", "/achievements/" + id + "/members", models.InviteTeamMemberRequest{StudentID: f.student.ID.String()}, 400},
+		{"Undang Anggota", owner, "POST", "/achievements/" + id + "/members", models.InviteTeamMemberRequest{StudentID: teammate.ID.String()}, 201},
+		{"Undang Dua Kali", owner, "POST", "/achievements/" + id + "/members", models.InviteTeamMemberRequest{StudentID: teammate.ID.String()}, 409},
+		{"Anggota Melihat Prestasi Tim", member, "GET", "/achievements/" + id + "/members", nil, 200},
+		{"Submit Saat Undangan Belum Dijawab", owner, "PATCH", "/achievements/" + id + "/submit", nil, 400},
+		{"Terima Undangan", member, "PATCH", "/achievements/" + id + "/invitation/accept", nil, 200},
+		{"Terima Ulang", member, "PATCH", "/achievements/" + id + "/invitation/accept", nil, 400},
+		{"Pembagian Tidak 100", owner, "PUT", "/achievements/" + id + "/members", allocation(60, 30), 400},
+		{"Pembagian 70/30", owner, "PUT", "/achievements/" + id + "/members", allocation(70, 30), 200},
+		{"Submit", owner, "PATCH", "/achievements/" + id + "/submit", nil, 200},
+	}
+	for _, tt := range steps {
+		status, body := decodeBody(t, tt.app, tt.method, tt.path, tt.body)
+		if status != tt.expectedStatus {
+			t.Fatalf("%s: expected %d, got %d (%v)", tt.name, tt.expectedStatus, status, body)
+		}
+	}
+
+	// satu verifikasi oleh dosen wali pelapor berlaku untuk semua anggota
+	verify := appAs(f.advisor.UserID, "/achievements/:id/verify", "PATCH", f.service.Verify)
+	status, body := decodeBody(t, verify, "PATCH", "/achievements/"+id+"/verify", models.VerifyAchievementRequest{Status: "verified"})
+	if status != 200 || body["points"] != float64(60) {
+		t.Fatalf("Verify gagal: %d %v", status, body)
+	}
+
+	expected := map[uuid.UUID]int{f.student.ID: 42, teammate.ID: 18}
+	for studentID, points := range expected {
+		entries, _ := f.ledgerRepo.GetByStudentID(context.Background(), studentID)
+		if len(entries) != 1 || entries[0].Points != points || entries[0].AchievementID != ref.ID {
+			t.Errorf("Expected %d poin untuk %s, got %+v", points, studentID, entries)
+		}
+	}
+
+	_, body = decodeBody(t, member, "GET", "/achievements/achiev", nil)
+	if refs, _ := body["data"].([]interface{}); len(refs) != 1 || refs[0].(map[string]interface{})["status"] != "verified" {
+		t.Errorf("Prestasi tim harus muncul di daftar anggota dengan status verified: %v", body)
+	}
+}
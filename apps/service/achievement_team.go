@@ -0,0 +1,549 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var ErrTeamNotReady = errors.New("tim belum siap disubmit")
+
+// AchievementTeam anggota prestasi tim dan pembagian poinnya. Prestasi tanpa baris achievement_members adalah prestasi perorangan
+type AchievementTeam struct {
+	memberRepo repository.AchievementMemberRepository
+}
+
+func NewAchievementTeam(memberRepo repository.AchievementMemberRepository) *AchievementTeam {
+	return &AchievementTeam{memberRepo: memberRepo}
+}
+
+// IsMember - anggota yang masih diundang atau sudah menerima boleh melihat prestasi tim
+func (t *AchievementTeam) IsMember(ctx context.Context, achievementID, studentID uuid.UUID) (bool, error) {
+	member, err := t.memberRepo.Get(ctx, achievementID, studentID)
+	if err != nil || member == nil {
+		return false, err
+	}
+	return member.Status != models.TeamMemberDeclined, nil
+}
+
+// CheckReady dipanggil sebelum submit: semua undangan harus sudah dijawab dan pembagian poin anggota yang menerima harus 100%
+func (t *AchievementTeam) CheckReady(ctx context.Context, achievementID uuid.UUID) error {
+	members, err := t.memberRepo.GetByAchievementID(ctx, achievementID)
+	if err != nil {
+		return err
+	}
+
+	var accepted []models.AchievementMember
+	for _, m := range members {
+		switch m.Status {
+		case models.TeamMemberInvited:
+			return errors.Join(ErrTeamNotReady, errors.New("masih ada undangan anggota yang belum dijawab"))
+		case models.TeamMemberAccepted:
+			accepted = append(accepted, m)
+		}
+	}
+	if msg := checkPointShares(accepted); msg != "" {
+		return errors.Join(ErrTeamNotReady, errors.New(msg))
+	}
+	return nil
+}
+
+// checkPointShares - semua 0 (dibagi rata) atau jumlahnya tepat 100
+func checkPointShares(members []models.AchievementMember) string {
+	total := 0
+	for _, m := range members {
+		if m.PointShare < 0 {
+			return "point_share tidak boleh negatif"
+		}
+		total += m.PointShare
+	}
+	if total != 0 && total != 100 {
+		return "jumlah point_share anggota harus 100 (atau semua 0 untuk dibagi rata)"
+	}
+	return ""
+}
+
+// Allocate membagi total poin ke anggota yang menerima undangan. Sisa pembulatan diberikan ke ketua tim
+func (t *AchievementTeam) Allocate(ctx context.Context, ref *models.AchievementReference, total int) ([]PointAllocation, error) {
+	members, err := t.memberRepo.GetByAchievementID(ctx, ref.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []models.AchievementMember
+	for _, m := range members {
+		if m.Status == models.TeamMemberAccepted {
+			accepted = append(accepted, m)
+		}
+	}
+	if len(accepted) == 0 {
+		return []PointAllocation{{StudentID: ref.StudentID, Points: total}}, nil
+	}
+	return allocatePoints(accepted, total), nil
+}
+
+func allocatePoints(members []models.AchievementMember, total int) []PointAllocation {
+	shareSum := 0
+	for _, m := range members {
+		shareSum += m.PointShare
+	}
+
+	leader := 0
+	allocations := make([]PointAllocation, len(members))
+	remaining := total
+	for i, m := range members {
+		points := total / len(members)
+		if shareSum > 0 {
+			points = total * m.PointShare / 100
+		}
+		allocations[i] = PointAllocation{StudentID: m.StudentID, Points: points}
+		remaining -= points
+		if m.Role == models.TeamRoleLeader && members[leader].Role != models.TeamRoleLeader {
+			leader = i
+		}
+	}
+	allocations[leader].Points += remaining
+	return allocations
+}
+
+func validTeamRole(role string) bool {
+	return role == models.TeamRoleLeader || role == models.TeamRoleMember
+}
+
+// currentStudent profil mahasiswa yang login. Kalau gagal, respons error sudah dikirim.
+func (s *AchievementService) currentStudent(c *fiber.Ctx) (*models.Students, error) {
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	student, err := s.studentRepo.GetByUserID(c.Context(), userUUID)
+	if err != nil || student == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Profil mahasiswa tidak ditemukan"})
+	}
+	return student, nil
+}
+
+// editableTeam prestasi milik mahasiswa yang login dan masih boleh diubah anggotanya. Kalau gagal, respons error sudah dikirim.
+func (s *AchievementService) editableTeam(c *fiber.Ctx) (*models.AchievementReference, error) {
+	ref, err := s.ownedReference(c, "mengubah anggota")
+	if ref == nil {
+		return nil, err
+	}
+	if !CanEditAchievement(ref.Status) {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "Anggota tim hanya bisa diubah saat status draft atau needs_revision"})
+	}
+	return ref, nil
+}
+
+// GetMembers godoc
+// @Summary      Anggota prestasi tim
+// @Description  Mengambil anggota prestasi tim beserta peran, status undangan dan pembagian poin. Prestasi perorangan mengembalikan daftar kosong
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Daftar anggota"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Router       /achievements/{id}/members [get]
+func (s *AchievementService) GetMembers(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	members, err := s.team.memberRepo.GetByAchievementID(c.Context(), ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil anggota tim"})
+	}
+	if members == nil {
+		members = []models.AchievementMember{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Anggota prestasi tim",
+		"data":    members,
+	})
+}
+
+// InviteMember godoc
+// @Summary      Undang anggota tim
+// @Description  Pelapor mengundang mahasiswa lain sebagai anggota prestasi tim (khusus pemilik, status draft atau needs_revision). Undangan pertama menjadikan pelapor ketua tim. Anggota harus menerima undangan sebelum prestasi bisa disubmit
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.InviteTeamMemberRequest true "ID mahasiswa dan peran (leader/member)"
+// @Success      201  {object}  map[string]interface{} "Undangan dibuat"
+// @Failure      400  {object}  map[string]interface{} "Mahasiswa atau peran tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik prestasi"
+// @Failure      409  {object}  map[string]interface{} "Mahasiswa sudah menjadi anggota"
+// @Router       /achievements/{id}/members [post]
+func (s *AchievementService) InviteMember(c *fiber.Ctx) error {
+	ref, err := s.editableTeam(c)
+	if ref == nil {
+		return err
+	}
+
+	var req models.InviteTeamMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	if req.Role == "" {
+		req.Role = models.TeamRoleMember
+	}
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if !validTeamRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Peran harus 'leader' atau 'member'"})
+	}
+
+	studentUUID, err := uuid.Parse(req.StudentID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "student_id tidak valid"})
+	}
+	if studentUUID == ref.StudentID {
+		return c.Status(400).JSON(fiber.Map{"error": "Pelapor sudah otomatis menjadi anggota tim"})
+	}
+
+	ctx := c.Context()
+	invitee, err := s.studentRepo.GetByID(ctx, studentUUID)
+	if err != nil || invitee == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Mahasiswa yang diundang tidak ditemukan"})
+	}
+
+	members, err := s.team.memberRepo.GetByAchievementID(ctx, ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil anggota tim"})
+	}
+
+	inviterID, _ := currentUserID(c)
+	now := time.Now()
+	if len(members) == 0 {
+		submitterRole := models.TeamRoleLeader
+		if req.Role == models.TeamRoleLeader {
+			submitterRole = models.TeamRoleMember
+		}
+		submitter := models.AchievementMember{
+			AchievementID: ref.ID,
+			StudentID:     ref.StudentID,
+			Role:          submitterRole,
+			Status:        models.TeamMemberAccepted,
+			RespondedAt:   &now,
+		}
+		if err := s.team.memberRepo.Create(ctx, &submitter); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat tim"})
+		}
+		members = append(members, submitter)
+	}
+
+	var existing *models.AchievementMember
+	for i, m := range members {
+		if m.StudentID == studentUUID {
+			existing = &members[i]
+		} else if req.Role == models.TeamRoleLeader && m.Role == models.TeamRoleLeader && m.Status != models.TeamMemberDeclined {
+			return c.Status(400).JSON(fiber.Map{"error": "Ketua tim sudah ada, ubah peran lewat PUT /achievements/{id}/members"})
+		}
+	}
+
+	// mahasiswa yang pernah menolak boleh diundang ulang
+	if existing != nil {
+		if existing.Status != models.TeamMemberDeclined {
+			return c.Status(409).JSON(fiber.Map{"error": "Mahasiswa sudah menjadi anggota atau masih diundang"})
+		}
+		existing.Role = req.Role
+		existing.Status = models.TeamMemberInvited
+		existing.PointShare = 0
+		existing.InvitedBy = &inviterID
+		existing.RespondedAt = nil
+		if err := s.team.memberRepo.Update(ctx, existing); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengundang anggota"})
+		}
+		return c.Status(201).JSON(fiber.Map{"message": "Undangan anggota tim dikirim ulang", "data": existing})
+	}
+
+	member := &models.AchievementMember{
+		AchievementID: ref.ID,
+		StudentID:     studentUUID,
+		Role:          req.Role,
+		Status:        models.TeamMemberInvited,
+		InvitedBy:     &inviterID,
+	}
+	if err := s.team.memberRepo.Create(ctx, member); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengundang anggota"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Undangan anggota tim dikirim",
+		"data":    member,
+	})
+}
+
+// UpdateMembers godoc
+// @Summary      Atur peran dan pembagian poin tim
+// @Description  Mengatur peran (tepat satu leader) dan point_share (persen) untuk semua anggota yang belum menolak. Jumlah point_share harus 100, atau semua 0 untuk dibagi rata. Khusus pemilik, status draft atau needs_revision
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.UpdateTeamMembersRequest true "Peran dan pembagian poin setiap anggota"
+// @Success      200  {object}  map[string]interface{} "Anggota diperbarui"
+// @Failure      400  {object}  map[string]interface{} "Daftar anggota, peran atau pembagian poin tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik prestasi"
+// @Router       /achievements/{id}/members [put]
+func (s *AchievementService) UpdateMembers(c *fiber.Ctx) error {
+	ref, err := s.editableTeam(c)
+	if ref == nil {
+		return err
+	}
+
+	var req models.UpdateTeamMembersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	ctx := c.Context()
+	members, err := s.team.memberRepo.GetByAchievementID(ctx, ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil anggota tim"})
+	}
+
+	active := make(map[string]*models.AchievementMember)
+	for i, m := range members {
+		if m.Status != models.TeamMemberDeclined {
+			active[m.StudentID.String()] = &members[i]
+		}
+	}
+	if len(active) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Prestasi ini bukan prestasi tim, undang anggota terlebih dahulu"})
+	}
+	if len(req.Members) != len(active) {
+		return c.Status(400).JSON(fiber.Map{"error": "Sertakan semua anggota tim yang belum menolak undangan"})
+	}
+
+	var errs []models.FieldError
+	leaders := 0
+	updated := make([]models.AchievementMember, 0, len(req.Members))
+	for i, item := range req.Members {
+		path := fmt.Sprintf("members[%d]", i)
+		member, ok := active[item.StudentID]
+		if !ok {
+			errs = append(errs, models.FieldError{Field: path + ".student_id", Message: "bukan anggota tim"})
+			continue
+		}
+		delete(active, item.StudentID)
+
+		role := strings.ToLower(strings.TrimSpace(item.Role))
+		if !validTeamRole(role) {
+			errs = append(errs, models.FieldError{Field: path + ".role", Message: "harus 'leader' atau 'member'"})
+		}
+		if role == models.TeamRoleLeader {
+			leaders++
+		}
+		member.Role = role
+		member.PointShare = item.PointShare
+		updated = append(updated, *member)
+	}
+	if leaders != 1 {
+		errs = append(errs, models.FieldError{Field: "members", Message: "harus ada tepat satu leader"})
+	}
+	if msg := checkPointShares(updated); msg != "" {
+		errs = append(errs, models.FieldError{Field: "members", Message: msg})
+	}
+	if len(errs) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Pengaturan anggota tim tidak valid", "errors": errs})
+	}
+
+	for i := range updated {
+		if err := s.team.memberRepo.Update(ctx, &updated[i]); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui anggota tim"})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Anggota tim berhasil diperbarui",
+		"data":    updated,
+	})
+}
+
+// RemoveMember godoc
+// @Summary      Keluarkan anggota tim
+// @Description  Menghapus anggota atau membatalkan undangan (khusus pemilik, status draft atau needs_revision). Pelapor tidak bisa dikeluarkan; kalau anggota lain habis prestasi kembali menjadi perorangan
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        studentId path string true "ID mahasiswa anggota (UUID)"
+// @Success      200  {object}  map[string]interface{} "Anggota dikeluarkan"
+// @Failure      400  {object}  map[string]interface{} "Pelapor tidak bisa dikeluarkan"
+// @Failure      404  {object}  map[string]interface{} "Bukan anggota tim"
+// @Router       /achievements/{id}/members/{studentId} [delete]
+func (s *AchievementService) RemoveMember(c *fiber.Ctx) error {
+	ref, err := s.editableTeam(c)
+	if ref == nil {
+		return err
+	}
+
+	studentUUID, err := uuid.Parse(c.Params("studentId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID mahasiswa tidak valid"})
+	}
+	if studentUUID == ref.StudentID {
+		return c.Status(400).JSON(fiber.Map{"error": "Pelapor tidak bisa dikeluarkan dari tim"})
+	}
+
+	ctx := c.Context()
+	members, err := s.team.memberRepo.GetByAchievementID(ctx, ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil anggota tim"})
+	}
+
+	var removed *models.AchievementMember
+	var submitter *models.AchievementMember
+	others := 0
+	for i, m := range members {
+		switch m.StudentID {
+		case studentUUID:
+			removed = &members[i]
+		case ref.StudentID:
+			submitter = &members[i]
+		default:
+			others++
+		}
+	}
+	if removed == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Mahasiswa bukan anggota tim"})
+	}
+
+	if err := s.team.memberRepo.Delete(ctx, removed.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengeluarkan anggota"})
+	}
+	if others == 0 && submitter != nil {
+		if err := s.team.memberRepo.Delete(ctx, submitter.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengembalikan prestasi menjadi perorangan"})
+		}
+	}
+
+	return c.JSON(fiber.Map{"message": "Anggota tim berhasil dikeluarkan"})
+}
+
+// GetInvitations godoc
+// @Summary      Undangan tim saya
+// @Description  Mengambil undangan prestasi tim yang belum dijawab oleh mahasiswa yang login
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Daftar undangan"
+// @Failure      404  {object}  map[string]interface{} "Profil mahasiswa tidak ditemukan"
+// @Router       /achievements/invitations [get]
+func (s *AchievementService) GetInvitations(c *fiber.Ctx) error {
+	student, err := s.currentStudent(c)
+	if student == nil {
+		return err
+	}
+
+	ctx := c.Context()
+	memberships, err := s.team.memberRepo.GetByStudentID(ctx, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil undangan"})
+	}
+
+	invitations := []fiber.Map{}
+	for _, m := range memberships {
+		if m.Status != models.TeamMemberInvited {
+			continue
+		}
+		ref, err := s.achievementRepo.GetReferenceByID(ctx, m.AchievementID)
+		if err != nil || ref == nil || ref.DeletedAt != nil {
+			continue
+		}
+		item := fiber.Map{"invitation": m, "achievement": ref}
+		if detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID); err == nil && detail != nil {
+			item["title"] = detail.Title
+		}
+		invitations = append(invitations, item)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Undangan prestasi tim",
+		"data":    invitations,
+	})
+}
+
+// AcceptInvitation godoc
+// @Summary      Terima undangan tim
+// @Description  Mahasiswa yang diundang menerima keanggotaan prestasi tim
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Undangan diterima"
+// @Failure      400  {object}  map[string]interface{} "Undangan sudah dijawab"
+// @Failure      404  {object}  map[string]interface{} "Tidak ada undangan"
+// @Router       /achievements/{id}/invitation/accept [patch]
+func (s *AchievementService) AcceptInvitation(c *fiber.Ctx) error {
+	return s.respondInvitation(c, models.TeamMemberAccepted, "Undangan tim diterima")
+}
+
+// DeclineInvitation godoc
+// @Summary      Tolak undangan tim
+// @Description  Mahasiswa yang diundang menolak keanggotaan prestasi tim
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Undangan ditolak"
+// @Failure      400  {object}  map[string]interface{} "Undangan sudah dijawab"
+// @Failure      404  {object}  map[string]interface{} "Tidak ada undangan"
+// @Router       /achievements/{id}/invitation/decline [patch]
+func (s *AchievementService) DeclineInvitation(c *fiber.Ctx) error {
+	return s.respondInvitation(c, models.TeamMemberDeclined, "Undangan tim ditolak")
+}
+
+func (s *AchievementService) respondInvitation(c *fiber.Ctx, status string, message string) error {
+	achievUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	student, err := s.currentStudent(c)
+	if student == nil {
+		return err
+	}
+
+	ctx := c.Context()
+	member, err := s.team.memberRepo.Get(ctx, achievUUID, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil undangan"})
+	}
+	if member == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tidak ada undangan untuk prestasi ini"})
+	}
+	if member.Status != models.TeamMemberInvited {
+		return c.Status(400).JSON(fiber.Map{"error": "Undangan sudah dijawab"})
+	}
+
+	now := time.Now()
+	member.Status = status
+	member.RespondedAt = &now
+	if err := s.team.memberRepo.Update(ctx, member); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan jawaban undangan"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": message,
+		"data":    member,
+	})
+}
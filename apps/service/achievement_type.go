@@ -0,0 +1,269 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	achievementTypeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	fieldNamePattern          = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+)
+
+var knownFieldTypes = map[string]bool{
+	models.FieldTypeString:     true,
+	models.FieldTypeNumber:     true,
+	models.FieldTypeInteger:    true,
+	models.FieldTypeDate:       true,
+	models.FieldTypeStringList: true,
+	models.FieldTypePeriod:     true,
+	models.FieldTypeISSN:       true,
+	models.FieldTypeObject:     true,
+}
+
+// AchievementTypeService registry tipe prestasi yang bisa ditambah admin tanpa ubah kode
+type AchievementTypeService struct {
+	typeRepo repository.AchievementTypeRepository
+}
+
+func NewAchievementTypeService(typeRepo repository.AchievementTypeRepository) *AchievementTypeService {
+	return &AchievementTypeService{typeRepo: typeRepo}
+}
+
+// validateFieldDefinitions memeriksa definisi field buatan admin sebelum disimpan
+func validateFieldDefinitions(fields []models.AchievementFieldDefinition) []models.FieldError {
+	var errs []models.FieldError
+	seen := make(map[string]bool)
+	for _, field := range commonAchievementFields {
+		seen[field.Name] = true
+	}
+
+	for i, field := range fields {
+		path := fmt.Sprintf("fields[%d]", i)
+		switch {
+		case !fieldNamePattern.MatchString(field.Name):
+			errs = append(errs, models.FieldError{Field: path + ".name", Message: "wajib diisi, huruf/angka/underscore dan diawali huruf"})
+		case seen[field.Name]:
+			errs = append(errs, models.FieldError{Field: path + ".name", Message: "nama field '" + field.Name + "' sudah dipakai"})
+		}
+		seen[field.Name] = true
+
+		if !knownFieldTypes[field.DataType] {
+			errs = append(errs, models.FieldError{Field: path + ".data_type", Message: "tipe data tidak dikenal"})
+		}
+		if len(field.Options) > 0 && field.DataType != models.FieldTypeString {
+			errs = append(errs, models.FieldError{Field: path + ".options", Message: "options hanya untuk data_type string"})
+		}
+	}
+	return errs
+}
+
+// GetAll godoc
+// @Summary      Daftar tipe prestasi
+// @Description  Mengambil tipe prestasi aktif beserta definisi field details untuk menampilkan form secara dinamis. include_inactive=true hanya untuk pemegang achievement_type:manage
+// @Tags         Achievement Types
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        include_inactive query bool false "Ikut tampilkan tipe nonaktif"
+// @Success      200  {object}  map[string]interface{} "Daftar tipe prestasi"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
+// @Router       /achievement-types [get]
+func (s *AchievementTypeService) GetAll(c *fiber.Ctx) error {
+	includeInactive := c.QueryBool("include_inactive") && middleware.HasPermission(c, "achievement_type:manage")
+
+	types, err := s.typeRepo.GetAll(c.Context(), includeInactive)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil tipe prestasi"})
+	}
+	if types == nil {
+		types = []models.AchievementTypeDefinition{}
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Daftar tipe prestasi",
+		"common_fields": commonAchievementFields,
+		"data":          types,
+	})
+}
+
+// GetByKey godoc
+// @Summary      Detail tipe prestasi
+// @Description  Mengambil satu tipe prestasi berdasarkan key
+// @Tags         Achievement Types
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key path string true "Key tipe prestasi, misal competition"
+// @Success      200  {object}  map[string]interface{} "Detail tipe prestasi"
+// @Failure      404  {object}  map[string]interface{} "Tipe tidak ditemukan"
+// @Router       /achievement-types/{key} [get]
+func (s *AchievementTypeService) GetByKey(c *fiber.Ctx) error {
+	t, err := s.findType(c)
+	if t == nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Detail tipe prestasi",
+		"data":    t,
+	})
+}
+
+// findType mengambil tipe :key. Kalau gagal, respons error sudah dikirim.
+func (s *AchievementTypeService) findType(c *fiber.Ctx) (*models.AchievementTypeDefinition, error) {
+	t, err := s.typeRepo.GetByKey(c.Context(), NormalizeAchievementType(c.Params("key")))
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil tipe prestasi"})
+	}
+	if t == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Tipe prestasi tidak ditemukan"})
+	}
+	return t, nil
+}
+
+// Create godoc
+// @Summary      Tambah tipe prestasi
+// @Description  Admin mendefinisikan tipe prestasi baru (key, label, field details, poin default)
+// @Tags         Achievement Types
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateAchievementTypeRequest true "Definisi tipe prestasi"
+// @Success      201  {object}  map[string]interface{} "Tipe prestasi dibuat"
+// @Failure      400  {object}  map[string]interface{} "Definisi tidak valid"
+// @Failure      409  {object}  map[string]interface{} "Key sudah dipakai"
+// @Router       /achievement-types [post]
+func (s *AchievementTypeService) Create(c *fiber.Ctx) error {
+	var req models.CreateAchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	t := &models.AchievementTypeDefinition{
+		Key:           NormalizeAchievementType(req.Key),
+		Label:         strings.TrimSpace(req.Label),
+		Fields:        req.Fields,
+		DefaultPoints: req.DefaultPoints,
+		IsActive:      true,
+	}
+
+	var errs []models.FieldError
+	if !achievementTypeKeyPattern.MatchString(t.Key) {
+		errs = append(errs, models.FieldError{Field: "key", Message: "2-50 karakter huruf kecil, angka atau underscore, diawali huruf"})
+	}
+	errs = append(errs, validateTypeDefinition(t)...)
+	if len(errs) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Definisi tipe prestasi tidak valid", "errors": errs})
+	}
+
+	ctx := c.Context()
+	existing, err := s.typeRepo.GetByKey(ctx, t.Key)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek tipe prestasi"})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Key tipe prestasi sudah dipakai"})
+	}
+
+	if err := s.typeRepo.Create(ctx, t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan tipe prestasi"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Tipe prestasi berhasil dibuat",
+		"data":    t,
+	})
+}
+
+func validateTypeDefinition(t *models.AchievementTypeDefinition) []models.FieldError {
+	var errs []models.FieldError
+	if t.Label == "" {
+		errs = append(errs, models.FieldError{Field: "label", Message: "wajib diisi"})
+	}
+	if t.DefaultPoints < 0 {
+		errs = append(errs, models.FieldError{Field: "default_points", Message: "tidak boleh negatif"})
+	}
+	return append(errs, validateFieldDefinitions(t.Fields)...)
+}
+
+// Update godoc
+// @Summary      Ubah tipe prestasi
+// @Description  Mengubah label, field details, poin default atau status aktif. Key tidak bisa diubah. Field yang tidak dikirim tidak berubah
+// @Tags         Achievement Types
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key path string true "Key tipe prestasi"
+// @Param        request body models.UpdateAchievementTypeRequest true "Perubahan"
+// @Success      200  {object}  map[string]interface{} "Tipe prestasi diperbarui"
+// @Failure      400  {object}  map[string]interface{} "Definisi tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Tipe tidak ditemukan"
+// @Router       /achievement-types/{key} [put]
+func (s *AchievementTypeService) Update(c *fiber.Ctx) error {
+	t, err := s.findType(c)
+	if t == nil {
+		return err
+	}
+
+	var req models.UpdateAchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+	if req.Label != nil {
+		t.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.Fields != nil {
+		t.Fields = *req.Fields
+	}
+	if req.DefaultPoints != nil {
+		t.DefaultPoints = *req.DefaultPoints
+	}
+	if req.IsActive != nil {
+		t.IsActive = *req.IsActive
+	}
+
+	if errs := validateTypeDefinition(t); len(errs) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Definisi tipe prestasi tidak valid", "errors": errs})
+	}
+
+	if err := s.typeRepo.Update(c.Context(), t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memperbarui tipe prestasi"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Tipe prestasi berhasil diperbarui",
+		"data":    t,
+	})
+}
+
+// Delete godoc
+// @Summary      Nonaktifkan tipe prestasi
+// @Description  Tipe tidak dihapus permanen karena masih dipakai prestasi lama, hanya dinonaktifkan sehingga tidak bisa dipilih untuk prestasi baru
+// @Tags         Achievement Types
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key path string true "Key tipe prestasi"
+// @Success      200  {object}  map[string]interface{} "Tipe prestasi dinonaktifkan"
+// @Failure      404  {object}  map[string]interface{} "Tipe tidak ditemukan"
+// @Router       /achievement-types/{key} [delete]
+func (s *AchievementTypeService) Delete(c *fiber.Ctx) error {
+	t, err := s.findType(c)
+	if t == nil {
+		return err
+	}
+
+	t.IsActive = false
+	if err := s.typeRepo.Update(c.Context(), t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menonaktifkan tipe prestasi"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Tipe prestasi berhasil dinonaktifkan"})
+}
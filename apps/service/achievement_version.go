@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// archiveVersion menyimpan isi prestasi sebelum diedit sebagai versi berikutnya
+func (s *AchievementService) archiveVersion(ctx context.Context, ref *models.AchievementReference, previous *models.AchievementDetail, editedBy uuid.UUID) (*models.AchievementVersion, error) {
+	existing, err := s.versionRepo.GetByAchievementID(ctx, ref.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	version := &models.AchievementVersion{
+		AchievementID: ref.ID.String(),
+		Version:       next,
+		Detail:        *previous,
+		EditedBy:      editedBy.String(),
+		EditedAt:      time.Now(),
+	}
+	if err := s.versionRepo.Create(ctx, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// versionDetail isi prestasi pada versi n. Versi terbaru (jumlah versi lama + 1) adalah isi yang sekarang tersimpan.
+func (s *AchievementService) versionDetail(ctx context.Context, ref *models.AchievementReference, n int, versions []models.AchievementVersion) (*models.AchievementDetail, error) {
+	if n == len(versions)+1 {
+		return s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	}
+	for i := range versions {
+		if versions[i].Version == n {
+			return &versions[i].Detail, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetVersions godoc
+// @Summary      Daftar versi isi prestasi
+// @Description  Mengambil daftar versi isi prestasi. Versi n adalah isi sebelum perubahan ke-n (edited_by / edited_at = siapa dan kapan perubahan itu dilakukan), versi terakhir (current) adalah isi saat ini
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Daftar versi"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Router       /achievements/{id}/versions [get]
+func (s *AchievementService) GetVersions(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	versions, err := s.versionRepo.GetByAchievementID(c.Context(), ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat versi"})
+	}
+
+	list := make([]fiber.Map, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, fiber.Map{
+			"version":   v.Version,
+			"title":     v.Detail.Title,
+			"edited_by": v.EditedBy,
+			"edited_at": v.EditedAt,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Riwayat versi prestasi",
+		"data": fiber.Map{
+			"current":  len(versions) + 1,
+			"versions": list,
+		},
+	})
+}
+
+// GetVersion godoc
+// @Summary      Isi prestasi pada versi tertentu
+// @Description  Mengambil snapshot isi prestasi pada versi n. n = current mengembalikan isi saat ini
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        n path int true "Nomor versi"
+// @Success      200  {object}  map[string]interface{} "Isi prestasi pada versi n"
+// @Failure      400  {object}  map[string]interface{} "ID atau nomor versi tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi atau versi tidak ditemukan"
+// @Router       /achievements/{id}/versions/{n} [get]
+func (s *AchievementService) GetVersion(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	n, err := strconv.Atoi(c.Params("n"))
+	if err != nil || n < 1 {
+		return c.Status(400).JSON(fiber.Map{"error": "Nomor versi tidak valid"})
+	}
+
+	ctx := c.Context()
+	versions, err := s.versionRepo.GetByAchievementID(ctx, ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat versi"})
+	}
+
+	detail, err := s.versionDetail(ctx, ref, n, versions)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil isi versi"})
+	}
+	if detail == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Versi tidak ditemukan"})
+	}
+
+	data := fiber.Map{
+		"version": n,
+		"current": n == len(versions)+1,
+		"detail":  detail,
+	}
+	if n <= len(versions) {
+		data["edited_by"] = versions[n-1].EditedBy
+		data["edited_at"] = versions[n-1].EditedAt
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Isi prestasi versi " + strconv.Itoa(n),
+		"data":    data,
+	})
+}
+
+// DiffVersions godoc
+// @Summary      Bandingkan dua versi prestasi
+// @Description  Menampilkan field yang berubah antara dua versi. Default membandingkan versi lama terakhir dengan isi saat ini
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        from query int false "Versi asal"
+// @Param        to query int false "Versi tujuan"
+// @Success      200  {object}  map[string]interface{} "Daftar perubahan per field"
+// @Failure      400  {object}  map[string]interface{} "Nomor versi tidak valid atau belum pernah diedit"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi atau versi tidak ditemukan"
+// @Router       /achievements/{id}/versions/diff [get]
+func (s *AchievementService) DiffVersions(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
+	}
+
+	ctx := c.Context()
+	versions, err := s.versionRepo.GetByAchievementID(ctx, ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat versi"})
+	}
+	if len(versions) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Prestasi belum pernah diedit"})
+	}
+
+	current := len(versions) + 1
+	to, err1 := strconv.Atoi(c.Query("to", strconv.Itoa(current)))
+	from, err2 := strconv.Atoi(c.Query("from", strconv.Itoa(to-1)))
+	if err1 != nil || err2 != nil || from == to {
+		return c.Status(400).JSON(fiber.Map{"error": "Parameter from / to harus nomor versi yang berbeda"})
+	}
+
+	before, err := s.versionDetail(ctx, ref, from, versions)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil isi versi"})
+	}
+	after, err := s.versionDetail(ctx, ref, to, versions)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil isi versi"})
+	}
+	if before == nil || after == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Versi tidak ditemukan"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Perbandingan versi prestasi",
+		"data": fiber.Map{
+			"from":    from,
+			"to":      to,
+			"current": current,
+			"changes": DiffAchievementDetails(before, after),
+		},
+	})
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidTransition  = errors.New("perubahan status tidak diizinkan")
+	ErrTransitionConflict = errors.New("status prestasi sudah diubah oleh request lain, muat ulang data")
+)
+
+// achievementTransitions satu-satunya sumber aturan alur status prestasi
+var achievementTransitions = map[string][]string{
+	models.AchievementStatusDraft:         {models.AchievementStatusSubmitted},
+	models.AchievementStatusSubmitted:     {models.AchievementStatusVerified, models.AchievementStatusRejected, models.AchievementStatusNeedsRevision, models.AchievementStatusDraft},
+	models.AchievementStatusNeedsRevision: {models.AchievementStatusSubmitted},
+	models.AchievementStatusRejected:      {models.AchievementStatusDraft},
+	// verifikasi yang sudah diberikan bisa dicabut (revoke), poinnya dibalik di point_ledger
+	models.AchievementStatusVerified: {models.AchievementStatusRejected},
+}
+
+// NormalizeStatus menyeragamkan status lama ("Draft", " submitted ") ke bentuk kanonik huruf kecil
+func NormalizeStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+func CanTransition(from, to string) bool {
+	for _, allowed := range achievementTransitions[NormalizeStatus(from)] {
+		if allowed == NormalizeStatus(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanEditAchievement - isi prestasi hanya boleh diubah saat draft atau diminta revisi (withdraw / reopen dulu kalau perlu)
+func CanEditAchievement(status string) bool {
+	switch NormalizeStatus(status) {
+	case models.AchievementStatusDraft, models.AchievementStatusNeedsRevision:
+		return true
+	}
+	return false
+}
+
+// CanDeleteAchievement - prestasi yang sedang diproses atau sudah verified tidak boleh dihapus
+func CanDeleteAchievement(status string) bool {
+	switch NormalizeStatus(status) {
+	case models.AchievementStatusDraft, models.AchievementStatusNeedsRevision, models.AchievementStatusRejected:
+		return true
+	}
+	return false
+}
+
+// AchievementWorkflow menjalankan perubahan status prestasi dan mencatat riwayatnya
+type AchievementWorkflow struct {
+	achievementRepo repository.AchievementRepository
+}
+
+func NewAchievementWorkflow(achievementRepo repository.AchievementRepository) *AchievementWorkflow {
+	return &AchievementWorkflow{achievementRepo: achievementRepo}
+}
+
+// Transition memindahkan ref ke status to atas nama actorID. ref ikut diperbarui kalau berhasil.
+func (w *AchievementWorkflow) Transition(ctx context.Context, ref *models.AchievementReference, to string, actorID uuid.UUID, note string) error {
+	from := NormalizeStatus(ref.Status)
+	to = NormalizeStatus(to)
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	now := time.Now()
+	next := *ref
+	next.Status = to
+	switch to {
+	case models.AchievementStatusSubmitted:
+		next.SubmittedAt = now
+		next.VerifiedBy = nil
+		next.VerifiedAt = nil
+	case models.AchievementStatusVerified, models.AchievementStatusRejected, models.AchievementStatusNeedsRevision:
+		next.VerifiedBy = &actorID
+		next.VerifiedAt = &now
+		next.RejectionNote = nil
+		if to != models.AchievementStatusVerified {
+			next.RejectionNote = &note
+		}
+	case models.AchievementStatusDraft:
+		// catatan penolakan dibiarkan supaya mahasiswa tetap bisa membacanya saat revisi
+		next.VerifiedBy = nil
+		next.VerifiedAt = nil
+	}
+
+	change := &models.AchievementStatusChange{
+		AchievementID: ref.ID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ActorID:       &actorID,
+		Note:          note,
+	}
+	ok, err := w.achievementRepo.TransitionStatus(ctx, &next, from, change)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTransitionConflict
+	}
+
+	*ref = next
+	return nil
+}
+
+// Revert mengembalikan ref ke previous setelah langkah lanjutan transisi gagal. Tidak melewati aturan
+// achievementTransitions, tetapi tetap dicatat di riwayat dengan note sebagai alasannya
+func (w *AchievementWorkflow) Revert(ctx context.Context, ref *models.AchievementReference, previous models.AchievementReference, actorID uuid.UUID, note string) error {
+	change := &models.AchievementStatusChange{
+		AchievementID: ref.ID,
+		FromStatus:    NormalizeStatus(ref.Status),
+		ToStatus:      NormalizeStatus(previous.Status),
+		ActorID:       &actorID,
+		Note:          note,
+	}
+	ok, err := w.achievementRepo.TransitionStatus(ctx, &previous, change.FromStatus, change)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTransitionConflict
+	}
+
+	*ref = previous
+	return nil
+}
+
+func (w *AchievementWorkflow) History(ctx context.Context, achievementID uuid.UUID) ([]models.AchievementStatusChange, error) {
+	return w.achievementRepo.GetStatusHistory(ctx, achievementID)
+}
@@ -1,42 +1,156 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log"
 	"strings"
 	"time"
 	"uas-pelaporan-prestasi-mahasiswa/apps/models"
 	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+type AchievementOptions struct {
+	// MaxResubmissions berapa kali dosen boleh meminta revisi (needs_revision) untuk satu prestasi.
+	// Setelah batas tercapai dosen harus memilih verified atau rejected. 0 berarti tanpa batas
+	MaxResubmissions int
+}
+
 type AchievementService struct {
 	achievementRepo repository.AchievementRepository
 	studentRepo     repository.StudentsRepository
+	revisionRepo    repository.AchievementRevisionRepository
+	versionRepo     repository.AchievementVersionRepository
+	verifiers       *VerificationAuthority
+	workflow        *AchievementWorkflow
+	duplicates      *DuplicateDetector
+	team            *AchievementTeam
+	files           *FileStorage
+	validator       *AchievementValidator
+	scorer          *ScoringEngine
+	ledger          *PointLedger
+	options         AchievementOptions
 }
 
-func NewAchievementService(aRepo repository.AchievementRepository, sRepo repository.StudentsRepository) *AchievementService {
+func NewAchievementService(aRepo repository.AchievementRepository, sRepo repository.StudentsRepository, verifiers *VerificationAuthority, validator *AchievementValidator, scorer *ScoringEngine, ledger *PointLedger, revisionRepo repository.AchievementRevisionRepository, versionRepo repository.AchievementVersionRepository, memberRepo repository.AchievementMemberRepository, files *FileStorage, options AchievementOptions) *AchievementService {
 	return &AchievementService{
 		achievementRepo: aRepo,
 		studentRepo:     sRepo,
+		revisionRepo:    revisionRepo,
+		versionRepo:     versionRepo,
+		verifiers:       verifiers,
+		workflow:        NewAchievementWorkflow(aRepo),
+		duplicates:      NewDuplicateDetector(aRepo),
+		team:            NewAchievementTeam(memberRepo),
+		files:           files,
+		validator:       validator,
+		scorer:          scorer,
+		ledger:          ledger,
+		options:         options,
 	}
 }
 
+// ownedReference mengambil prestasi :id milik mahasiswa yang login. Kalau gagal, respons error sudah dikirim.
+func (s *AchievementService) ownedReference(c *fiber.Ctx, action string) (*models.AchievementReference, error) {
+	return s.studentReference(c, action, false)
+}
+
+// studentReference seperti ownedReference; allowMembers ikut mengizinkan anggota prestasi tim
+func (s *AchievementService) studentReference(c *fiber.Ctx, action string, allowMembers bool) (*models.AchievementReference, error) {
+	achievUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	ctx := c.Context()
+	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
+	if err != nil || ref == nil || ref.DeletedAt != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
+	}
+
+	student, err := s.studentRepo.GetByUserID(ctx, userUUID)
+	if err != nil || student == nil {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Data mahasiswa tidak ditemukan"})
+	}
+
+	if ref.StudentID == student.ID {
+		return ref, nil
+	}
+	if allowMembers {
+		isMember, err := s.team.IsMember(ctx, ref.ID, student.ID)
+		if err != nil {
+			return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek anggota tim"})
+		}
+		if isMember {
+			return ref, nil
+		}
+	}
+	return nil, c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak " + action + " prestasi ini"})
+}
+
+// readableReference - pemegang achievement:read boleh melihat prestasi siapa pun, selain itu hanya milik sendiri atau tim
+func (s *AchievementService) readableReference(c *fiber.Ctx) (*models.AchievementReference, error) {
+	if !middleware.HasPermission(c, "achievement:read") {
+		return s.studentReference(c, "melihat", true)
+	}
+
+	achievUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+	ref, err := s.achievementRepo.GetReferenceByID(c.Context(), achievUUID)
+	if err != nil || ref == nil || ref.DeletedAt != nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
+	}
+	return ref, nil
+}
+
+// validateDetail menjalankan AchievementValidator. false berarti respons error (400 per field / 500) sudah dikirim
+func (s *AchievementService) validateDetail(c *fiber.Ctx, detail *models.AchievementDetail) (bool, error) {
+	errs, err := s.validator.Validate(c.Context(), detail)
+	if err != nil {
+		return false, c.Status(500).JSON(fiber.Map{"error": "Gagal membaca skema tipe prestasi"})
+	}
+	if len(errs) > 0 {
+		return false, c.Status(400).JSON(fiber.Map{"error": "Data prestasi tidak valid", "errors": errs})
+	}
+	return true, nil
+}
+
+// respondTransitionError memetakan error AchievementWorkflow ke status HTTP
+func respondTransitionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidTransition):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, ErrTransitionConflict):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Gagal mengubah status prestasi: " + err.Error()})
+}
+
 // Create godoc
 // @Summary      Buat prestasi baru
-// @Description  Melaporkan prestasi baru ke sistem (khusus Mahasiswa). Data disimpan di MongoDB dan PostgreSQL
+// @Description  Melaporkan prestasi baru ke sistem (khusus Mahasiswa). Data disimpan di MongoDB dan PostgreSQL. Kalau mirip prestasi lain milik mahasiswa yang sama, respons berisi warning dan possible_duplicates (tetap tersimpan)
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request body models.CreateAchievementRequest true "Data prestasi (type, title, description, details, tags, attachments)"
 // @Success      201  {object}  map[string]interface{} "Prestasi berhasil dilaporkan"
-// @Failure      400  {object}  map[string]interface{} "Request tidak valid"
+// @Failure      400  {object}  map[string]interface{} "Request tidak valid, errors berisi kesalahan per field sesuai skema achievement_type"
 // @Failure      401  {object}  map[string]interface{} "Unauthorized"
 // @Failure      404  {object}  map[string]interface{} "Profil mahasiswa tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "File lampiran sudah dilampirkan ke prestasi lain"
 // @Failure      500  {object}  map[string]interface{} "Gagal menyimpan data"
 // @Router       /achievements [post]
 func (s *AchievementService) Create(c *fiber.Ctx) error {
@@ -45,10 +159,6 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if req.Title == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Judul prestasi wajib diisi"})
-	}
-
 	userVal := c.Locals("user_id")
 	if userVal == nil {
 		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
@@ -64,17 +174,27 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Profil mahasiswa tidak ditemukan. Silakan lengkapi biodata terlebih dahulu."})
 	}
 
+	// ID reference dibuat lebih dulu supaya URL lampiran bisa langsung diisi
+	achievementID := uuid.New()
+	attachments, err := s.resolveAttachments(c, userID, achievementID, req.Attachments, nil)
+	if attachments == nil {
+		return err
+	}
+
 	newDetail := &models.AchievementDetail{
 		StudentID:       student.ID.String(),
-		AchievementType: req.Type,
+		AchievementType: NormalizeAchievementType(req.Type),
 		Title:           req.Title,
 		Description:     req.Description,
 		Details:         req.Details,
-		Attachments:     req.Attachments,
+		Attachments:     attachments,
 		Tags:            req.Tags,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
+	if ok, err := s.validateDetail(c, newDetail); !ok {
+		return err
+	}
 
 	if err := s.achievementRepo.CreateDetail(ctx, newDetail); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal ke MongoDB: " + err.Error()})
@@ -82,17 +202,24 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 
 	mongoIDString := newDetail.ID.Hex()
 	newRef := &models.AchievementReference{
+		ID:                 achievementID,
 		StudentID:          student.ID,
 		MongoAchievementID: mongoIDString,
-		Status:             "draft",
+		Status:             models.AchievementStatusDraft,
 		SubmittedAt:        time.Now(),
 	}
 
 	if err := s.achievementRepo.CreateReference(ctx, newRef); err != nil {
+		s.discardCreated(ctx, nil, mongoIDString)
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal ke PostgreSQL: " + err.Error()})
 	}
+	// LinkToAchievement semua-atau-tidak-sama-sekali, jadi reference bisa dihapus tanpa ikut menghapus baris files
+	if err := s.linkAttachments(ctx, newRef.ID, attachments); err != nil {
+		s.discardCreated(ctx, &newRef.ID, mongoIDString)
+		return respondLinkError(c, err)
+	}
 
-	return c.Status(201).JSON(fiber.Map{
+	return c.Status(201).JSON(s.withDuplicateWarning(ctx, fiber.Map{
 		"message": "Prestasi berhasil dilaporkan",
 		"data": fiber.Map{
 			"id":                   newRef.ID,
@@ -100,68 +227,81 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
 			"status":               newRef.Status,
 			"detail":               newDetail,
 		},
-	})
+	}, newDetail, newRef))
+}
+
+// discardCreated menghapus reference (kalau sudah tersimpan) dan detail dari Create yang gagal di tengah jalan
+func (s *AchievementService) discardCreated(ctx context.Context, refID *uuid.UUID, mongoID string) {
+	if refID != nil {
+		if err := s.achievementRepo.DeleteReference(ctx, *refID); err != nil {
+			log.Printf("⚠️  Gagal menghapus reference prestasi %s yang batal dibuat: %v", *refID, err)
+			return // detail dibiarkan supaya reference tidak menunjuk dokumen yang hilang
+		}
+	}
+	if err := s.achievementRepo.DeleteDetail(ctx, mongoID); err != nil {
+		log.Printf("⚠️  Gagal menghapus detail prestasi %s yang batal dibuat: %v", mongoID, err)
+	}
 }
 
 // UploadAttachment godoc
 // @Summary      Upload lampiran prestasi
-// @Description  Mengunggah file lampiran untuk prestasi (jpg, png, pdf). Maks 10MB
+// @Description  Mengunggah file lampiran untuk prestasi (jpg, png, pdf). Tipe dicek dari isi file (magic bytes), ukuran maks FILE_MAX_SIZE_MB (default 10MB) dan total per user FILE_USER_QUOTA_MB. PDF berisi JavaScript ditolak dan file dipindai virus kalau VIRUS_SCANNER aktif. File disimpan berdasarkan SHA-256 isinya, nama asli hanya dicatat sebagai metadata
 // @Tags         Achievements
 // @Accept       multipart/form-data
 // @Produce      json
 // @Security     BearerAuth
 // @Param        file formData file true "File yang akan di-upload (Maks 10MB, Tipe: jpg/png/pdf)"
 // @Success      200  {object}  map[string]interface{} "File berhasil diupload"
-// @Failure      400  {object}  map[string]interface{} "Format file tidak diizinkan"
+// @Failure      400  {object}  map[string]interface{} "Format file tidak diizinkan, isi tidak sesuai ekstensi, atau PDF tidak aman"
+// @Failure      403  {object}  map[string]interface{} "Kuota penyimpanan habis"
+// @Failure      413  {object}  map[string]interface{} "Ukuran file melebihi batas"
+// @Failure      422  {object}  map[string]interface{} "File terdeteksi mengandung malware"
 // @Failure      500  {object}  map[string]interface{} "Gagal menyimpan file"
+// @Failure      503  {object}  map[string]interface{} "Pemindai virus tidak tersedia"
 // @Router       /achievements/upload [post]
 func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
 	file, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Gagal mengambil file. Pastikan key-nya 'file'"})
 	}
 
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".pdf": true}
-	if !allowedExts[ext] {
-		return c.Status(400).JSON(fiber.Map{"error": "Format file tidak diizinkan (hanya jpg, png, pdf)"})
-	}
-
-	filename := fmt.Sprintf("%d-%s", time.Now().Unix(), file.Filename)
-	savePath := fmt.Sprintf("./uploads/%s", filename)
-
-	if _, err := os.Stat("./uploads"); os.IsNotExist(err) {
-		os.Mkdir("./uploads", 0755)
-	}
-	if err := c.SaveFile(file, savePath); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan file ke server"})
+	stored, err := s.files.Save(c.Context(), userUUID, file)
+	if err != nil {
+		return respondFileError(c, err)
 	}
 
-	fileURL := fmt.Sprintf("http://localhost:3000/uploads/%s", filename)
-
 	return c.Status(200).JSON(fiber.Map{
 		"message": "File berhasil diupload",
 		"data": fiber.Map{
-			"fileName": file.Filename,
-			"fileUrl":  fileURL,
-			"fileType": file.Header["Content-Type"][0],
+			"fileId":       stored.ID,
+			"fileName":     stored.OriginalName,
+			"fileUrl":      s.files.DownloadURL(stored.ID),
+			"fileType":     stored.MimeType,
+			"thumbnailUrl": s.files.ThumbnailURL(stored),
+			"size":         stored.Size,
+			"hash":         stored.SHA256,
 		},
 	})
 }
 
 // GetAll godoc
 // @Summary      Dapatkan semua prestasi
-// @Description  Mengambil daftar seluruh prestasi dengan filter status opsional (draft, submitted, verified, rejected)
+// @Description  Mengambil daftar seluruh prestasi dengan filter status opsional (draft, submitted, needs_revision, verified, rejected)
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        status query string false "Filter berdasarkan status (draft/submitted/verified/rejected)"
+// @Param        status query string false "Filter berdasarkan status (draft/submitted/needs_revision/verified/rejected)"
 // @Success      200  {object}  map[string]interface{} "Daftar prestasi"
 // @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
 // @Router       /achievements [get]
 func (s *AchievementService) GetAll(c *fiber.Ctx) error {
-	status := c.Query("status")
+	status := NormalizeStatus(c.Query("status"))
 
 	refs, err := s.achievementRepo.GetAll(c.Context(), status)
 	if err != nil {
@@ -184,24 +324,17 @@ func (s *AchievementService) GetAll(c *fiber.Ctx) error {
 // @Param        id path string true "ID prestasi (UUID)"
 // @Success      200  {object}  map[string]interface{} "Detail prestasi (info dari PostgreSQL, detail dari MongoDB)"
 // @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan prestasi milik sendiri"
 // @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
 // @Router       /achievements/{id} [get]
 func (s *AchievementService) GetByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	achievementUUID, err := uuid.Parse(id)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
-	}
-
-	ctx := c.Context()
-
-	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievementUUID)
-	if err != nil || ref == nil {
-		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
 	}
 
 	mongoID := ref.MongoAchievementID
-	detail, err := s.achievementRepo.GetDetailByID(ctx, mongoID)
+	detail, err := s.achievementRepo.GetDetailByID(c.Context(), mongoID)
 	var detailData interface{} = detail
 	if err != nil {
 		detailData = "Detail data di MongoDB tidak ditemukan atau rusak."
@@ -219,16 +352,18 @@ func (s *AchievementService) GetByID(c *fiber.Ctx) error {
 
 // Verify godoc
 // @Summary      Verifikasi prestasi
-// @Description  Memverifikasi atau menolak prestasi mahasiswa (khusus Dosen). Wajib sertakan alasan jika ditolak
+// @Description  Memverifikasi, menolak, atau meminta revisi (needs_revision) prestasi berstatus submitted. Saat verified poin dihitung otomatis dan rinciannya disimpan di score_breakdown; untuk prestasi tim poin dibagi ke anggota sesuai point_share (point_allocations). Hanya dosen wali mahasiswa atau dosen penerima delegasi aktif. Wajib sertakan alasan jika ditolak, dan catatan atau komentar per field jika meminta revisi
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "ID prestasi (UUID)"
-// @Param        request body models.VerifyAchievementRequest true "Status verifikasi (verified/rejected) dan catatan"
+// @Param        request body models.VerifyAchievementRequest true "Status verifikasi (verified/rejected/needs_revision), catatan dan komentar per field"
 // @Success      200  {object}  map[string]interface{} "Status berhasil diperbarui"
-// @Failure      400  {object}  map[string]interface{} "ID atau status tidak valid"
-// @Failure      500  {object}  map[string]interface{} "Gagal update status"
+// @Failure      400  {object}  map[string]interface{} "ID atau status tidak valid, prestasi belum submitted, atau batas revisi tercapai"
+// @Failure      403  {object}  map[string]interface{} "Bukan dosen wali mahasiswa dan tidak punya delegasi"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal update status, verifikasi dibatalkan tanpa perubahan"
 // @Router       /achievements/{id}/verify [patch]
 func (s *AchievementService) Verify(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -237,112 +372,318 @@ func (s *AchievementService) Verify(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	dosenIDStr := c.Locals("user_id").(string)
-	dosenUUID, _ := uuid.Parse(dosenIDStr)
+	dosenUUID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
 
 	var req models.VerifyAchievementRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
 	}
 
-	if req.Status != "verified" && req.Status != "rejected" {
-		return c.Status(400).JSON(fiber.Map{"error": "Status harus sesuai dengan format 'verified' atau 'rejected'"})
+	req.Status = NormalizeStatus(req.Status)
+	switch req.Status {
+	case models.AchievementStatusVerified, models.AchievementStatusRejected, models.AchievementStatusNeedsRevision:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "Status harus sesuai dengan format 'verified', 'rejected' atau 'needs_revision'"})
 	}
 
-	if req.Status == "rejected" && req.Notes == "" {
+	if req.Status == models.AchievementStatusRejected && req.Notes == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "Wajib sertakan alasan penolakan"})
 	}
+	if req.Status == models.AchievementStatusNeedsRevision && req.Notes == "" && len(req.FieldComments) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Wajib sertakan catatan atau komentar per field untuk permintaan revisi"})
+	}
+	for field := range req.FieldComments {
+		if !isRevisableField(field) {
+			return c.Status(400).JSON(fiber.Map{"error": "Field '" + field + "' tidak dikenal"})
+		}
+	}
 
 	ctx := c.Context()
 	now := time.Now()
 
-	updateData := &models.AchievementReference{
-		ID:            achievUUID,
-		Status:        req.Status,
-		VerifiedBy:    &dosenUUID,
-		VerifiedAt:    &now,
-		RejectionNote: &req.Notes,
+	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
+	if err != nil || ref == nil || ref.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
 	}
 
-	err = s.achievementRepo.UpdateStatus(ctx, updateData)
+	if NormalizeStatus(ref.Status) != models.AchievementStatusSubmitted {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi dengan status submitted yang bisa diverifikasi"})
+	}
+
+	student, err := s.studentRepo.GetByID(ctx, ref.StudentID)
+	if err != nil || student == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Mahasiswa pemilik prestasi tidak ditemukan"})
+	}
 
+	_, delegation, err := s.verifiers.Authorize(ctx, dosenUUID, student, now)
+	if errors.Is(err, ErrNotLecturer) || errors.Is(err, ErrNotAdvisor) {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal update status: " + err.Error()})
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek hak verifikasi"})
 	}
 
-	return c.JSON(fiber.Map{
+	revisions, err := s.revisionRepo.GetByAchievementID(ctx, ref.ID.String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat revisi"})
+	}
+	if req.Status == models.AchievementStatusNeedsRevision && s.revisionLimitReached(revisions) {
+		return c.Status(400).JSON(fiber.Map{"error": "Batas permintaan revisi sudah tercapai, pilih verified atau rejected"})
+	}
+
+	// poin, review dan alokasi disiapkan sebelum status berubah supaya kegagalan tidak meninggalkan verifikasi setengah jadi
+	var score *models.ScoreBreakdown
+	var detail *models.AchievementDetail
+	var allocations []PointAllocation
+	if req.Status == models.AchievementStatusVerified {
+		detail, err = s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+		if err != nil || detail == nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+		}
+		if score, err = s.scorer.Score(ctx, detail); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung poin prestasi: " + err.Error()})
+		}
+		if allocations, err = s.team.Allocate(ctx, ref, score.Total); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal menghitung pembagian poin anggota tim"})
+		}
+	}
+
+	// prestasi lama yang disubmit sebelum ada snapshot revisi tidak punya ronde untuk diberi review
+	var round int
+	var previousReview *models.AchievementReview
+	if len(revisions) > 0 {
+		round = revisions[len(revisions)-1].Round
+		previousReview = revisions[len(revisions)-1].Review
+		review := &models.AchievementReview{
+			Status:        req.Status,
+			Notes:         req.Notes,
+			FieldComments: req.FieldComments,
+			ReviewerID:    dosenUUID.String(),
+			ReviewedAt:    now,
+		}
+		if err := s.revisionRepo.SetReview(ctx, ref.ID.String(), round, review); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan review"})
+		}
+	}
+	restoreReview := func() error {
+		if len(revisions) == 0 {
+			return nil
+		}
+		return s.revisionRepo.SetReview(ctx, ref.ID.String(), round, previousReview)
+	}
+
+	previous := *ref
+	if err := s.workflow.Transition(ctx, ref, req.Status, dosenUUID, req.Notes); err != nil {
+		if restoreErr := restoreReview(); restoreErr != nil {
+			log.Printf("⚠️  Gagal mengembalikan review prestasi %s: %v", ref.ID, restoreErr)
+			return c.Status(500).JSON(fiber.Map{"error": "Verifikasi gagal dan review tidak bisa dikembalikan, hubungi admin"})
+		}
+		return respondTransitionError(c, err)
+	}
+
+	// rollback membalik poin di ledger, mengembalikan status ke submitted dan memulihkan review.
+	// Semua langkah tetap dicoba walaupun salah satunya gagal
+	rollback := func(note string) error {
+		var errs []error
+		if err := s.ledger.Reverse(ctx, ref, dosenUUID, note); err != nil {
+			errs = append(errs, fmt.Errorf("reverse ledger: %w", err))
+		}
+		if err := s.workflow.Revert(ctx, ref, previous, dosenUUID, note); err != nil {
+			errs = append(errs, fmt.Errorf("revert status: %w", err))
+		}
+		if err := restoreReview(); err != nil {
+			errs = append(errs, fmt.Errorf("restore review: %w", err))
+		}
+		return errors.Join(errs...)
+	}
+
+	// score_breakdown baru disimpan setelah status dan ledger berhasil, jadi kegagalan sebelumnya tidak meninggalkan poin di MongoDB
+	if score != nil {
+		failure, note := "", ""
+		if err := s.ledger.Award(ctx, ref, detail, score, allocations, dosenUUID); err != nil {
+			failure, note = "Poin gagal dicatat di ledger, verifikasi dibatalkan. Silakan coba lagi", "verifikasi dibatalkan: poin gagal dicatat"
+		} else if err := s.achievementRepo.UpdateScore(ctx, ref.MongoAchievementID, score); err != nil {
+			failure, note = "Gagal menyimpan poin prestasi, verifikasi dibatalkan. Silakan coba lagi", "verifikasi dibatalkan: poin gagal disimpan"
+		}
+		if failure != "" {
+			if err := rollback(note); err != nil {
+				log.Printf("⚠️  Gagal membatalkan verifikasi prestasi %s: %v", ref.ID, err)
+				return c.Status(500).JSON(fiber.Map{"error": "Verifikasi gagal dan tidak bisa dibatalkan sepenuhnya, hubungi admin untuk mengecek status dan poin prestasi"})
+			}
+			return c.Status(500).JSON(fiber.Map{"error": failure})
+		}
+	}
+
+	response := fiber.Map{
 		"message": "Status prestasi berhasil diperbarui",
 		"status":  req.Status,
-	})
+	}
+	if score != nil {
+		response["points"] = score.Total
+		response["point_allocations"] = allocations
+		response["score_breakdown"] = score
+	}
+	if delegation != nil {
+		response["delegation_id"] = delegation.ID
+	}
+	return c.JSON(response)
 }
 
-// Update godoc
-// @Summary      Update prestasi
-// @Description  Memperbarui data prestasi (khusus pemilik, status belum verified)
+// Revoke godoc
+// @Summary      Cabut verifikasi prestasi
+// @Description  Mengubah prestasi verified menjadi rejected, misal karena ternyata data tidak benar. Poin yang sudah diberikan dibalik dengan entri reversal di point_ledger. Hanya dosen wali mahasiswa atau dosen penerima delegasi aktif, wajib sertakan alasan
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "ID prestasi (UUID)"
-// @Param        request body models.CreateAchievementRequest true "Data prestasi yang akan diupdate"
-// @Success      200  {object}  map[string]interface{} "Data berhasil diperbarui"
-// @Failure      400  {object}  map[string]interface{} "ID tidak valid atau prestasi sudah verified"
-// @Failure      403  {object}  map[string]interface{} "Tidak berhak mengedit"
+// @Param        request body models.AchievementTransitionRequest true "Alasan pencabutan"
+// @Success      200  {object}  map[string]interface{} "Verifikasi dicabut"
+// @Failure      400  {object}  map[string]interface{} "Alasan kosong atau prestasi belum verified"
+// @Failure      403  {object}  map[string]interface{} "Bukan dosen wali mahasiswa dan tidak punya delegasi"
 // @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
-// @Failure      500  {object}  map[string]interface{} "Gagal update data"
-// @Router       /achievements/{id} [put]
-func (s *AchievementService) Update(c *fiber.Ctx) error {
-
-	id := c.Params("id")
-	achievUUID, err := uuid.Parse(id)
+// @Failure      409  {object}  map[string]interface{} "Status sudah diubah request lain"
+// @Router       /achievements/{id}/revoke [patch]
+func (s *AchievementService) Revoke(c *fiber.Ctx) error {
+	achievUUID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userUUID, _ := uuid.Parse(userIDStr)
+	dosenUUID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.AchievementTransitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Wajib sertakan alasan pencabutan verifikasi"})
+	}
 
 	ctx := c.Context()
-
 	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
-	if err != nil || ref == nil {
+	if err != nil || ref == nil || ref.DeletedAt != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
 	}
+	if NormalizeStatus(ref.Status) != models.AchievementStatusVerified {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi berstatus verified yang bisa dicabut verifikasinya"})
+	}
 
-	student, err := s.studentRepo.GetByUserID(ctx, userUUID)
+	student, err := s.studentRepo.GetByID(ctx, ref.StudentID)
 	if err != nil || student == nil {
-		return c.Status(403).JSON(fiber.Map{"error": "Data mahasiswa tidak ditemukan"})
+		return c.Status(404).JSON(fiber.Map{"error": "Mahasiswa pemilik prestasi tidak ditemukan"})
+	}
+
+	_, _, err = s.verifiers.Authorize(ctx, dosenUUID, student, time.Now())
+	if errors.Is(err, ErrNotLecturer) || errors.Is(err, ErrNotAdvisor) {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek hak verifikasi"})
 	}
 
-	if ref.StudentID != student.ID {
-		return c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak mengedit prestasi ini"})
+	previous := *ref
+	if err := s.workflow.Transition(ctx, ref, models.AchievementStatusRejected, dosenUUID, req.Note); err != nil {
+		return respondTransitionError(c, err)
+	}
+	// status dikembalikan ke verified kalau poin gagal dibalik. Reverse memakai saldo terkini,
+	// jadi reversal yang sempat tertulis sebagian dilanjutkan saat pencabutan diulang
+	if err := s.ledger.Reverse(ctx, ref, dosenUUID, req.Note); err != nil {
+		if revertErr := s.workflow.Revert(ctx, ref, previous, dosenUUID, "pencabutan dibatalkan: poin gagal dibalik"); revertErr != nil {
+			log.Printf("⚠️  Gagal membatalkan pencabutan prestasi %s: %v (reverse ledger: %v)", ref.ID, revertErr, err)
+			return c.Status(500).JSON(fiber.Map{"error": "Pencabutan gagal dan tidak bisa dibatalkan sepenuhnya, hubungi admin untuk mengecek status dan poin prestasi"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Poin gagal dibalik di ledger, pencabutan dibatalkan. Silakan coba lagi"})
 	}
 
-	if ref.Status == "verified" {
-		return c.Status(400).JSON(fiber.Map{"error": "Prestasi yang sudah diverifikasi tidak bisa diedit"})
+	return c.JSON(fiber.Map{
+		"message": "Verifikasi prestasi berhasil dicabut",
+		"status":  ref.Status,
+	})
+}
+
+// Update godoc
+// @Summary      Update prestasi
+// @Description  Memperbarui data prestasi (khusus pemilik, hanya saat status draft atau needs_revision). Isi sebelumnya disimpan sebagai versi baru, lihat /achievements/{id}/versions
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.CreateAchievementRequest true "Data prestasi yang akan diupdate"
+// @Success      200  {object}  map[string]interface{} "Data berhasil diperbarui"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid, prestasi bukan draft / needs_revision, atau data tidak sesuai skema"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak mengedit"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "File lampiran sudah dilampirkan ke prestasi lain"
+// @Failure      500  {object}  map[string]interface{} "Gagal update data"
+// @Router       /achievements/{id} [put]
+func (s *AchievementService) Update(c *fiber.Ctx) error {
+	ref, err := s.ownedReference(c, "mengedit")
+	if ref == nil {
+		return err
 	}
 
+	if !CanEditAchievement(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi berstatus draft atau needs_revision yang bisa diedit. Tarik (withdraw) atau buka ulang (reopen) dulu"})
+	}
+	ctx := c.Context()
+
 	var req models.CreateAchievementRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
 	}
 
+	previous, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || previous == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+	userUUID, _ := currentUserID(c)
+	attachments, err := s.resolveAttachments(c, userUUID, ref.ID, req.Attachments, previous.Attachments)
+	if attachments == nil {
+		return err
+	}
+
 	updateDetail := &models.AchievementDetail{
 		Title:           req.Title,
-		AchievementType: req.Type,
+		AchievementType: NormalizeAchievementType(req.Type),
 		Description:     req.Description,
 		Details:         req.Details,
-		Attachments:     req.Attachments,
+		Attachments:     attachments,
 		Tags:            req.Tags,
 	}
+	if ok, err := s.validateDetail(c, updateDetail); !ok {
+		return err
+	}
+	if len(DiffAchievementDetails(previous, updateDetail)) == 0 {
+		return c.JSON(fiber.Map{
+			"message": "Tidak ada perubahan pada data prestasi",
+		})
+	}
+
+	// versi lama disimpan dulu, kalau update gagal paling hanya ada satu versi yang sama dengan isi sekarang
+	version, err := s.archiveVersion(ctx, ref, previous, userUUID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan versi sebelumnya"})
+	}
 
 	err = s.achievementRepo.UpdateDetail(ctx, ref.MongoAchievementID, updateDetail)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal update data: " + err.Error()})
 	}
+	if err := s.linkAttachments(ctx, ref.ID, attachments); err != nil {
+		return respondLinkError(c, err)
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Data prestasi berhasil diperbarui",
+		"version": version.Version + 1,
 	})
 }
 
@@ -374,7 +715,7 @@ func (s *AchievementService) GetAllMongoData(c *fiber.Ctx) error {
 
 // GetMyAchievment godoc
 // @Summary      Dapatkan prestasi saya
-// @Description  Mengambil daftar prestasi milik mahasiswa yang sedang login
+// @Description  Mengambil daftar prestasi milik mahasiswa yang sedang login, termasuk prestasi tim yang undangannya sudah diterima
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -405,6 +746,21 @@ func (s *AchievementService) GetMyAchievment(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data prestasi: " + err.Error()})
 	}
 
+	// prestasi tim yang dilaporkan anggota lain ikut tampil setelah undangannya diterima
+	memberships, err := s.team.memberRepo.GetByStudentID(ctx, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil prestasi tim: " + err.Error()})
+	}
+	for _, m := range memberships {
+		if m.Status != models.TeamMemberAccepted {
+			continue
+		}
+		ref, err := s.achievementRepo.GetReferenceByID(ctx, m.AchievementID)
+		if err == nil && ref != nil && ref.DeletedAt == nil && ref.StudentID != student.ID {
+			refs = append(refs, *ref)
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Berhasil mengambil daftar prestasi Anda",
 		"data":    refs,
@@ -413,49 +769,30 @@ func (s *AchievementService) GetMyAchievment(c *fiber.Ctx) error {
 
 // Delete godoc
 // @Summary      Hapus prestasi (soft delete)
-// @Description  Menghapus prestasi dengan soft delete (khusus pemilik, status belum verified)
+// @Description  Menghapus prestasi dengan soft delete (khusus pemilik, status draft, needs_revision atau rejected)
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "ID prestasi (UUID)"
 // @Success      200  {object}  map[string]interface{} "Prestasi berhasil dihapus"
-// @Failure      400  {object}  map[string]interface{} "ID tidak valid atau prestasi sudah verified"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid atau prestasi sedang/sudah diverifikasi"
 // @Failure      403  {object}  map[string]interface{} "Tidak berhak menghapus"
 // @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
 // @Failure      500  {object}  map[string]interface{} "Gagal menghapus prestasi"
 // @Router       /achievements/{id} [delete]
 func (s *AchievementService) Delete(c *fiber.Ctx) error {
-	id := c.Params("id")
-	achievUUID, err := uuid.Parse(id)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
-	}
-
-	userIDStr := c.Locals("user_id").(string)
-	userUUID, _ := uuid.Parse(userIDStr)
-
-	ctx := c.Context()
-
-	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
-	if err != nil || ref == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
-	}
-
-	student, err := s.studentRepo.GetByUserID(ctx, userUUID)
-	if err != nil || student == nil {
-		return c.Status(403).JSON(fiber.Map{"error": "Data mahasiswa tidak ditemukan"})
-	}
-
-	if ref.StudentID != student.ID {
-		return c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak menghapus prestasi ini"})
+	ref, err := s.ownedReference(c, "menghapus")
+	if ref == nil {
+		return err
 	}
 
-	if ref.Status == "verified" {
-		return c.Status(400).JSON(fiber.Map{"error": "Prestasi yang sudah diverifikasi tidak bisa dihapus"})
+	if !CanDeleteAchievement(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{"error": "Prestasi yang sedang diverifikasi atau sudah diverifikasi tidak bisa dihapus"})
 	}
 
-	err = s.achievementRepo.SoftDelete(ctx, achievUUID)
+	ctx := c.Context()
+	err = s.achievementRepo.SoftDelete(ctx, ref.ID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus prestasi: " + err.Error()})
 	}
@@ -467,54 +804,158 @@ func (s *AchievementService) Delete(c *fiber.Ctx) error {
 
 // Submit godoc
 // @Summary      Submit prestasi untuk verifikasi
-// @Description  Mengubah status prestasi dari draft menjadi submitted (khusus pemilik)
+// @Description  Mengubah status prestasi dari draft atau needs_revision menjadi submitted (khusus pemilik). Setiap submit menyimpan snapshot isi prestasi sebagai ronde revisi baru. Kemungkinan duplikat dilaporkan lewat warning dan possible_duplicates
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "ID prestasi (UUID)"
 // @Success      200  {object}  map[string]interface{} "Prestasi berhasil disubmit"
-// @Failure      400  {object}  map[string]interface{} "ID tidak valid atau status bukan draft"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid, status bukan draft / needs_revision, atau data belum lengkap sesuai skema"
 // @Failure      403  {object}  map[string]interface{} "Tidak berhak submit"
 // @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Status sudah diubah request lain"
 // @Failure      500  {object}  map[string]interface{} "Gagal submit prestasi"
 // @Router       /achievements/{id}/submit [patch]
 func (s *AchievementService) Submit(c *fiber.Ctx) error {
-	id := c.Params("id")
-	achievUUID, err := uuid.Parse(id)
+	ref, err := s.ownedReference(c, "submit")
+	if ref == nil {
+		return err
+	}
+
+	ctx := c.Context()
+	if !CanTransition(ref.Status, models.AchievementStatusSubmitted) {
+		return respondTransitionError(c, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, NormalizeStatus(ref.Status), models.AchievementStatusSubmitted))
+	}
+	detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || detail == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Detail prestasi di MongoDB tidak ditemukan"})
+	}
+	// data lama dari sebelum ada validasi harus dilengkapi dulu sebelum bisa diverifikasi
+	if ok, err := s.validateDetail(c, detail); !ok {
+		return err
+	}
+	if err := s.team.CheckReady(ctx, ref.ID); errors.Is(err, ErrTeamNotReady) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	} else if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek anggota tim"})
+	}
+
+	userUUID, _ := currentUserID(c)
+	if err := s.workflow.Transition(ctx, ref, models.AchievementStatusSubmitted, userUUID, ""); err != nil {
+		return respondTransitionError(c, err)
+	}
+
+	revision, err := s.snapshotRevision(ctx, ref, detail, userUUID)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+		return c.Status(500).JSON(fiber.Map{"error": "Prestasi sudah disubmit tetapi snapshot revisi gagal disimpan"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userUUID, _ := uuid.Parse(userIDStr)
+	return c.JSON(s.withDuplicateWarning(ctx, fiber.Map{
+		"message": "Prestasi berhasil disubmit untuk verifikasi",
+		"round":   revision.Round,
+	}, detail, ref))
+}
 
-	ctx := c.Context()
+// Withdraw godoc
+// @Summary      Tarik kembali prestasi
+// @Description  Mengembalikan prestasi berstatus submitted ke draft sebelum diverifikasi (khusus pemilik)
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.AchievementTransitionRequest false "Catatan opsional"
+// @Success      200  {object}  map[string]interface{} "Prestasi kembali menjadi draft"
+// @Failure      400  {object}  map[string]interface{} "Status bukan submitted"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Status sudah diubah request lain"
+// @Router       /achievements/{id}/withdraw [patch]
+func (s *AchievementService) Withdraw(c *fiber.Ctx) error {
+	return s.returnToDraft(c, models.AchievementStatusSubmitted, "Prestasi berhasil ditarik kembali menjadi draft")
+}
 
-	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievUUID)
-	if err != nil || ref == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Data prestasi tidak ditemukan"})
+// Reopen godoc
+// @Summary      Buka ulang prestasi yang ditolak
+// @Description  Mengembalikan prestasi berstatus rejected ke draft supaya bisa diperbaiki dan disubmit ulang (khusus pemilik)
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Param        request body models.AchievementTransitionRequest false "Catatan opsional"
+// @Success      200  {object}  map[string]interface{} "Prestasi kembali menjadi draft"
+// @Failure      400  {object}  map[string]interface{} "Status bukan rejected"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Status sudah diubah request lain"
+// @Router       /achievements/{id}/reopen [patch]
+func (s *AchievementService) Reopen(c *fiber.Ctx) error {
+	return s.returnToDraft(c, models.AchievementStatusRejected, "Prestasi dibuka ulang, silakan perbaiki lalu submit kembali")
+}
+
+// returnToDraft dipakai Withdraw dan Reopen; from memastikan endpoint tidak dipakai untuk transisi lain
+func (s *AchievementService) returnToDraft(c *fiber.Ctx, from string, message string) error {
+	ref, err := s.ownedReference(c, "mengubah")
+	if ref == nil {
+		return err
 	}
 
-	student, err := s.studentRepo.GetByUserID(ctx, userUUID)
-	if err != nil || student == nil {
-		return c.Status(403).JSON(fiber.Map{"error": "Data mahasiswa tidak ditemukan"})
+	var req models.AchievementTransitionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+		}
+	}
+
+	if NormalizeStatus(ref.Status) != from {
+		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi berstatus " + from + " yang bisa dikembalikan ke draft"})
 	}
 
-	if ref.StudentID != student.ID {
-		return c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak submit prestasi ini"})
+	userUUID, _ := currentUserID(c)
+	if err := s.workflow.Transition(c.Context(), ref, models.AchievementStatusDraft, userUUID, req.Note); err != nil {
+		return respondTransitionError(c, err)
 	}
 
-	if ref.Status != "Draft" && ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "Hanya prestasi dengan status draft yang bisa disubmit"})
+	return c.JSON(fiber.Map{
+		"message": message,
+		"status":  ref.Status,
+	})
+}
+
+// GetHistory godoc
+// @Summary      Riwayat status prestasi
+// @Description  Mengambil seluruh perubahan status prestasi (aktor, status asal/tujuan, catatan, waktu). Mahasiswa hanya bisa melihat prestasi miliknya
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID prestasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Riwayat status"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak"
+// @Failure      404  {object}  map[string]interface{} "Prestasi tidak ditemukan"
+// @Router       /achievements/{id}/history [get]
+func (s *AchievementService) GetHistory(c *fiber.Ctx) error {
+	ref, err := s.readableReference(c)
+	if ref == nil {
+		return err
 	}
 
-	err = s.achievementRepo.Submit(ctx, achievUUID)
+	history, err := s.workflow.History(c.Context(), ref.ID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal submit prestasi: " + err.Error()})
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat status"})
+	}
+	if history == nil {
+		history = []models.AchievementStatusChange{}
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Prestasi berhasil disubmit untuk verifikasi",
+		"message": "Riwayat status prestasi",
+		"data": fiber.Map{
+			"status":  ref.Status,
+			"history": history,
+		},
 	})
 }
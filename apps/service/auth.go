@@ -1,8 +1,13 @@
 package service
 
 import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
 	"uas-pelaporan-prestasi-mahasiswa/apps/models"
 	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
 	"uas-pelaporan-prestasi-mahasiswa/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,75 +17,102 @@ import (
 type AuthService struct {
 	userRepo       repository.UserRepository
 	permissionRepo repository.PermissionRepository
+	sessions       *SessionManager
+	loginGuard     *LoginGuard
+	mfa            *MFAService
 }
 
-func NewAuthService(userRepo repository.UserRepository, permissionRepo repository.PermissionRepository) *AuthService {
-	return &AuthService{userRepo: userRepo, permissionRepo: permissionRepo}
+// NewAuthService - loginGuard boleh nil (tanpa pembatasan percobaan login),
+// mfa boleh nil (login langsung dapat token tanpa langkah kedua)
+func NewAuthService(userRepo repository.UserRepository, permissionRepo repository.PermissionRepository, sessions *SessionManager, loginGuard *LoginGuard, mfa *MFAService) *AuthService {
+	return &AuthService{userRepo: userRepo, permissionRepo: permissionRepo, sessions: sessions, loginGuard: loginGuard, mfa: mfa}
 }
 
-// Register godoc
-// @Summary      Registrasi pengguna baru
-// @Description  Mendaftarkan pengguna baru ke dalam sistem dengan username, email, password, dan role
-// @Tags         Auth
-// @Accept       json
-// @Produce      json
-// @Param        request body models.RegisterRequest true "Data registrasi pengguna"
-// @Success      201  {object}  map[string]interface{} "User berhasil dibuat"
-// @Failure      400  {object}  map[string]interface{} "Request tidak valid atau username sudah ada"
-// @Failure      500  {object}  map[string]interface{} "Gagal menyimpan user"
-// @Router       /auth/Register [post]
-func (s *AuthService) Register(c *fiber.Ctx) error {
-	var req models.RegisterRequest
+// ErrCodeAccountInactive dikirim di field "code" supaya client bisa membedakan
+// akun nonaktif dengan password salah
+const ErrCodeAccountInactive = "ACCOUNT_INACTIVE"
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+// deviceLabel dipakai untuk menamai sesi di GET /auth/sessions
+func deviceLabel(c *fiber.Ctx, label string) string {
+	if label == "" {
+		label = c.Get("User-Agent")
+	}
+	if len(label) > 100 {
+		label = label[:100]
 	}
+	return label
+}
 
-	ctx := c.Context()
-	existUser, _ := s.userRepo.GetByUsernameOrEmail(ctx, req.Username)
+func (s *AuthService) recordLogin(c *fiber.Ctx, user *models.User, login string, outcome string) {
+	recordLoginEvent(c, s.loginGuard, user, login, outcome)
+}
 
-	if existUser != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "username atau email udah ada / terdaftar"})
+// recordLoginEvent mencatat event login untuk audit kalau login guard aktif
+func recordLoginEvent(c *fiber.Ctx, guard *LoginGuard, user *models.User, login string, outcome string) {
+	if guard == nil {
+		return
 	}
-
-	hashedPassword, err := utils.HashPassword(req.Password)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "password gagal di hash"})
+	event := models.LoginEvent{
+		Login:     login,
+		IPAddress: c.IP(),
+		UserAgent: deviceLabel(c, ""),
+		Outcome:   outcome,
 	}
-
-	roleUUID, err := uuid.Parse(req.RoleID)
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "Role ID tidak valid"})
+	if user != nil {
+		event.UserID = &user.ID
 	}
+	guard.Record(c.Context(), event)
+}
 
-	newUser := &models.User{
-		Username:     req.Username,
-		Email:        req.Email,
-		PasswordHash: hashedPassword,
-		FullName:     req.FullName,
-		RoleID:       roleUUID,
-	}
+func respondLoginBlocked(c *fiber.Ctx, block *LoginBlock) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(block.RetryAfter.Seconds()))))
+	return c.Status(block.Status).JSON(fiber.Map{"error": block.Message, "code": block.Code})
+}
+
+// loginSuccessData menerbitkan sesi baru dan menyusun isi "data" respons login.
+// Dipakai oleh Login biasa dan langkah kedua login MFA.
+func loginSuccessData(c *fiber.Ctx, sessions *SessionManager, permissionRepo repository.PermissionRepository, user *models.User, device string) (fiber.Map, error) {
+	ctx := c.Context()
+	permissions, err := permissionRepo.GetByRoleID(ctx, user.RoleID)
 
-	if err := s.userRepo.Create(ctx, newUser); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan user ke database"})
+	var permissionList []string
+	if err == nil {
+		for _, p := range permissions {
+			permissionList = append(permissionList, p.Name)
+		}
 	}
 
-	return c.Status(201).JSON(fiber.Map{
-		"message": "User berhasil dibuat",
-		"data":    newUser,
-	})
+	tokens, err := sessions.Issue(ctx, user, uuid.New(), deviceLabel(c, device))
+	if err != nil {
+		return nil, err
+	}
+	return fiber.Map{
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"user": fiber.Map{
+			"id":       user.ID,
+			"username": user.Username,
+			"fullName": user.FullName,
+			"role":     user.Role.Name,
+
+			"permissions": permissionList,
+		},
+	}, nil
 }
 
 // Login godoc
 // @Summary      Login pengguna
-// @Description  Melakukan autentikasi pengguna dan mendapatkan token JWT (access_token & refresh_token)
+// @Description  Melakukan autentikasi pengguna dan mendapatkan token JWT (access_token & refresh_token). Jika MFA aktif atau diwajibkan untuk role-nya, respons berisi mfa_token untuk langkah berikutnya
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.LoginRequest true "Kredensial login (username/email dan password)"
-// @Success      200  {object}  map[string]interface{} "Login berhasil dengan token"
+// @Success      200  {object}  map[string]interface{} "Login berhasil dengan token, atau status mfa_required berisi mfa_token untuk langkah kedua"
 // @Failure      400  {object}  map[string]interface{} "Request tidak valid"
 // @Failure      401  {object}  map[string]interface{} "Username atau password salah"
+// @Failure      403  {object}  map[string]interface{} "Akun nonaktif (code: ACCOUNT_INACTIVE)"
+// @Failure      423  {object}  map[string]interface{} "Akun dikunci sementara (code: ACCOUNT_LOCKED), lihat header Retry-After"
+// @Failure      429  {object}  map[string]interface{} "Terlalu banyak percobaan (code: TOO_MANY_ATTEMPTS), lihat header Retry-After"
 // @Router       /auth/Login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
 	var req models.LoginRequest
@@ -92,41 +124,69 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 	ctx := c.Context()
 	user, err := s.userRepo.GetByUsernameOrEmail(ctx, req.Username)
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
+		user = nil
+	}
+
+	userKey, ipKey := UserKey(user, req.Username), IPKey(c.IP())
+	if s.loginGuard != nil {
+		block, err := s.loginGuard.Check(ctx, userKey, ipKey)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa percobaan login"})
+		}
+		if block != nil {
+			outcome := models.LoginOutcomeThrottled
+			if block.Code == ErrCodeAccountLocked {
+				outcome = models.LoginOutcomeLocked
+			}
+			s.recordLogin(c, user, req.Username, outcome)
+			return respondLoginBlocked(c, block)
+		}
 	}
 
-	if !utils.CheckPassword(req.Password, user.PasswordHash) {
+	if user == nil || !utils.CheckPassword(req.Password, user.PasswordHash) {
+		outcome := models.LoginOutcomeInvalidPassword
+		if user == nil {
+			outcome = models.LoginOutcomeUnknownUser
+		}
+		s.recordLogin(c, user, req.Username, outcome)
+
+		if s.loginGuard != nil {
+			if err := s.loginGuard.RecordFailure(ctx, userKey, ipKey); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat percobaan login"})
+			}
+		}
 		return c.Status(401).JSON(fiber.Map{"error": "Username atau password salah"})
 	}
 
-	permissions, err := s.permissionRepo.GetByRoleID(ctx, user.RoleID)
+	if !user.IsActive {
+		s.recordLogin(c, user, req.Username, models.LoginOutcomeInactive)
+		return c.Status(403).JSON(fiber.Map{"error": "Akun sudah dinonaktifkan", "code": ErrCodeAccountInactive})
+	}
 
-	var permissionList []string
-	if err == nil {
-		for _, p := range permissions {
-			permissionList = append(permissionList, p.Name)
+	if s.loginGuard != nil {
+		if err := s.loginGuard.RecordSuccess(ctx, userKey); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat percobaan login"})
+		}
+	}
+
+	if s.mfa != nil {
+		challenge, err := s.mfa.LoginChallenge(ctx, user)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa status MFA"})
+		}
+		if challenge != nil {
+			s.recordLogin(c, user, req.Username, models.LoginOutcomeMFAPending)
+			return c.JSON(fiber.Map{"status": "mfa_required", "data": challenge})
 		}
 	}
 
-	token, refreshToken, err := utils.GenerateToken(user.ID, user.Role.Name)
+	data, err := loginSuccessData(c, s.sessions, s.permissionRepo, user, req.DeviceLabel)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
 	}
-	return c.JSON(fiber.Map{
-		"status": "success",
-		"data": fiber.Map{
-			"token":        token,
-			"refreshToken": refreshToken,
-			"user": fiber.Map{
-				"id":       user.ID,
-				"username": user.Username,
-				"fullName": user.FullName,
-				"role":     user.Role.Name,
-
-				"permissions": permissionList,
-			},
-		},
-	})
+	s.recordLogin(c, user, req.Username, models.LoginOutcomeSuccess)
+
+	return c.JSON(fiber.Map{"status": "success", "data": data})
 }
 
 // GetProfile godoc
@@ -166,14 +226,15 @@ func (s *AuthService) GetProfile(c *fiber.Ctx) error {
 
 // RefreshToken godoc
 // @Summary      Refresh token
-// @Description  Mendapatkan access token baru menggunakan refresh token yang valid
+// @Description  Menukar refresh token dengan pasangan token baru. Refresh token hanya bisa dipakai sekali, pemakaian ulang akan mencabut seluruh sesi turunannya
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.RefreshTokenRequest true "Refresh token"
 // @Success      200  {object}  map[string]interface{} "Token baru berhasil dibuat"
-// @Failure      400  {object}  map[string]interface{} "Refresh token tidak valid"
-// @Failure      404  {object}  map[string]interface{} "User tidak ditemukan"
+// @Failure      400  {object}  map[string]interface{} "Request body tidak valid"
+// @Failure      401  {object}  map[string]interface{} "Refresh token tidak valid, expired, atau sudah dipakai"
+// @Failure      403  {object}  map[string]interface{} "Akun nonaktif (code: ACCOUNT_INACTIVE)"
 // @Router       /auth/refresh [post]
 func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 	var req models.RefreshTokenRequest
@@ -184,39 +245,43 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	claims, err := utils.ValidateToken(req.RefreshToken)
+	ctx := c.Context()
+	current, err := s.sessions.Validate(ctx, req.RefreshToken)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "refresh token tidak valid atau expired",
-		})
+		if errors.Is(err, ErrRefreshTokenInvalid) || errors.Is(err, ErrRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa refresh token"})
 	}
 
-	ctx := c.Context()
-	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	user, err := s.userRepo.GetByID(ctx, current.UserID)
 
 	if err != nil || user == nil {
 		return c.Status(401).JSON(fiber.Map{"error": "User tidak ditemukan"})
 	}
 
-	roleName := "unknow"
-	if user.Role != nil {
-		roleName = user.Role.Name
+	if !user.IsActive {
+		return c.Status(403).JSON(fiber.Map{"error": "Akun sudah dinonaktifkan", "code": ErrCodeAccountInactive})
 	}
 
-	newAccessToken, err := utils.GenerateAccessToken(user.ID, roleName)
+	tokens, err := s.sessions.Rotate(ctx, current, user)
 	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token baru"})
 	}
 
 	return c.JSON(fiber.Map{
-		"message":      "Token berhasil diperbarui",
-		"access_token": newAccessToken,
+		"message":       "Token berhasil diperbarui",
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
 	})
 }
 
 // UpdateUser godoc
 // @Summary      Update data pengguna
-// @Description  Memperbarui data pengguna berdasarkan ID (username, email, full_name)
+// @Description  Memperbarui data pengguna berdasarkan ID (username, email, full_name). Pengguna hanya bisa mengubah profilnya sendiri kecuali punya permission user:update
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -225,6 +290,7 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 // @Param        request body models.User true "Data yang akan diupdate"
 // @Success      200  {object}  map[string]interface{} "User berhasil diupdate"
 // @Failure      400  {object}  map[string]interface{} "ID atau body tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan profil sendiri dan tidak punya permission user:update"
 // @Failure      500  {object}  map[string]interface{} "Gagal update user"
 // @Router       /auth/user/{id} [put]
 func (s *AuthService) UpdateUser(c *fiber.Ctx) error {
@@ -234,6 +300,10 @@ func (s *AuthService) UpdateUser(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
 	}
 
+	if current, _ := c.Locals("user_id").(string); current != userUUID.String() && !middleware.HasPermission(c, "user:update") {
+		return c.Status(403).JSON(fiber.Map{"error": "Forbidden: hanya bisa mengubah profil sendiri"})
+	}
+
 	var req models.User
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
@@ -272,6 +342,9 @@ func (s *AuthService) DeleteUser(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
+	if err := s.sessions.RevokeAll(ctx, userUUID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut sesi user"})
+	}
 	if err := s.userRepo.Delete(ctx, userUUID); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus user"})
 	}
@@ -334,21 +407,27 @@ func (s *AuthService) UpdateRoleUser(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	if err := s.userRepo.UpdateRole(ctx, roleUUID, userUUID); err != nil {
+	if err := s.userRepo.UpdateRole(ctx, userUUID, roleUUID); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal update role"})
 	}
+	// token lama masih membawa role lama, paksa ambil token baru
+	if err := s.sessions.InvalidateAccessTokens(ctx, userUUID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Role berubah tapi gagal mencabut token lama"})
+	}
 	return c.JSON(fiber.Map{"message": "Role user berhasil diupdate"})
 
 }
 
 // Logout godoc
 // @Summary      Logout pengguna
-// @Description  Mengeluarkan pengguna dari sistem (invalidate session)
+// @Description  Mengeluarkan pengguna dari sistem dengan mencabut refresh token yang dikirim dan access token yang sedang dipakai
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        request body models.LogoutRequest true "Refresh token sesi yang akan dicabut"
 // @Success      200  {object}  map[string]interface{} "Logout berhasil"
+// @Failure      400  {object}  map[string]interface{} "Refresh token kosong atau bukan milik user"
 // @Failure      401  {object}  map[string]interface{} "Unauthorized"
 // @Router       /auth/logout [post]
 func (s *AuthService) Logout(c *fiber.Ctx) error {
@@ -356,9 +435,255 @@ func (s *AuthService) Logout(c *fiber.Ctx) error {
 	if userIdStr == nil {
 		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
 	}
+	userID, err := uuid.Parse(userIdStr.(string))
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.LogoutRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "refresh_token wajib diisi"})
+	}
+
+	ctx := c.Context()
+	if err := s.sessions.Revoke(ctx, userID, req.RefreshToken); err != nil {
+		if errors.Is(err, ErrRefreshTokenInvalid) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal logout"})
+	}
+
+	// access token yang dipakai request ini juga langsung tidak berlaku
+	tokenID, _ := c.Locals("token_id").(string)
+	expiresAt, ok := c.Locals("token_expires_at").(time.Time)
+	if !ok {
+		expiresAt = time.Now().Add(utils.AccessTokenTTL)
+	}
+	if err := s.sessions.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut access token"})
+	}
 
 	return c.Status(200).JSON(fiber.Map{
 		"status":  "success",
 		"message": "Berhasil logout",
 	})
 }
+
+// LogoutAll godoc
+// @Summary      Logout dari semua sesi
+// @Description  Mencabut seluruh refresh token dan access token milik pengguna yang sedang login (semua perangkat)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Semua sesi berhasil dicabut"
+// @Failure      401  {object}  map[string]interface{} "Unauthorized"
+// @Failure      500  {object}  map[string]interface{} "Gagal mencabut sesi"
+// @Router       /auth/logout-all [post]
+func (s *AuthService) LogoutAll(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id")
+	if userIdStr == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	userID, err := uuid.Parse(userIdStr.(string))
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := s.sessions.RevokeAll(c.Context(), userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut semua sesi"})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Berhasil logout dari semua sesi",
+	})
+}
+
+// GetSessions godoc
+// @Summary      Daftar sesi aktif
+// @Description  Mengambil daftar sesi (refresh token) yang masih aktif milik pengguna yang sedang login
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Daftar sesi aktif"
+// @Failure      401  {object}  map[string]interface{} "Unauthorized"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
+// @Router       /auth/sessions [get]
+func (s *AuthService) GetSessions(c *fiber.Ctx) error {
+	userIdStr := c.Locals("user_id")
+	if userIdStr == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	userID, err := uuid.Parse(userIdStr.(string))
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	sessions, err := s.sessions.List(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data sesi"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Daftar sesi aktif",
+		"data":    sessions,
+	})
+}
+
+// DeactivateUser godoc
+// @Summary      Nonaktifkan pengguna
+// @Description  Menonaktifkan akun pengguna beserta alasannya. Semua sesi dan token milik pengguna langsung dicabut (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID pengguna (UUID)"
+// @Param        request body models.DeactivateUserRequest true "Alasan penonaktifan"
+// @Success      200  {object}  map[string]interface{} "User berhasil dinonaktifkan"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid, alasan kosong, atau menonaktifkan diri sendiri"
+// @Failure      404  {object}  map[string]interface{} "User tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal menonaktifkan user"
+// @Router       /auth/{id}/deactivate [patch]
+func (s *AuthService) DeactivateUser(c *fiber.Ctx) error {
+	userUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	if current, _ := c.Locals("user_id").(string); current == userUUID.String() {
+		return c.Status(400).JSON(fiber.Map{"error": "Tidak bisa menonaktifkan akun sendiri"})
+	}
+
+	var req models.DeactivateUserRequest
+	if err := c.BodyParser(&req); err != nil || req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Alasan penonaktifan wajib diisi"})
+	}
+
+	ctx := c.Context()
+	if user, err := s.userRepo.GetByID(ctx, userUUID); err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+
+	if err := s.userRepo.SetActive(ctx, userUUID, false, req.Reason); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menonaktifkan user"})
+	}
+	middleware.InvalidateUserStatus(userUUID)
+	if err := s.sessions.RevokeAll(ctx, userUUID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "User dinonaktifkan tapi gagal mencabut sesi"})
+	}
+
+	return c.JSON(fiber.Map{"message": "User berhasil dinonaktifkan"})
+}
+
+// ActivateUser godoc
+// @Summary      Aktifkan kembali pengguna
+// @Description  Mengaktifkan kembali akun pengguna yang sebelumnya dinonaktifkan (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID pengguna (UUID)"
+// @Success      200  {object}  map[string]interface{} "User berhasil diaktifkan"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "User tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengaktifkan user"
+// @Router       /auth/{id}/activate [patch]
+func (s *AuthService) ActivateUser(c *fiber.Ctx) error {
+	userUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	ctx := c.Context()
+	if user, err := s.userRepo.GetByID(ctx, userUUID); err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+
+	if err := s.userRepo.SetActive(ctx, userUUID, true, ""); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengaktifkan user"})
+	}
+	middleware.InvalidateUserStatus(userUUID)
+
+	return c.JSON(fiber.Map{"message": "User berhasil diaktifkan kembali"})
+}
+
+// UnlockUser godoc
+// @Summary      Buka kunci akun
+// @Description  Menghapus hitungan login gagal dan kunci sementara pada akun pengguna (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID pengguna (UUID)"
+// @Success      200  {object}  map[string]interface{} "Akun berhasil dibuka"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid atau proteksi login tidak aktif"
+// @Failure      404  {object}  map[string]interface{} "User tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuka kunci akun"
+// @Router       /auth/{id}/unlock [post]
+func (s *AuthService) UnlockUser(c *fiber.Ctx) error {
+	userUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	if s.loginGuard == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Proteksi login tidak aktif"})
+	}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByID(ctx, userUUID)
+	if err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+
+	if err := s.loginGuard.Unlock(ctx, userUUID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuka kunci akun"})
+	}
+	s.recordLogin(c, user, user.Username, models.LoginOutcomeUnlocked)
+
+	return c.JSON(fiber.Map{"message": "Kunci akun berhasil dibuka"})
+}
+
+// GetLoginEvents godoc
+// @Summary      Riwayat percobaan login
+// @Description  Mengambil catatan audit percobaan login terbaru, bisa difilter per user atau IP (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id query string false "Filter ID pengguna (UUID)"
+// @Param        ip query string false "Filter alamat IP"
+// @Param        limit query int false "Jumlah data (default 100, maks 500)"
+// @Success      200  {object}  map[string]interface{} "Daftar event login"
+// @Failure      400  {object}  map[string]interface{} "Filter tidak valid atau proteksi login tidak aktif"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
+// @Router       /auth/login-events [get]
+func (s *AuthService) GetLoginEvents(c *fiber.Ctx) error {
+	if s.loginGuard == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Proteksi login tidak aktif"})
+	}
+
+	filter := models.LoginEventFilter{
+		IPAddress: c.Query("ip"),
+		Limit:     c.QueryInt("limit", 100),
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "user_id tidak valid"})
+		}
+		filter.UserID = &parsed
+	}
+
+	events, err := s.loginGuard.Events(c.Context(), filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil riwayat login"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Riwayat percobaan login",
+		"data":    events,
+	})
+}
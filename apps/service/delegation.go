@@ -0,0 +1,186 @@
+package service
+
+import (
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const delegationDateLayout = "2006-01-02"
+
+// DelegationService dipakai dosen wali untuk menitipkan hak verifikasi ke dosen lain sementara waktu
+type DelegationService struct {
+	lectureRepo    repository.LectureRepository
+	delegationRepo repository.DelegationRepository
+}
+
+func NewDelegationService(lectureRepo repository.LectureRepository, delegationRepo repository.DelegationRepository) *DelegationService {
+	return &DelegationService{lectureRepo: lectureRepo, delegationRepo: delegationRepo}
+}
+
+// currentLecturer profil dosen milik user yang login. Kalau gagal, respons error sudah dikirim.
+func (s *DelegationService) currentLecturer(c *fiber.Ctx) (*models.Lecture, error) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	lecturer, err := s.lectureRepo.GetByUserID(c.Context(), userID)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil profil dosen"})
+	}
+	if lecturer == nil {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Profil dosen tidak ditemukan"})
+	}
+	return lecturer, nil
+}
+
+// Create godoc
+// @Summary      Delegasikan hak verifikasi
+// @Description  Dosen wali menitipkan hak verifikasi prestasi mahasiswa bimbingannya ke dosen lain selama rentang tanggal tertentu (misal saat cuti). end_date inklusif
+// @Tags         Delegations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateDelegationRequest true "Dosen penerima dan rentang tanggal (YYYY-MM-DD)"
+// @Success      201  {object}  map[string]interface{} "Delegasi dibuat"
+// @Failure      400  {object}  map[string]interface{} "Data tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan dosen"
+// @Failure      404  {object}  map[string]interface{} "Dosen penerima tidak ditemukan"
+// @Router       /delegations [post]
+func (s *DelegationService) Create(c *fiber.Ctx) error {
+	advisor, err := s.currentLecturer(c)
+	if advisor == nil {
+		return err
+	}
+
+	var req models.CreateDelegationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	delegateID, err := uuid.Parse(req.DelegateID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID dosen penerima tidak valid"})
+	}
+	if delegateID == advisor.ID {
+		return c.Status(400).JSON(fiber.Map{"error": "Tidak bisa mendelegasikan ke diri sendiri"})
+	}
+
+	startDate, err1 := time.ParseInLocation(delegationDateLayout, req.StartDate, time.Local)
+	endDate, err2 := time.ParseInLocation(delegationDateLayout, req.EndDate, time.Local)
+	if err1 != nil || err2 != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Format tanggal harus YYYY-MM-DD"})
+	}
+	endsAt := endDate.AddDate(0, 0, 1)
+	if endDate.Before(startDate) {
+		return c.Status(400).JSON(fiber.Map{"error": "end_date tidak boleh sebelum start_date"})
+	}
+	if !endsAt.After(time.Now()) {
+		return c.Status(400).JSON(fiber.Map{"error": "Rentang delegasi sudah lewat"})
+	}
+
+	ctx := c.Context()
+	delegate, err := s.lectureRepo.GetByID(ctx, delegateID)
+	if err != nil || delegate == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Dosen penerima tidak ditemukan"})
+	}
+
+	delegation := &models.VerificationDelegation{
+		AdvisorID:  advisor.ID,
+		DelegateID: delegate.ID,
+		StartsAt:   startDate,
+		EndsAt:     endsAt,
+		Reason:     req.Reason,
+	}
+	if err := s.delegationRepo.Create(ctx, delegation); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan delegasi"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Delegasi verifikasi berhasil dibuat",
+		"data":    delegation,
+	})
+}
+
+// GetMine godoc
+// @Summary      Daftar delegasi saya
+// @Description  Mengambil delegasi yang diberikan maupun diterima dosen yang sedang login
+// @Tags         Delegations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Daftar delegasi"
+// @Failure      403  {object}  map[string]interface{} "Bukan dosen"
+// @Router       /delegations [get]
+func (s *DelegationService) GetMine(c *fiber.Ctx) error {
+	lecturer, err := s.currentLecturer(c)
+	if lecturer == nil {
+		return err
+	}
+
+	delegations, err := s.delegationRepo.GetByLecturer(c.Context(), lecturer.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data delegasi"})
+	}
+
+	given := []models.VerificationDelegation{}
+	received := []models.VerificationDelegation{}
+	for _, d := range delegations {
+		if d.AdvisorID == lecturer.ID {
+			given = append(given, d)
+		} else {
+			received = append(received, d)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Daftar delegasi verifikasi",
+		"data": fiber.Map{
+			"given":    given,
+			"received": received,
+		},
+	})
+}
+
+// Revoke godoc
+// @Summary      Cabut delegasi
+// @Description  Mencabut delegasi sebelum waktunya berakhir. Hanya dosen wali pemberi delegasi
+// @Tags         Delegations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID delegasi (UUID)"
+// @Success      200  {object}  map[string]interface{} "Delegasi dicabut"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Delegasi tidak ditemukan"
+// @Router       /delegations/{id} [delete]
+func (s *DelegationService) Revoke(c *fiber.Ctx) error {
+	advisor, err := s.currentLecturer(c)
+	if advisor == nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	ctx := c.Context()
+	delegation, err := s.delegationRepo.GetByID(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data delegasi"})
+	}
+	if delegation == nil || delegation.AdvisorID != advisor.ID {
+		return c.Status(404).JSON(fiber.Map{"error": "Delegasi tidak ditemukan"})
+	}
+
+	if err := s.delegationRepo.Revoke(ctx, id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut delegasi"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Delegasi berhasil dicabut"})
+}
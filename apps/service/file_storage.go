@@ -0,0 +1,549 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/antivirus"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+	"uas-pelaporan-prestasi-mahasiswa/storage"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidSignature = errors.New("tanda tangan URL tidak valid atau sudah kedaluwarsa")
+
+// attachmentContentTypes content type yang dikirim saat download, ditentukan dari ekstensi yang diizinkan
+// (bukan dari header client) supaya browser tidak salah menafsirkan file
+var attachmentContentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+type FileStorageOptions struct {
+	// BaseURL alamat API (misal http://localhost:3000/api/v1), dipakai untuk URL download
+	BaseURL string
+	// SigningKey kunci HMAC untuk signed URL
+	SigningKey []byte
+	// SignedURLTTL masa berlaku signed URL
+	SignedURLTTL time.Duration
+	// MaxFileSize ukuran maksimal satu file (byte)
+	MaxFileSize int64
+	// UserQuota total ukuran upload per user (byte), 0 berarti tanpa batas
+	UserQuota int64
+	// ThumbnailSize sisi terpanjang thumbnail (piksel)
+	ThumbnailSize int
+}
+
+// FileStorage menyimpan file upload ke storage.FileStore berdasarkan SHA-256 isinya dan mencatat metadatanya di tabel files.
+// File tidak pernah diakses langsung dari storage, selalu lewat endpoint download yang mengecek hak akses atau signed URL
+type FileStorage struct {
+	store    storage.FileStore
+	fileRepo repository.FileRepository
+	scanner  antivirus.Scanner
+	options  FileStorageOptions
+	now      func() time.Time
+	// contentLocks menyerialkan Save dan SweepOrphans untuk isi yang sama, supaya sweeper tidak menghapus
+	// isi yang baru saja dianggap sudah ada oleh upload lain
+	contentLocks [64]sync.Mutex
+}
+
+func NewFileStorage(store storage.FileStore, fileRepo repository.FileRepository, scanner antivirus.Scanner, options FileStorageOptions) *FileStorage {
+	if options.SignedURLTTL <= 0 {
+		options.SignedURLTTL = 15 * time.Minute
+	}
+	if options.MaxFileSize <= 0 {
+		options.MaxFileSize = 10 << 20
+	}
+	if options.ThumbnailSize <= 0 {
+		options.ThumbnailSize = 320
+	}
+	return &FileStorage{store: store, fileRepo: fileRepo, scanner: scanner, options: options, now: time.Now}
+}
+
+// lockContent mengunci isi dengan SHA-256 sum, kembaliannya untuk membuka kunci
+func (s *FileStorage) lockContent(sum string) func() {
+	index := 0
+	if len(sum) >= 2 {
+		b, _ := strconv.ParseUint(sum[:2], 16, 8)
+		index = int(b) % len(s.contentLocks)
+	}
+	s.contentLocks[index].Lock()
+	return s.contentLocks[index].Unlock
+}
+
+func (s *FileStorage) MaxFileSize() int64 {
+	return s.options.MaxFileSize
+}
+
+func contentTypeFor(ext string) string {
+	if contentType, ok := attachmentContentTypes[strings.ToLower(ext)]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// SanitizeFileName nama asli dari client hanya untuk ditampilkan: tanpa folder, tanpa karakter kontrol, maksimal 255 karakter
+func SanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
+
+// Save memeriksa lalu menyimpan file milik ownerID: ukuran dan kuota, tipe dari magic bytes, isi PDF, lalu pindai virus.
+// Isi yang sudah ada di storage tidak diupload ulang, tetapi metadata tetap dibuat per upload
+func (s *FileStorage) Save(ctx context.Context, ownerID uuid.UUID, header *multipart.FileHeader) (*models.StoredFile, error) {
+	name := SanitizeFileName(header.Filename)
+	ext := strings.ToLower(filepath.Ext(name))
+	mimeType, allowed := attachmentContentTypes[ext]
+	if !allowed {
+		return nil, ErrFileTypeNotAllowed
+	}
+	if header.Size > s.options.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	if s.options.UserQuota > 0 {
+		used, err := s.fileRepo.TotalSizeByOwner(ctx, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if used+header.Size > s.options.UserQuota {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	if SniffContentType(head[:n]) != mimeType {
+		return nil, ErrFileTypeMismatch
+	}
+
+	// header.Size dari client, ukuran sebenarnya dihitung ulang saat hashing
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, io.LimitReader(src, s.options.MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > s.options.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if mimeType == "application/pdf" {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(src)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckPDF(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Scan(ctx, src); err != nil {
+		return nil, err
+	}
+
+	file := &models.StoredFile{
+		OwnerID:      ownerID,
+		SHA256:       sum,
+		StorageKey:   storage.ContentKey(sum, ext),
+		OriginalName: name,
+		MimeType:     mimeType,
+		Size:         size,
+	}
+
+	// dari cek Exists sampai baris files tersimpan isi ini tidak boleh disapu SweepOrphans
+	defer s.lockContent(sum)()
+	exists, err := s.store.Exists(ctx, file.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.store.Put(ctx, file.StorageKey, src, size, mimeType); err != nil {
+			return nil, err
+		}
+	}
+
+	// preview hanya pelengkap, gagal membuatnya tidak membatalkan upload
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if file.ThumbnailKey, err = s.saveThumbnail(ctx, src, file); err != nil {
+		log.Printf("⚠️  Gagal membuat thumbnail %s: %v", file.StorageKey, err)
+	}
+
+	if err := s.fileRepo.Create(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// saveThumbnail membuat preview JPEG dari gambar, atau dari gambar hasil scan di dalam PDF.
+// Key kosong kalau file tidak punya sumber preview
+func (s *FileStorage) saveThumbnail(ctx context.Context, src io.Reader, file *models.StoredFile) (string, error) {
+	key := storage.ThumbnailKey(file.SHA256)
+	exists, err := s.store.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return key, nil
+	}
+
+	content, err := io.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	if file.MimeType == "application/pdf" {
+		if content = PDFPreviewImage(content); content == nil {
+			return "", nil
+		}
+	}
+	thumbnail, err := MakeThumbnail(content, s.options.ThumbnailSize)
+	if err != nil {
+		return "", err
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// DownloadURL alamat download untuk pengupload (butuh login)
+func (s *FileStorage) DownloadURL(fileID uuid.UUID) string {
+	return strings.TrimRight(s.options.BaseURL, "/") + "/files/" + fileID.String()
+}
+
+// AttachmentURL alamat download lampiran sebuah prestasi (butuh login, dicek pemilik / dosen wali / admin)
+func (s *FileStorage) AttachmentURL(achievementID uuid.UUID, fileID string) string {
+	return strings.TrimRight(s.options.BaseURL, "/") + "/achievements/" + achievementID.String() + "/attachments/" + fileID
+}
+
+// ThumbnailURL alamat preview untuk pengupload, kosong kalau file tidak punya preview
+func (s *FileStorage) ThumbnailURL(file *models.StoredFile) string {
+	if file.ThumbnailKey == "" {
+		return ""
+	}
+	return s.DownloadURL(file.ID) + "/thumbnail"
+}
+
+// AttachmentThumbnailURL alamat preview lampiran sebuah prestasi, kosong kalau file tidak punya preview
+func (s *FileStorage) AttachmentThumbnailURL(achievementID uuid.UUID, file *models.StoredFile) string {
+	if file.ThumbnailKey == "" {
+		return ""
+	}
+	return s.AttachmentURL(achievementID, file.ID.String()) + "/thumbnail"
+}
+
+func (s *FileStorage) signature(fileID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.options.SigningKey)
+	fmt.Fprintf(mac, "%s:%d", fileID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL URL download tanpa login yang berlaku SignedURLTTL, untuk disematkan di laporan
+func (s *FileStorage) SignedURL(fileID uuid.UUID) (string, time.Time) {
+	expiresAt := s.now().Add(s.options.SignedURLTTL).Truncate(time.Second)
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set("signature", s.signature(fileID, expiresAt.Unix()))
+	return strings.TrimRight(s.options.BaseURL, "/") + "/files/" + fileID.String() + "/signed?" + query.Encode(), expiresAt
+}
+
+// VerifySignature mengecek signature dan masa berlaku signed URL
+func (s *FileStorage) VerifySignature(fileID uuid.UUID, expires string, signature string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() > expiresUnix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(fileID, expiresUnix))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Send mengirim isi file dengan Content-Type dari ekstensi dan Content-Disposition berisi nama asli
+func (s *FileStorage) Send(c *fiber.Ctx, file *models.StoredFile) error {
+	body, err := s.store.Get(c.Context(), file.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Isi file tidak ditemukan di storage"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membaca file dari storage"})
+	}
+
+	disposition := "attachment"
+	if c.QueryBool("inline") {
+		disposition = "inline"
+	}
+	c.Set(fiber.HeaderContentType, contentTypeFor(filepath.Ext(file.OriginalName)))
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": file.OriginalName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.SendStream(body, int(file.Size))
+}
+
+// respondFileError memetakan error FileStorage.Save ke status HTTP
+func respondFileError(c *fiber.Ctx, err error) error {
+	var infected *antivirus.InfectedError
+	switch {
+	case errors.Is(err, ErrFileTypeNotAllowed), errors.Is(err, ErrFileTypeMismatch), errors.Is(err, ErrUnsafePDF), errors.Is(err, ErrInvalidPDF):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, ErrQuotaExceeded):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, ErrFileTooLarge):
+		return c.Status(413).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &infected):
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, antivirus.ErrUnavailable):
+		return c.Status(503).JSON(fiber.Map{"error": antivirus.ErrUnavailable.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan file ke storage"})
+}
+
+// SendThumbnail mengirim preview JPEG file, 404 kalau preview tidak tersedia
+func (s *FileStorage) SendThumbnail(c *fiber.Ctx, file *models.StoredFile) error {
+	if file.ThumbnailKey == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "Preview tidak tersedia untuk file ini"})
+	}
+	body, err := s.store.Get(c.Context(), file.ThumbnailKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Preview tidak tersedia untuk file ini"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membaca preview dari storage"})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderContentDisposition, "inline")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// key thumbnail berdasarkan isi file, jadi isinya tidak pernah berubah
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.SendStream(body)
+}
+
+// ownFile file milik user yang sedang login (atau admin). nil berarti respons error sudah dikirim
+func (s *FileStorage) ownFile(c *fiber.Ctx) (*models.StoredFile, error) {
+	file, err := s.findFile(c)
+	if file == nil {
+		return nil, err
+	}
+	userUUID, ok := currentUserID(c)
+	if !ok {
+		return nil, c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	if file.OwnerID != userUUID && !middleware.IsSuperRole(c) {
+		return nil, c.Status(403).JSON(fiber.Map{"error": "Anda tidak berhak mengunduh file ini"})
+	}
+	return file, nil
+}
+
+// findFile mengambil metadata file dari param :id. nil berarti respons error sudah dikirim
+func (s *FileStorage) findFile(c *fiber.Ctx) (*models.StoredFile, error) {
+	fileID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID file tidak valid"})
+	}
+	file, err := s.fileRepo.GetByID(c.Context(), fileID)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data file"})
+	}
+	if file == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "File tidak ditemukan"})
+	}
+	return file, nil
+}
+
+// Download godoc
+// @Summary      Download file upload sendiri
+// @Description  Mengunduh file yang diupload user yang sedang login (misal untuk preview sebelum dilampirkan). Admin boleh mengunduh semua file. Lampiran prestasi diunduh lewat /achievements/{id}/attachments/{fileId}
+// @Tags         Files
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id path string true "ID file (UUID)"
+// @Param        inline query bool false "Tampilkan di browser (Content-Disposition inline)"
+// @Success      200  {file}    file "Isi file"
+// @Failure      403  {object}  map[string]interface{} "Bukan file milik sendiri"
+// @Failure      404  {object}  map[string]interface{} "File tidak ditemukan"
+// @Router       /files/{id} [get]
+func (s *FileStorage) Download(c *fiber.Ctx) error {
+	file, err := s.ownFile(c)
+	if file == nil {
+		return err
+	}
+	return s.Send(c, file)
+}
+
+// DownloadThumbnail godoc
+// @Summary      Preview file upload sendiri
+// @Description  Thumbnail JPEG dari gambar atau dari gambar hasil scan di dalam PDF, milik user yang sedang login (admin boleh semua)
+// @Tags         Files
+// @Produce      jpeg
+// @Security     BearerAuth
+// @Param        id path string true "ID file (UUID)"
+// @Success      200  {file}    file "Thumbnail JPEG"
+// @Failure      403  {object}  map[string]interface{} "Bukan file milik sendiri"
+// @Failure      404  {object}  map[string]interface{} "File atau preview tidak ditemukan"
+// @Router       /files/{id}/thumbnail [get]
+func (s *FileStorage) DownloadThumbnail(c *fiber.Ctx) error {
+	file, err := s.ownFile(c)
+	if file == nil {
+		return err
+	}
+	return s.SendThumbnail(c, file)
+}
+
+// DownloadSigned godoc
+// @Summary      Download file lewat signed URL
+// @Description  Mengunduh file tanpa login memakai URL bertanda tangan HMAC yang masa berlakunya singkat (lihat /achievements/{id}/attachments/{fileId}/signed-url)
+// @Tags         Files
+// @Produce      octet-stream
+// @Param        id path string true "ID file (UUID)"
+// @Param        expires query int true "Unix time kedaluwarsa"
+// @Param        signature query string true "Tanda tangan HMAC"
+// @Success      200  {file}    file "Isi file"
+// @Failure      403  {object}  map[string]interface{} "Signature tidak valid atau kedaluwarsa"
+// @Failure      404  {object}  map[string]interface{} "File tidak ditemukan"
+// @Router       /files/{id}/signed [get]
+func (s *FileStorage) DownloadSigned(c *fiber.Ctx) error {
+	fileID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID file tidak valid"})
+	}
+	// signature dicek sebelum query database supaya URL palsu tidak bisa dipakai menebak ID file
+	if err := s.VerifySignature(fileID, c.Query("expires"), c.Query("signature")); err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	}
+	file, err := s.findFile(c)
+	if file == nil {
+		return err
+	}
+	return s.Send(c, file)
+}
+
+// SweepOrphans menghapus upload yang tidak dilampirkan ke prestasi mana pun sebelum olderThan.
+// Isi di storage hanya dihapus kalau tidak ada baris files lain dengan isi yang sama
+func (s *FileStorage) SweepOrphans(ctx context.Context, olderThan time.Time) (int, error) {
+	orphans, err := s.fileRepo.GetUnlinkedBefore(ctx, olderThan)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, file := range orphans {
+		deleted, err := s.removeOrphan(ctx, file)
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// removeOrphan false kalau file ternyata sudah dilampirkan setelah GetUnlinkedBefore, blob-nya dibiarkan
+func (s *FileStorage) removeOrphan(ctx context.Context, file models.StoredFile) (bool, error) {
+	defer s.lockContent(file.SHA256)()
+	deleted, err := s.fileRepo.Delete(ctx, file.ID)
+	if err != nil || deleted == 0 {
+		return false, err
+	}
+
+	remaining, err := s.fileRepo.CountByStorageKey(ctx, file.StorageKey)
+	if err != nil {
+		return true, err
+	}
+	if remaining == 0 {
+		if err := s.store.Delete(ctx, file.StorageKey); err != nil {
+			return true, err
+		}
+	}
+
+	// thumbnail hanya bergantung pada isi, jadi bisa dipakai upload lain dengan ekstensi berbeda (.jpg dan .jpeg)
+	if file.ThumbnailKey == "" {
+		return true, nil
+	}
+	remaining, err = s.fileRepo.CountByThumbnailKey(ctx, file.ThumbnailKey)
+	if err != nil || remaining > 0 {
+		return true, err
+	}
+	return true, s.store.Delete(ctx, file.ThumbnailKey)
+}
+
+// StartOrphanSweeper menjalankan SweepOrphans setiap interval di background sampai ctx selesai.
+// Upload yang belum dilampirkan setelah ttl dianggap ditinggalkan
+func (s *FileStorage) StartOrphanSweeper(ctx context.Context, interval time.Duration, ttl time.Duration) {
+	if interval <= 0 || ttl <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				removed, err := s.SweepOrphans(ctx, s.now().Add(-ttl))
+				if err != nil {
+					log.Printf("⚠️  Gagal menghapus upload yang tidak dilampirkan: %v", err)
+				} else if removed > 0 {
+					log.Printf("🧹 %d upload yang tidak dilampirkan dihapus", removed)
+				}
+			}
+		}
+	}()
+}
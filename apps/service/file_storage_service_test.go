@@ -0,0 +1,815 @@
+package service_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/antivirus"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+	"uas-pelaporan-prestasi-mahasiswa/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// uploadFile mengirim multipart dengan field "file"
+func uploadFile(t *testing.T, app *fiber.App, path, fileName, contentType string, content []byte) (int, map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+fileName+`"`)
+	header.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", path, &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Error request: %v", err)
+	}
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// pdfFile PDF minimal yang lolos pemeriksaan (header, %%EOF), extra disisipkan di dalam dictionary katalog
+func pdfFile(extra string) []byte {
+	return []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog " + extra + " >>\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+}
+
+func TestUploadAttachment_ContentAddressed(t *testing.T) {
+	f := newAchievementFixture()
+	app := appAs(f.student.UserID, "/achievements/upload", "POST", f.service.UploadAttachment)
+	content := pdfFile("sertifikat juara")
+
+	tests := []struct {
+		name             string
+		fileName         string
+		expectedStatus   int
+		expectedFileName string
+	}{
+		{"Nama Biasa", "sertifikat.pdf", 200, "sertifikat.pdf"},
+		{"Path Traversal", "../../etc/sertifikat.pdf", 200, "sertifikat.pdf"},
+		{"Ekstensi Tidak Diizinkan", "script.exe", 400, ""},
+	}
+
+	var hashes []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := uploadFile(t, app, "/achievements/upload", tt.fileName, "application/pdf", content)
+			if status != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+			if status != 200 {
+				return
+			}
+			data := body["data"].(map[string]interface{})
+			if data["fileName"] != tt.expectedFileName {
+				t.Errorf("Expected fileName %q, got %v", tt.expectedFileName, data["fileName"])
+			}
+			if !regexp.MustCompile(`^http://api\.test/api/v1/files/[0-9a-f-]{36}$`).MatchString(data["fileUrl"].(string)) {
+				t.Errorf("fileUrl harus endpoint download API, got %v", data["fileUrl"])
+			}
+			hashes = append(hashes, data["hash"].(string))
+		})
+	}
+
+	// isi sama -> SHA-256 sama, disimpan sekali di storage
+	if len(hashes) != 2 || hashes[0] != hashes[1] {
+		t.Errorf("Hash harus sama untuk isi yang sama: %v", hashes)
+	}
+}
+
+// s3Stub server mirip MinIO: menyimpan objek di memori dan menolak request tanpa tanda tangan SigV4
+type s3Stub struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+var sigV4Header = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKIATEST/\d{8}/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`)
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !sigV4Header.MatchString(r.Header.Get("Authorization")) || r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, exists := s.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = body
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Write(object)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store_AgainstStub(t *testing.T) {
+	stub := &s3Stub{objects: make(map[string][]byte)}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	store := storage.NewS3Store(storage.S3Options{
+		Endpoint:  server.URL,
+		Bucket:    "prestasi",
+		AccessKey: "AKIATEST",
+		SecretKey: "rahasia",
+		PathStyle: true,
+	})
+	ctx := context.Background()
+	key := storage.ContentKey(strings.Repeat("ab", 32), ".PDF")
+	content := []byte("%PDF-1.4 isi lampiran")
+
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, ok := stub.objects["/prestasi/"+key]; !ok {
+		t.Fatalf("Objek harus tersimpan path-style di bucket, got %v", stub.objects)
+	}
+
+	if exists, err := store.Exists(ctx, key); err != nil || !exists {
+		t.Errorf("Exists: %v %v", exists, err)
+	}
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Isi file berbeda: %q", got)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); err != storage.ErrNotFound {
+		t.Errorf("Expected ErrNotFound setelah delete, got %v", err)
+	}
+	if err := store.Put(ctx, "../rahasia", bytes.NewReader(content), int64(len(content)), ""); err == nil {
+		t.Error("Key path traversal harus ditolak")
+	}
+
+	wrongCreds := storage.NewS3Store(storage.S3Options{Endpoint: server.URL, Bucket: "prestasi", AccessKey: "LAIN", PathStyle: true})
+	if err := wrongCreds.Put(ctx, key, bytes.NewReader(content), int64(len(content)), ""); err == nil {
+		t.Error("Request yang ditolak storage harus error")
+	}
+}
+
+func TestAttachmentDownload_AccessAndSignedURL(t *testing.T) {
+	f := newAchievementFixture()
+	middleware.SetPermissionCache(middleware.NewPermissionCache(mocks.NewManualMockPermissionRepo(), time.Minute, "Admin"))
+	defer middleware.SetPermissionCache(nil)
+
+	content := pdfFile("sertifikat pribadi")
+	_, body := uploadFile(t, appAs(f.student.UserID, "/achievements/upload", "POST", f.service.UploadAttachment), "/achievements/upload", "sertifikat juara.pdf", "text/html", content)
+	fileID := body["data"].(map[string]interface{})["fileId"].(string)
+
+	ctx := context.Background()
+	detail := &models.AchievementDetail{StudentID: f.student.ID.String(), AchievementType: "competition", Title: "Juara 1 Gemastik",
+		Attachments: []models.Attachment{{FileID: fileID, FileName: "sertifikat juara.pdf"}}}
+	f.achievementRepo.CreateDetail(ctx, detail)
+	ref := &models.AchievementReference{StudentID: f.student.ID, MongoAchievementID: detail.ID.Hex(), Status: models.AchievementStatusSubmitted}
+	f.achievementRepo.CreateReference(ctx, ref)
+
+	otherStudent := &models.Students{UserID: uuid.New(), StudentID: "NIM-002", AdvisorID: &f.otherLecturer.ID}
+	f.studentRepo.Create(ctx, otherStudent)
+
+	request := func(userID uuid.UUID, role, path string, handler fiber.Handler, route string) *http.Response {
+		app := fiber.New()
+		app.Get(route, func(c *fiber.Ctx) error {
+			c.Locals("user_id", userID.String())
+			c.Locals("role", role)
+			return c.Next()
+		}, handler)
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatalf("Error request: %v", err)
+		}
+		return resp
+	}
+	attachmentPath := "/achievements/" + ref.ID.String() + "/attachments/" + fileID
+
+	tests := []struct {
+		name           string
+		userID         uuid.UUID
+		role           string
+		path           string
+		expectedStatus int
+	}{
+		{"Pemilik", f.student.UserID, "Mahasiswa", attachmentPath, 200},
+		{"Dosen Wali", f.advisor.UserID, "Dosen", attachmentPath, 200},
+		{"Admin", uuid.New(), "Admin", attachmentPath, 200},
+		{"Dosen Lain", f.otherLecturer.UserID, "Dosen", attachmentPath, 403},
+		{"Mahasiswa Lain", otherStudent.UserID, "Mahasiswa", attachmentPath, 403},
+		{"File Bukan Lampiran Prestasi", f.student.UserID, "Mahasiswa", "/achievements/" + ref.ID.String() + "/attachments/" + uuid.NewString(), 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := request(tt.userID, tt.role, tt.path, f.service.DownloadAttachment, "/achievements/:id/attachments/:fileId")
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if resp.StatusCode != 200 {
+				return
+			}
+			got, _ := io.ReadAll(resp.Body)
+			if !bytes.Equal(got, content) {
+				t.Errorf("Isi file berbeda: %q", got)
+			}
+			// content type dari ekstensi, bukan dari header client (text/html)
+			if ct := resp.Header.Get("Content-Type"); ct != "application/pdf" {
+				t.Errorf("Expected Content-Type application/pdf, got %s", ct)
+			}
+			if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename="sertifikat juara.pdf"` {
+				t.Errorf("Content-Disposition salah: %s", cd)
+			}
+		})
+	}
+
+	t.Run("Signed URL", func(t *testing.T) {
+		resp := request(f.advisor.UserID, "Dosen", attachmentPath+"/signed-url", f.service.GetAttachmentSignedURL, "/achievements/:id/attachments/:fileId/signed-url")
+		var result map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&result)
+		signedURL, _ := url.Parse(result["data"].(map[string]interface{})["url"].(string))
+		if signedURL == nil || signedURL.Query().Get("signature") == "" {
+			t.Fatalf("Signed URL tidak valid: %v", result)
+		}
+
+		// tanpa login sama sekali
+		app := fiber.New()
+		app.Get("/api/v1/files/:id/signed", f.files.DownloadSigned)
+		resp, _ = app.Test(httptest.NewRequest("GET", signedURL.RequestURI(), nil))
+		if resp.StatusCode != 200 {
+			t.Errorf("Signed URL harus bisa dipakai tanpa login, got %d", resp.StatusCode)
+		}
+
+		query := signedURL.Query()
+		query.Set("expires", "9999999999")
+		resp, _ = app.Test(httptest.NewRequest("GET", signedURL.Path+"?"+query.Encode(), nil))
+		if resp.StatusCode != 403 {
+			t.Errorf("Masa berlaku yang diubah harus ditolak, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Download File Upload Sendiri", func(t *testing.T) {
+		if resp := request(f.student.UserID, "Mahasiswa", "/files/"+fileID, f.files.Download, "/files/:id"); resp.StatusCode != 200 {
+			t.Errorf("Pengupload harus bisa mengunduh, got %d", resp.StatusCode)
+		}
+		if resp := request(otherStudent.UserID, "Mahasiswa", "/files/"+fileID, f.files.Download, "/files/:id"); resp.StatusCode != 403 {
+			t.Errorf("User lain tidak boleh mengunduh, got %d", resp.StatusCode)
+		}
+	})
+}
+
+// clamdStub server TCP yang menjawab protokol INSTREAM clamd, file berisi tanda EICAR dianggap virus
+func clamdStub(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Gagal membuka stub clamd: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				command := make([]byte, len("zINSTREAM\x00"))
+				if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var content []byte
+				for {
+					var size uint32
+					if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					chunk := make([]byte, size)
+					if _, err := io.ReadFull(conn, chunk); err != nil {
+						return
+					}
+					content = append(content, chunk...)
+				}
+				if bytes.Contains(content, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+					conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func deflate(content string) string {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	writer.Write([]byte(content))
+	writer.Close()
+	return buf.String()
+}
+
+func TestUploadAttachment_Validation(t *testing.T) {
+	clamd := clamdStub(t)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR foto")
+
+	// port yang baru ditutup -> clamd tidak tersedia
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	downAddr := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name           string
+		scanner        antivirus.Scanner
+		options        service.FileStorageOptions
+		fileName       string
+		content        []byte
+		expectedStatus int
+	}{
+		{"PNG Valid Lolos ClamAV", antivirus.NewClamAVScanner(clamd, time.Second), service.FileStorageOptions{}, "foto.png", png, 200},
+		{"PDF Valid", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf", pdfFile(""), 200},
+		{"PNG Diberi Nama PDF", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf", png, 400},
+		{"HTML Diberi Nama JPG", antivirus.NewNopScanner(), service.FileStorageOptions{}, "foto.jpg", []byte("<html><script>alert(1)</script></html>"), 400},
+		{"PDF Dengan JavaScript", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf", pdfFile("/OpenAction << /S /JavaScript /JS (app.alert(1)) >>"), 400},
+		{"PDF JavaScript Di-escape", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf", pdfFile("/OpenAction << /S /J#61vaScript >>"), 400},
+		{"PDF JavaScript Dalam Stream Terkompresi", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf",
+			pdfFile(">>\nstream\n" + deflate("<< /S /JavaScript /JS (app.alert(1)) >>") + "\nendstream\n<<"), 400},
+		{"PDF Scan Dengan Byte /JS Di Gambar", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf",
+			pdfFile(">>\nendobj\n2 0 obj\n<< /Type /XObject /Subtype /Image /Filter /DCTDecode /Length 15 >>\nstream\n\xFF\xD8\xFF/JS \x00/Launch\nendstream\n<<"), 200},
+		{"PDF Object Stream Menyamar Jadi Gambar", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf",
+			pdfFile(">>\nendobj\n2 0 obj\n<< /Type /ObjStm /Subtype /Image /Filter /FlateDecode >>\nstream\n" + deflate("<< /S /JavaScript /JS (app.alert(1)) >>") + "\nendstream\n<<"), 400},
+		{"PDF JavaScript Di Stream Dengan Dictionary Bersarang", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf",
+			pdfFile(">>\nendobj\n2 0 obj\n<< /Type /ObjStm /ID <00> /DecodeParms << /A << /B 1 >> >> /Filter /FlateDecode >>\nstream\n" + deflate("<< /S /JavaScript /JS (app.alert(1)) >>") + "\nendstream\n<<"), 400},
+		{"PDF FlateDecode Rusak", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf",
+			pdfFile(">>\nendobj\n2 0 obj\n<< /Type /ObjStm /Filter /FlateDecode >>\nstream\nbukan zlib\nendstream\n<<"), 400},
+		{"PDF Terpotong", antivirus.NewNopScanner(), service.FileStorageOptions{}, "sertifikat.pdf", []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog"), 400},
+		{"Melebihi Ukuran Maksimal", antivirus.NewNopScanner(), service.FileStorageOptions{MaxFileSize: 16}, "foto.png", png, 413},
+		{"Kuota Habis", antivirus.NewNopScanner(), service.FileStorageOptions{UserQuota: int64(len(png)) + 10}, "foto.png", png, 403},
+		{"Terdeteksi Virus", antivirus.NewClamAVScanner(clamd, time.Second), service.FileStorageOptions{}, "foto.png", append(png, []byte("X5O!P%@AP[4\\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*")...), 422},
+		{"ClamAV Tidak Tersedia", antivirus.NewClamAVScanner(downAddr, time.Second), service.FileStorageOptions{}, "foto.png", png, 503},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture().withFiles(tt.scanner, tt.options)
+			if tt.options.UserQuota > 0 {
+				// upload pertama masih muat, upload kedua melewati kuota
+				uploadFile(t, appAs(f.student.UserID, "/achievements/upload", "POST", f.service.UploadAttachment), "/achievements/upload", tt.fileName, "image/png", tt.content)
+			}
+
+			app := appAs(f.student.UserID, "/achievements/upload", "POST", f.service.UploadAttachment)
+			status, body := uploadFile(t, app, "/achievements/upload", tt.fileName, "application/octet-stream", tt.content)
+			if status != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+}
+
+func TestAttachments_LinkedUploadsAndOrphanSweep(t *testing.T) {
+	f := newAchievementFixture()
+	ctx := context.Background()
+	otherStudent := &models.Students{UserID: uuid.New(), StudentID: "NIM-002", AdvisorID: &f.advisor.ID}
+	f.studentRepo.Create(ctx, otherStudent)
+
+	upload := func(userID uuid.UUID, fileName string, content []byte) string {
+		app := appAs(userID, "/achievements/upload", "POST", f.service.UploadAttachment)
+		status, body := uploadFile(t, app, "/achievements/upload", fileName, "", content)
+		if status != 200 {
+			t.Fatalf("Upload gagal: %d %v", status, body)
+		}
+		return body["data"].(map[string]interface{})["fileId"].(string)
+	}
+	certificate := pdfFile("sertifikat gemastik")
+	fileA := upload(f.student.UserID, "sertifikat.pdf", certificate)
+	fileB := upload(f.student.UserID, "foto.png", []byte("\x89PNG\r\n\x1a\nfoto podium"))
+	fileOther := upload(otherStudent.UserID, "punya-orang.png", []byte("\x89PNG\r\n\x1a\nfoto orang lain"))
+
+	create := appAs(f.student.UserID, "/achievements", "POST", f.service.Create)
+	request := func(fileIDs ...string) models.CreateAchievementRequest {
+		req := models.CreateAchievementRequest{Title: "Juara 1 Gemastik", Type: "competition", Details: map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"}}
+		for _, id := range fileIDs {
+			req.Attachments = append(req.Attachments, models.AttachmentInput{FileID: id})
+		}
+		return req
+	}
+
+	status, body := decodeBody(t, create, "POST", "/achievements", request(fileA))
+	if status != 201 {
+		t.Fatalf("Expected 201, got %d (%v)", status, body)
+	}
+	achievementID := body["data"].(map[string]interface{})["id"].(string)
+	attachments := body["data"].(map[string]interface{})["detail"].(map[string]interface{})["attachments"].([]interface{})
+	attachment := attachments[0].(map[string]interface{})
+	if attachment["fileName"] != "sertifikat.pdf" || attachment["fileUrl"] != "http://api.test/api/v1/achievements/"+achievementID+"/attachments/"+fileA {
+		t.Errorf("Metadata lampiran harus diisi server dari upload, got %v", attachment)
+	}
+
+	tests := []struct {
+		name           string
+		fileIDs        []string
+		expectedStatus int
+	}{
+		{"Sudah Dilampirkan Ke Prestasi Lain", []string{fileA}, 400},
+		{"Upload Milik Mahasiswa Lain", []string{fileOther}, 400},
+		{"Bukan fileId", []string{"http://localhost:3000/uploads/sertifikat.pdf"}, 400},
+		{"File Sama Dua Kali", []string{fileB, fileB}, 400},
+		{"Tanpa Lampiran", nil, 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, create, "POST", "/achievements", request(tt.fileIDs...))
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+
+	add := appAs(f.student.UserID, "/achievements/:id/attachments", "POST", f.service.AddAttachment)
+	if status, body := decodeBody(t, add, "POST", "/achievements/"+achievementID+"/attachments", models.AttachmentInput{FileID: fileB}); status != 201 {
+		t.Fatalf("Tambah lampiran: expected 201, got %d (%v)", status, body)
+	}
+	if status, _ := decodeBody(t, add, "POST", "/achievements/"+achievementID+"/attachments", models.AttachmentInput{FileID: fileB}); status != 409 {
+		t.Errorf("Lampiran yang sama: expected 409, got %d", status)
+	}
+	otherAdd := appAs(otherStudent.UserID, "/achievements/:id/attachments", "POST", f.service.AddAttachment)
+	if status, _ := decodeBody(t, otherAdd, "POST", "/achievements/"+achievementID+"/attachments", models.AttachmentInput{FileID: fileOther}); status != 403 {
+		t.Errorf("Mahasiswa lain: expected 403, got %d", status)
+	}
+
+	remove := appAs(f.student.UserID, "/achievements/:id/attachments/:fileId", "DELETE", f.service.RemoveAttachment)
+	if status, body := decodeBody(t, remove, "DELETE", "/achievements/"+achievementID+"/attachments/"+fileA, nil); status != 200 {
+		t.Fatalf("Hapus lampiran: expected 200, got %d (%v)", status, body)
+	}
+	ref, _ := f.achievementRepo.GetReferenceByID(ctx, uuid.MustParse(achievementID))
+	detail, _ := f.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if len(detail.Attachments) != 1 || detail.Attachments[0].FileID != fileB {
+		t.Errorf("Yang tersisa harus hanya lampiran B, got %+v", detail.Attachments)
+	}
+
+	// sweeper: upload yang tidak pernah dilampirkan dan lebih tua dari TTL dihapus, isi yang masih dipakai tetap ada
+	duplicateOfA := upload(f.student.UserID, "sertifikat-lagi.pdf", certificate)
+	old := time.Now().Add(-48 * time.Hour)
+	f.fileRepo.SetCreatedAt(uuid.MustParse(duplicateOfA), old)
+	f.fileRepo.SetCreatedAt(uuid.MustParse(fileOther), old)
+	f.fileRepo.SetCreatedAt(uuid.MustParse(fileA), old)
+	otherFile, _ := f.fileRepo.GetByID(ctx, uuid.MustParse(fileOther))
+
+	removed, err := f.files.SweepOrphans(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || removed != 2 {
+		t.Fatalf("Expected 2 upload dihapus, got %d (%v)", removed, err)
+	}
+	for _, id := range []string{fileA, fileB} {
+		if file, _ := f.fileRepo.GetByID(ctx, uuid.MustParse(id)); file == nil {
+			t.Errorf("File %s yang pernah dilampirkan tidak boleh dihapus", id)
+		}
+	}
+	download := func(userID uuid.UUID, id string) int {
+		resp, _ := appAs(userID, "/files/:id", "GET", f.files.Download).Test(httptest.NewRequest("GET", "/files/"+id, nil))
+		return resp.StatusCode
+	}
+	if status := download(f.student.UserID, fileA); status != 200 {
+		t.Errorf("Isi file A dipakai bersama upload yang dihapus, harus tetap ada, got %d", status)
+	}
+	if status := download(otherStudent.UserID, fileOther); status != 404 {
+		t.Errorf("Upload yang dihapus sweeper harus 404, got %d", status)
+	}
+	if _, err := os.Stat(filepath.Join(os.TempDir(), "prestasi-uploads-test", filepath.FromSlash(otherFile.StorageKey))); !os.IsNotExist(err) {
+		t.Errorf("Isi file yang tidak dipakai lagi harus dihapus dari storage, got %v", err)
+	}
+}
+
+// racingStore menjalankan onExists sekali setelah Exists menjawab, untuk menyisipkan sweeper di tengah upload
+type racingStore struct {
+	storage.FileStore
+	onExists func()
+}
+
+func (r *racingStore) Exists(ctx context.Context, key string) (bool, error) {
+	exists, err := r.FileStore.Exists(ctx, key)
+	if hook := r.onExists; hook != nil {
+		r.onExists = nil
+		hook()
+	}
+	return exists, err
+}
+
+func TestOrphanSweep_ConcurrentDuplicateUpload(t *testing.T) {
+	f := newAchievementFixture()
+	ctx := context.Background()
+	store := &racingStore{FileStore: storage.NewLocalStore(filepath.Join(os.TempDir(), "prestasi-uploads-test"))}
+	files := service.NewFileStorage(store, f.fileRepo, antivirus.NewNopScanner(), service.FileStorageOptions{BaseURL: "http://api.test/api/v1", SigningKey: []byte("rahasia-file")})
+	achievements := service.NewAchievementService(f.achievementRepo, f.studentRepo, service.NewVerificationAuthority(f.lectureRepo, f.delegationRepo),
+		service.NewAchievementValidator(f.typeRepo), service.NewScoringEngine(service.DefaultScoringRules(), f.typeRepo), service.NewPointLedger(f.ledgerRepo), f.revisionRepo, f.versionRepo, f.memberRepo, files, service.AchievementOptions{})
+	app := appAs(f.student.UserID, "/achievements/upload", "POST", achievements.UploadAttachment)
+	content := []byte("\x89PNG\r\n\x1a\nfoto " + uuid.NewString())
+
+	status, body := uploadFile(t, app, "/achievements/upload", "foto.png", "", content)
+	if status != 200 {
+		t.Fatalf("Upload pertama gagal: %d %v", status, body)
+	}
+	abandoned := body["data"].(map[string]interface{})["fileId"].(string)
+	f.fileRepo.SetCreatedAt(uuid.MustParse(abandoned), time.Now().Add(-48*time.Hour))
+
+	// sweeper jalan tepat setelah upload kedua melihat isinya sudah ada, sebelum baris files-nya tersimpan
+	swept := make(chan int, 1)
+	store.onExists = func() {
+		go func() {
+			removed, _ := files.SweepOrphans(ctx, time.Now().Add(-24*time.Hour))
+			swept <- removed
+		}()
+		time.Sleep(50 * time.Millisecond)
+	}
+	status, body = uploadFile(t, app, "/achievements/upload", "foto-lagi.png", "", content)
+	if status != 200 {
+		t.Fatalf("Upload kedua gagal: %d %v", status, body)
+	}
+	if removed := <-swept; removed != 1 {
+		t.Fatalf("Expected upload lama dihapus, got %d", removed)
+	}
+
+	fresh := body["data"].(map[string]interface{})["fileId"].(string)
+	resp, _ := appAs(f.student.UserID, "/files/:id", "GET", files.Download).Test(httptest.NewRequest("GET", "/files/"+fresh, nil))
+	if resp.StatusCode != 200 {
+		t.Errorf("Isi upload kedua tidak boleh ikut terhapus sweeper, got %d", resp.StatusCode)
+	}
+}
+
+// racingFileRepo menjalankan onListed sekali setelah sweeper mengambil daftar upload yatim,
+// dan onGet sekali setelah metadata file dibaca (misal saat lampiran dicek)
+type racingFileRepo struct {
+	*mocks.ManualMockFileRepo
+	onListed func()
+	onGet    func()
+}
+
+func (r *racingFileRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.StoredFile, error) {
+	file, err := r.ManualMockFileRepo.GetByID(ctx, id)
+	if hook := r.onGet; hook != nil {
+		r.onGet = nil
+		hook()
+	}
+	return file, err
+}
+
+func (r *racingFileRepo) GetUnlinkedBefore(ctx context.Context, before time.Time) ([]models.StoredFile, error) {
+	files, err := r.ManualMockFileRepo.GetUnlinkedBefore(ctx, before)
+	if hook := r.onListed; hook != nil {
+		r.onListed = nil
+		hook()
+	}
+	return files, err
+}
+
+func TestOrphanSweep_KeepsAttachedAndSharedContent(t *testing.T) {
+	f := newAchievementFixture()
+	ctx := context.Background()
+	repo := &racingFileRepo{ManualMockFileRepo: f.fileRepo}
+	files := service.NewFileStorage(storage.NewLocalStore(filepath.Join(os.TempDir(), "prestasi-uploads-test")), repo, antivirus.NewNopScanner(), service.FileStorageOptions{BaseURL: "http://api.test/api/v1", SigningKey: []byte("rahasia-file")})
+	achievements := service.NewAchievementService(f.achievementRepo, f.studentRepo, service.NewVerificationAuthority(f.lectureRepo, f.delegationRepo),
+		service.NewAchievementValidator(f.typeRepo), service.NewScoringEngine(service.DefaultScoringRules(), f.typeRepo), service.NewPointLedger(f.ledgerRepo), f.revisionRepo, f.versionRepo, f.memberRepo, files, service.AchievementOptions{})
+	app := appAs(f.student.UserID, "/achievements/upload", "POST", achievements.UploadAttachment)
+	upload := func(fileName string, content []byte) uuid.UUID {
+		status, body := uploadFile(t, app, "/achievements/upload", fileName, "", content)
+		if status != 200 {
+			t.Fatalf("Upload gagal: %d %v", status, body)
+		}
+		id := uuid.MustParse(body["data"].(map[string]interface{})["fileId"].(string))
+		f.fileRepo.SetCreatedAt(id, time.Now().Add(-48*time.Hour))
+		return id
+	}
+	download := func(id uuid.UUID) int {
+		resp, _ := appAs(f.student.UserID, "/files/:id", "GET", files.Download).Test(httptest.NewRequest("GET", "/files/"+id.String(), nil))
+		return resp.StatusCode
+	}
+	thumbnailExists := func(id uuid.UUID) bool {
+		file, _ := f.fileRepo.GetByID(ctx, id)
+		_, err := os.Stat(filepath.Join(os.TempDir(), "prestasi-uploads-test", filepath.FromSlash(file.ThumbnailKey)))
+		return file.ThumbnailKey != "" && err == nil
+	}
+
+	// dilampirkan setelah sweeper mengambil daftar, sebelum baris files-nya dihapus
+	attached := upload("foto.png", []byte("\x89PNG\r\n\x1a\nfoto "+uuid.NewString()))
+	achievementID := uuid.New()
+	repo.onListed = func() {
+		f.fileRepo.LinkToAchievement(ctx, []uuid.UUID{attached}, achievementID)
+	}
+	if removed, err := files.SweepOrphans(ctx, time.Now().Add(-24*time.Hour)); err != nil || removed != 0 {
+		t.Fatalf("File yang baru dilampirkan tidak boleh dihapus, got %d (%v)", removed, err)
+	}
+	if status := download(attached); status != 200 {
+		t.Errorf("Isi file yang dilampirkan harus tetap ada, got %d", status)
+	}
+
+	// .jpg dan .jpeg dengan isi sama memakai thumbnail yang sama
+	photo := testImage(t, "jpeg", 420, 210)
+	abandoned := upload("podium.jpg", photo)
+	kept := upload("podium.jpeg", photo)
+	f.fileRepo.LinkToAchievement(ctx, []uuid.UUID{kept}, achievementID)
+	if !thumbnailExists(kept) {
+		t.Fatalf("Thumbnail harus dibuat untuk foto")
+	}
+	if removed, err := files.SweepOrphans(ctx, time.Now().Add(-24*time.Hour)); err != nil || removed != 1 {
+		t.Fatalf("Expected satu upload .jpg dihapus, got %d (%v)", removed, err)
+	}
+	if file, _ := f.fileRepo.GetByID(ctx, abandoned); file != nil {
+		t.Errorf("Upload .jpg yang tidak dilampirkan harus dihapus")
+	}
+	if !thumbnailExists(kept) {
+		t.Errorf("Thumbnail yang masih dipakai upload .jpeg tidak boleh ikut terhapus")
+	}
+}
+
+func TestCreate_AttachmentLinkedConcurrently(t *testing.T) {
+	f := newAchievementFixture()
+	ctx := context.Background()
+	repo := &racingFileRepo{ManualMockFileRepo: f.fileRepo}
+	files := service.NewFileStorage(storage.NewLocalStore(filepath.Join(os.TempDir(), "prestasi-uploads-test")), repo, antivirus.NewNopScanner(), service.FileStorageOptions{BaseURL: "http://api.test/api/v1", SigningKey: []byte("rahasia-file")})
+	achievements := service.NewAchievementService(f.achievementRepo, f.studentRepo, service.NewVerificationAuthority(f.lectureRepo, f.delegationRepo),
+		service.NewAchievementValidator(f.typeRepo), service.NewScoringEngine(service.DefaultScoringRules(), f.typeRepo), service.NewPointLedger(f.ledgerRepo), f.revisionRepo, f.versionRepo, f.memberRepo, files, service.AchievementOptions{})
+
+	upload := appAs(f.student.UserID, "/achievements/upload", "POST", achievements.UploadAttachment)
+	status, body := uploadFile(t, upload, "/achievements/upload", "sertifikat.pdf", "", pdfFile("sertifikat "+uuid.NewString()))
+	if status != 200 {
+		t.Fatalf("Upload gagal: %d %v", status, body)
+	}
+	fileID := uuid.MustParse(body["data"].(map[string]interface{})["fileId"].(string))
+
+	// request lain melampirkan file yang sama tepat setelah Create mengecek lampirannya
+	repo.onGet = func() {
+		f.fileRepo.LinkToAchievement(ctx, []uuid.UUID{fileID}, uuid.New())
+	}
+	create := appAs(f.student.UserID, "/achievements", "POST", achievements.Create)
+	status, body = decodeBody(t, create, "POST", "/achievements", models.CreateAchievementRequest{
+		Title: "Juara 1 Gemastik", Type: "competition",
+		Details:     map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+		Attachments: []models.AttachmentInput{{FileID: fileID.String()}},
+	})
+	if status != 409 {
+		t.Fatalf("Expected 409, got %d %v", status, body)
+	}
+
+	if refs, _ := f.achievementRepo.GetAllByStudentID(ctx, f.student.ID); len(refs) != 0 {
+		t.Errorf("Reference prestasi yang gagal dibuat harus dihapus, got %+v", refs)
+	}
+	if details, _ := f.achievementRepo.GetAllDetailsFromMongo(ctx); len(details) != 0 {
+		t.Errorf("Detail prestasi yang gagal dibuat harus dihapus, got %d", len(details))
+	}
+}
+
+// testImage gambar polos w x h dalam format png / jpeg
+func testImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{R: 200, G: 30, B: 30, A: 255}}, image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if format == "png" {
+		png.Encode(&buf, img)
+	} else {
+		jpeg.Encode(&buf, img, nil)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        []byte
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"PNG Landscape", testImage(t, "png", 800, 400), 320, 160},
+		{"JPEG Portrait", testImage(t, "jpeg", 400, 1000), 128, 320},
+		{"Gambar Kecil Tidak Diperbesar", testImage(t, "png", 100, 50), 100, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbnail, err := service.MakeThumbnail(tt.content, 320)
+			if err != nil {
+				t.Fatalf("MakeThumbnail: %v", err)
+			}
+			config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+			if err != nil || format != "jpeg" {
+				t.Fatalf("Thumbnail harus JPEG, got %s (%v)", format, err)
+			}
+			if config.Width != tt.expectedWidth || config.Height != tt.expectedHeight {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.expectedWidth, tt.expectedHeight, config.Width, config.Height)
+			}
+		})
+	}
+
+	if _, err := service.MakeThumbnail([]byte("%PDF-1.4 bukan gambar"), 320); err == nil {
+		t.Error("Isi yang bukan gambar harus error")
+	}
+}
+
+func TestAttachmentThumbnails(t *testing.T) {
+	f := newAchievementFixture()
+	scan := testImage(t, "jpeg", 600, 300)
+	scannedPDF := []byte(fmt.Sprintf("%%PDF-1.4\n1 0 obj\n<< /Type /XObject /Subtype /Image /Width 600 /Height 300 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n", len(scan)))
+	scannedPDF = append(scannedPDF, scan...)
+	scannedPDF = append(scannedPDF, []byte("\nendstream\nendobj\ntrailer\n<< /Root 2 0 R >>\n%%EOF\n")...)
+
+	tests := []struct {
+		name            string
+		fileName        string
+		content         []byte
+		expectedPreview bool
+	}{
+		{"Foto PNG", "podium.png", testImage(t, "png", 800, 400), true},
+		{"PDF Hasil Scan", "sertifikat-scan.pdf", scannedPDF, true},
+		{"PDF Teks Saja", "sertifikat.pdf", pdfFile("/Pages 2 0 R"), false},
+	}
+
+	upload := appAs(f.student.UserID, "/achievements/upload", "POST", f.service.UploadAttachment)
+	create := appAs(f.student.UserID, "/achievements", "POST", f.service.Create)
+	thumbnail := appAs(f.advisor.UserID, "/achievements/:id/attachments/:fileId/thumbnail", "GET", f.service.DownloadAttachmentThumbnail)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := uploadFile(t, upload, "/achievements/upload", tt.fileName, "", tt.content)
+			if status != 200 {
+				t.Fatalf("Upload gagal: %d %v", status, body)
+			}
+			fileID := body["data"].(map[string]interface{})["fileId"].(string)
+
+			req := models.CreateAchievementRequest{Title: "Juara " + tt.name, Type: "competition",
+				Details:     map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+				Attachments: []models.AttachmentInput{{FileID: fileID}}}
+			status, body = decodeBody(t, create, "POST", "/achievements", req)
+			if status != 201 {
+				t.Fatalf("Create gagal: %d %v", status, body)
+			}
+			data := body["data"].(map[string]interface{})
+			attachment := data["detail"].(map[string]interface{})["attachments"].([]interface{})[0].(map[string]interface{})
+			thumbnailURL, _ := attachment["thumbnailUrl"].(string)
+			if (thumbnailURL != "") != tt.expectedPreview {
+				t.Fatalf("Expected preview=%v, got thumbnailUrl %q", tt.expectedPreview, thumbnailURL)
+			}
+
+			resp, _ := thumbnail.Test(httptest.NewRequest("GET", "/achievements/"+data["id"].(string)+"/attachments/"+fileID+"/thumbnail", nil))
+			if !tt.expectedPreview {
+				if resp.StatusCode != 404 {
+					t.Errorf("Tanpa preview harus 404, got %d", resp.StatusCode)
+				}
+				return
+			}
+			if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "image/jpeg" {
+				t.Fatalf("Expected thumbnail JPEG, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+			config, err := jpeg.DecodeConfig(resp.Body)
+			if err != nil || config.Width != 320 || config.Height != 160 {
+				t.Errorf("Thumbnail harus 320x160, got %dx%d (%v)", config.Width, config.Height, err)
+			}
+		})
+	}
+}
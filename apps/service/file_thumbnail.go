@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // decoder PNG untuk image.Decode
+)
+
+const (
+	thumbnailQuality = 80
+	// maxThumbnailSourcePixels batas ukuran gambar asli (lebar x tinggi) yang mau di-decode, mencegah decompression bomb
+	maxThumbnailSourcePixels = 40_000_000
+)
+
+var ErrImageTooLarge = errors.New("dimensi gambar terlalu besar untuk dibuat thumbnail")
+
+// MakeThumbnail mengecilkan gambar JPEG / PNG supaya sisi terpanjangnya maxSize piksel (tidak pernah diperbesar)
+// dan mengembalikannya sebagai JPEG. Bagian transparan PNG diberi latar putih
+func MakeThumbnail(content []byte, maxSize int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxThumbnailSourcePixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resizeToFit(flat, maxSize), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeToFit memperkecil dengan box filter: setiap piksel hasil adalah rata-rata kotak piksel asli yang diwakilinya
+func resizeToFit(src *image.RGBA, maxSize int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= maxSize && sh <= maxSize {
+		return src
+	}
+	dw, dh := maxSize, maxSize
+	if sw >= sh {
+		dh = max(1, sh*maxSize/sw)
+	} else {
+		dw = max(1, sw*maxSize/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*sh/dh, max((dy+1)*sh/dh, dy*sh/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*sw/dw, max((dx+1)*sw/dw, dx*sw/dw+1)
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride:]
+				for x := x0; x < x1; x++ {
+					r += int(row[x*4])
+					g += int(row[x*4+1])
+					b += int(row[x*4+2])
+					a += int(row[x*4+3])
+					n++
+				}
+			}
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// pdfOtherFilters filter yang membuat isi stream bukan lagi JPEG mentah
+var pdfOtherFilters = []string{"/FlateDecode", "/LZWDecode", "/ASCII85Decode", "/ASCIIHexDecode", "/RunLengthDecode"}
+
+// isJPEGImage true untuk stream /Subtype /Image yang hanya memakai /DCTDecode
+func (s pdfStream) isJPEGImage() bool {
+	image := false
+	names := s.names()
+	for i := 0; i+1 < len(names); i++ {
+		if names[i] == "/Subtype" && names[i+1] == "/Image" {
+			image = true
+		}
+	}
+	if !image || !s.hasFilter("/DCTDecode") {
+		return false
+	}
+	for _, filter := range pdfOtherFilters {
+		if s.hasFilter(filter) {
+			return false
+		}
+	}
+	return true
+}
+
+// PDFPreviewImage mengambil gambar JPEG terbesar yang tertanam di PDF (sertifikat hasil scan) sebagai sumber preview.
+// Merender halaman PDF butuh renderer di luar Go standar, jadi PDF tanpa gambar JPEG (teks / vektor saja) tidak punya preview.
+// nil kalau tidak ada gambar yang bisa dipakai
+func PDFPreviewImage(content []byte) []byte {
+	var best []byte
+	bestPixels := 0
+	streams, _, _ := pdfStreams(content)
+	for _, stream := range streams {
+		if !stream.isJPEGImage() {
+			continue
+		}
+		data := stream.data
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		if pixels := config.Width * config.Height; pixels > bestPixels {
+			best, bestPixels = data, pixels
+		}
+	}
+	return best
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrFileTypeNotAllowed = errors.New("format file tidak diizinkan (hanya jpg, png, pdf)")
+	ErrFileTypeMismatch   = errors.New("isi file tidak sesuai dengan ekstensinya")
+	ErrFileTooLarge       = errors.New("ukuran file melebihi batas")
+	ErrQuotaExceeded      = errors.New("kuota penyimpanan file sudah habis")
+	ErrUnsafePDF          = errors.New("PDF berisi JavaScript atau aksi otomatis yang tidak diizinkan")
+	ErrInvalidPDF         = errors.New("file PDF rusak atau tidak lengkap")
+)
+
+// fileSignatures magic bytes di awal file untuk tipe yang diizinkan
+var fileSignatures = []struct {
+	prefix      []byte
+	contentType string
+}{
+	{[]byte("\xFF\xD8\xFF"), "image/jpeg"},
+	{[]byte("\x89PNG\r\n\x1A\n"), "image/png"},
+	{[]byte("%PDF-"), "application/pdf"},
+}
+
+// SniffContentType menentukan tipe file dari isinya, bukan dari ekstensi / header client. Kosong kalau tidak dikenal
+func SniffContentType(head []byte) string {
+	for _, sig := range fileSignatures {
+		if bytes.HasPrefix(head, sig.prefix) {
+			return sig.contentType
+		}
+	}
+	return ""
+}
+
+var (
+	// pdfName nama PDF seperti /JavaScript, bisa ditulis dengan escape #xx (/J#61vaScript)
+	pdfName = regexp.MustCompile(`/[^\s/<>\[\]()%{}]+`)
+	// pdfStreamKeyword awal isi stream. Dictionary-nya tidak di-parse dengan regex (bisa bersarang / berisi hex string),
+	// cukup diambil teks dari "obj" terakhir sampai kata kunci ini
+	pdfStreamKeyword = regexp.MustCompile(`\bstream(\r\n|\n|\r)`)
+	// pdfDirectLength /Length berupa angka langsung (group 2 terisi kalau referensi "n 0 R")
+	pdfDirectLength = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	// pdfDangerousNames aksi yang bisa menjalankan kode saat PDF dibuka
+	pdfDangerousNames = map[string]bool{"/JavaScript": true, "/JS": true, "/Launch": true}
+)
+
+// maxInflatedPDFStream batas hasil dekompresi per stream, mencegah zip bomb
+const maxInflatedPDFStream = 16 << 20
+
+// pdfStream satu stream di file PDF: teks dictionary dan isi mentahnya
+type pdfStream struct {
+	dict []byte
+	data []byte
+}
+
+// pdfStreams memisahkan isi stream dari struktur object. outside berisi seluruh file tanpa isi stream.
+// ok false kalau ada stream tanpa endstream
+func pdfStreams(content []byte) (streams []pdfStream, outside []byte, ok bool) {
+	last := 0
+	for last < len(content) {
+		loc := pdfStreamKeyword.FindIndex(content[last:])
+		if loc == nil {
+			break
+		}
+		keyword, dataStart := last+loc[0], last+loc[1]
+		dataEnd := bytes.Index(content[dataStart:], []byte("endstream"))
+		if dataEnd < 0 {
+			return nil, nil, false
+		}
+		dataEnd += dataStart
+
+		dictStart := last
+		if i := bytes.LastIndex(content[last:keyword], []byte("obj")); i >= 0 {
+			dictStart = last + i
+		}
+		data := bytes.TrimSuffix(content[dataStart:dataEnd], []byte("\n"))
+		data = bytes.TrimSuffix(data, []byte("\r"))
+		streams = append(streams, pdfStream{dict: content[dictStart:keyword], data: data})
+		outside = append(outside, content[last:dataStart]...)
+		last = dataEnd
+	}
+	return streams, append(outside, content[last:]...), true
+}
+
+// names nama-nama di dictionary stream setelah escape #xx dibuka
+func (s pdfStream) names() []string {
+	var names []string
+	for _, name := range pdfName.FindAll(s.dict, -1) {
+		names = append(names, decodePDFName(name))
+	}
+	return names
+}
+
+// isImage true hanya untuk image XObject asli: /Subtype /Image, /Type (kalau ada) /XObject, dan panjang isinya
+// sama dengan /Length langsung. Object stream (/ObjStm) yang diberi /Subtype /Image tidak ikut
+func (s pdfStream) isImage() bool {
+	names := s.names()
+	image := false
+	for i, name := range names {
+		next := ""
+		if i+1 < len(names) {
+			next = names[i+1]
+		}
+		switch name {
+		case "/ObjStm":
+			return false
+		case "/Type":
+			if next != "/XObject" {
+				return false
+			}
+		case "/Subtype":
+			image = next == "/Image"
+		}
+	}
+	if !image {
+		return false
+	}
+	match := pdfDirectLength.FindSubmatch(s.dict)
+	if match == nil || len(match[2]) > 0 {
+		return false
+	}
+	length, err := strconv.Atoi(string(match[1]))
+	return err == nil && length == len(s.data)
+}
+
+// hasFilter true kalau dictionary stream menyebut filter tersebut, mis. "/FlateDecode"
+func (s pdfStream) hasFilter(filter string) bool {
+	for _, name := range s.names() {
+		if name == filter {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPDF memastikan file benar-benar PDF utuh (header dan %%EOF) dan tidak berisi JavaScript / Launch.
+// Isi stream ikut diperiksa (yang ber-FlateDecode didekompresi, termasuk object stream PDF 1.5+), kecuali isi gambar
+// supaya byte acak tidak terbaca sebagai /JS. Stream FlateDecode yang gagal didekompresi dianggap tidak aman
+func CheckPDF(content []byte) error {
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		return ErrInvalidPDF
+	}
+	tail := content
+	if len(tail) > 1024 {
+		tail = tail[len(tail)-1024:]
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return ErrInvalidPDF
+	}
+
+	streams, outside, ok := pdfStreams(content)
+	if !ok {
+		return ErrInvalidPDF
+	}
+	if hasDangerousPDFName(outside) {
+		return ErrUnsafePDF
+	}
+	for _, stream := range streams {
+		if stream.isImage() {
+			continue
+		}
+		// filter bisa berupa referensi "n 0 R", jadi data yang bisa didekompresi selalu diperiksa hasil dekompresinya
+		data := stream.data
+		if inflated, err := inflatePDFStream(data); err == nil {
+			data = inflated
+		} else if stream.hasFilter("/FlateDecode") || stream.hasFilter("/Fl") {
+			return ErrUnsafePDF
+		}
+		if hasDangerousPDFName(data) {
+			return ErrUnsafePDF
+		}
+	}
+	return nil
+}
+
+// inflatePDFStream mendekompresi stream FlateDecode, maksimal maxInflatedPDFStream
+func inflatePDFStream(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	inflated, err := io.ReadAll(io.LimitReader(reader, maxInflatedPDFStream))
+	if err != nil {
+		return nil, err
+	}
+	return inflated, nil
+}
+
+func hasDangerousPDFName(content []byte) bool {
+	for _, name := range pdfName.FindAll(content, -1) {
+		if pdfDangerousNames[decodePDFName(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// decodePDFName mengubah escape #xx pada nama PDF: /J#61vaScript -> /JavaScript
+func decodePDFName(name []byte) string {
+	if !bytes.Contains(name, []byte("#")) {
+		return string(name)
+	}
+	var decoded []byte
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if b, err := strconv.ParseUint(string(name[i+1:i+3]), 16, 8); err == nil {
+				decoded = append(decoded, byte(b))
+				i += 2
+				continue
+			}
+		}
+		decoded = append(decoded, name[i])
+	}
+	return string(decoded)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErrCodeTooManyAttempts = "TOO_MANY_ATTEMPTS"
+	ErrCodeAccountLocked   = "ACCOUNT_LOCKED"
+)
+
+type LoginGuardOptions struct {
+	// MaxUserFailures jumlah gagal per akun dalam Window sebelum akun dikunci sementara
+	MaxUserFailures int
+	// MaxIPFailures jumlah gagal per IP dalam Window, dibuat lebih longgar karena satu IP bisa dipakai banyak orang (NAT kampus)
+	MaxIPFailures   int
+	Window          time.Duration
+	LockoutDuration time.Duration
+	// BackoffBase jeda setelah gagal pertama, berlipat dua di setiap kegagalan berikutnya sampai BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+func DefaultLoginGuardOptions() LoginGuardOptions {
+	return LoginGuardOptions{
+		MaxUserFailures: 5,
+		MaxIPFailures:   20,
+		Window:          15 * time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		BackoffBase:     time.Second,
+		BackoffMax:      time.Minute,
+	}
+}
+
+// LoginBlock alasan login ditolak sebelum password dicek
+type LoginBlock struct {
+	Status     int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+// LoginGuard membatasi percobaan login gagal per akun dan per IP, lalu mencatat semuanya untuk audit
+type LoginGuard struct {
+	attempts repository.LoginAttemptStore
+	events   repository.LoginEventRepository
+	options  LoginGuardOptions
+}
+
+func NewLoginGuard(attempts repository.LoginAttemptStore, events repository.LoginEventRepository, options LoginGuardOptions) *LoginGuard {
+	return &LoginGuard{attempts: attempts, events: events, options: options}
+}
+
+// UserKey - akun yang ketemu dikunci per ID supaya tidak bisa diakali dengan ganti username <-> email
+func UserKey(user *models.User, login string) string {
+	if user != nil {
+		return "user:" + user.ID.String()
+	}
+	return "login:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (g *LoginGuard) backoff(failures int) time.Duration {
+	if g.options.BackoffBase <= 0 || failures <= 0 {
+		return 0
+	}
+	delay := g.options.BackoffBase
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if g.options.BackoffMax > 0 && delay >= g.options.BackoffMax {
+			return g.options.BackoffMax
+		}
+	}
+	return delay
+}
+
+// Check dipanggil sebelum password dicek. Hasil nil berarti boleh lanjut.
+func (g *LoginGuard) Check(ctx context.Context, userKey, ipKey string) (*LoginBlock, error) {
+	now := time.Now()
+
+	ipAttempt, err := g.attempts.Get(ctx, ipKey)
+	if err != nil {
+		return nil, err
+	}
+	if ipAttempt != nil && ipAttempt.LockedUntil != nil && now.Before(*ipAttempt.LockedUntil) {
+		return &LoginBlock{
+			Status:     429,
+			Code:       ErrCodeTooManyAttempts,
+			Message:    "Terlalu banyak percobaan login dari alamat ini, coba lagi nanti",
+			RetryAfter: ipAttempt.LockedUntil.Sub(now),
+		}, nil
+	}
+
+	userAttempt, err := g.attempts.Get(ctx, userKey)
+	if err != nil || userAttempt == nil {
+		return nil, err
+	}
+	if userAttempt.LockedUntil != nil && now.Before(*userAttempt.LockedUntil) {
+		return &LoginBlock{
+			Status:     423,
+			Code:       ErrCodeAccountLocked,
+			Message:    "Akun dikunci sementara karena terlalu banyak percobaan login gagal",
+			RetryAfter: userAttempt.LockedUntil.Sub(now),
+		}, nil
+	}
+
+	if now.Before(userAttempt.FirstFailureAt.Add(g.options.Window)) {
+		if wait := userAttempt.LastFailureAt.Add(g.backoff(userAttempt.Failures)).Sub(now); wait > 0 {
+			return &LoginBlock{
+				Status:     429,
+				Code:       ErrCodeTooManyAttempts,
+				Message:    "Terlalu cepat mencoba login lagi, tunggu sebentar",
+				RetryAfter: wait,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RecordFailure menambah hitungan gagal dan mengunci key yang sudah melewati batas
+func (g *LoginGuard) RecordFailure(ctx context.Context, userKey, ipKey string) error {
+	limits := map[string]int{userKey: g.options.MaxUserFailures, ipKey: g.options.MaxIPFailures}
+
+	for key, max := range limits {
+		attempt, err := g.attempts.RecordFailure(ctx, key, g.options.Window)
+		if err != nil {
+			return err
+		}
+		if max > 0 && attempt.Failures >= max {
+			if err := g.attempts.Lock(ctx, key, time.Now().Add(g.options.LockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordSuccess hanya mereset hitungan akun. Hitungan IP dibiarkan supaya penyerang
+// yang punya satu akun valid tidak bisa mereset limit IP-nya sendiri.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, userKey string) error {
+	return g.attempts.Reset(ctx, userKey)
+}
+
+func (g *LoginGuard) Unlock(ctx context.Context, userID uuid.UUID) error {
+	return g.attempts.Reset(ctx, UserKey(&models.User{ID: userID}, ""))
+}
+
+// Record menyimpan event audit. Gagal mencatat tidak boleh menggagalkan login, jadi error diabaikan.
+func (g *LoginGuard) Record(ctx context.Context, event models.LoginEvent) {
+	_ = g.events.Create(ctx, &event)
+}
+
+func (g *LoginGuard) Events(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	return g.events.GetRecent(ctx, filter)
+}
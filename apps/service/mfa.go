@@ -0,0 +1,611 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type MFAOptions struct {
+	// Issuer nama aplikasi yang tampil di aplikasi authenticator
+	Issuer string
+	// EncryptionKey dipakai untuk mengenkripsi secret TOTP di database
+	EncryptionKey     []byte
+	RecoveryCodeCount int
+}
+
+type MFAService struct {
+	userRepo       repository.UserRepository
+	permissionRepo repository.PermissionRepository
+	mfaRepo        repository.MFARepository
+	sessions       *SessionManager
+	loginGuard     *LoginGuard
+	options        MFAOptions
+}
+
+// NewMFAService - loginGuard boleh nil, sama seperti di AuthService
+func NewMFAService(
+	userRepo repository.UserRepository,
+	permissionRepo repository.PermissionRepository,
+	mfaRepo repository.MFARepository,
+	sessions *SessionManager,
+	loginGuard *LoginGuard,
+	options MFAOptions,
+) *MFAService {
+	if options.RecoveryCodeCount <= 0 {
+		options.RecoveryCodeCount = 10
+	}
+	return &MFAService{
+		userRepo:       userRepo,
+		permissionRepo: permissionRepo,
+		mfaRepo:        mfaRepo,
+		sessions:       sessions,
+		loginGuard:     loginGuard,
+		options:        options,
+	}
+}
+
+// totpSkew toleransi satu periode (30 detik) ke depan dan belakang
+const totpSkew = 1
+
+// LoginChallenge dipanggil Login setelah password benar. Hasil nil berarti user tidak perlu MFA.
+func (s *MFAService) LoginChallenge(ctx context.Context, user *models.User) (fiber.Map, error) {
+	roleName := ""
+	if user.Role != nil {
+		roleName = user.Role.Name
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenType := utils.TokenTypeMFAPending
+	if mfa == nil || !mfa.Enabled {
+		required, err := s.mfaRepo.IsRequiredForRole(ctx, user.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		tokenType = utils.TokenTypeMFASetup
+	}
+
+	token, err := utils.GenerateMFAToken(user.ID, roleName, tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return fiber.Map{
+		"mfa_token":          token,
+		"mfa_setup_required": tokenType == utils.TokenTypeMFASetup,
+		"expires_in":         int(utils.MFATokenTTL.Seconds()),
+	}, nil
+}
+
+func currentUserID(c *fiber.Ctx) (uuid.UUID, bool) {
+	userIDStr, _ := c.Locals("user_id").(string)
+	userID, err := uuid.Parse(userIDStr)
+	return userID, err == nil
+}
+
+// generateRecoveryCodes menghasilkan kode format xxxxx-xxxxx beserta hash-nya
+func (s *MFAService) generateRecoveryCodes() ([]string, []string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+	codes := make([]string, 0, s.options.RecoveryCodeCount)
+	hashes := make([]string, 0, s.options.RecoveryCodeCount)
+	for i := 0; i < s.options.RecoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for j := range b {
+			b[j] = alphabet[int(b[j])%len(alphabet)]
+		}
+		code := string(b[:5]) + "-" + string(b[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return utils.HashToken(normalized)
+}
+
+// verifyTOTP mengecek kode dan memastikan kode yang sama tidak bisa dipakai ulang
+func (s *MFAService) verifyTOTP(ctx context.Context, mfa *models.UserMFA, code string) (bool, error) {
+	secret, err := utils.DecryptString(s.options.EncryptionKey, mfa.SecretEncrypted)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	return s.mfaRepo.MarkStepUsed(ctx, mfa.UserID, step)
+}
+
+// verifySecondFactor menerima kode TOTP atau salah satu recovery code (sekali pakai)
+func (s *MFAService) verifySecondFactor(ctx context.Context, mfa *models.UserMFA, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		return s.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, hashRecoveryCode(recoveryCode))
+	}
+	return s.verifyTOTP(ctx, mfa, code)
+}
+
+// GetStatus godoc
+// @Summary      Status MFA
+// @Description  Menampilkan apakah MFA (TOTP) aktif untuk pengguna yang sedang login dan apakah role-nya mewajibkan MFA
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Status MFA"
+// @Failure      401  {object}  map[string]interface{} "Unauthorized"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil status MFA"
+// @Router       /auth/mfa [get]
+func (s *MFAService) GetStatus(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "user tidak di temukan"})
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	required, err := s.mfaRepo.IsRequiredForRole(ctx, user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"enabled":          mfa != nil && mfa.Enabled,
+			"required_by_role": required,
+		},
+	})
+}
+
+// Enroll godoc
+// @Summary      Mulai aktivasi MFA
+// @Description  Membuat secret TOTP baru beserta URI otpauth:// untuk di-scan aplikasi authenticator. MFA baru aktif setelah dikonfirmasi lewat /auth/mfa/confirm. Bisa memakai access token atau mfa_token dengan mfa_setup_required
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Secret dan otpauth URI"
+// @Failure      401  {object}  map[string]interface{} "Unauthorized"
+// @Failure      409  {object}  map[string]interface{} "MFA sudah aktif"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuat secret"
+// @Router       /auth/mfa/enroll [post]
+func (s *MFAService) Enroll(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "user tidak di temukan"})
+	}
+
+	existing, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if existing != nil && existing.Enabled {
+		return c.Status(409).JSON(fiber.Map{"error": "MFA sudah aktif, nonaktifkan dulu untuk mengganti perangkat"})
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat secret MFA"})
+	}
+	encrypted, err := utils.EncryptString(s.options.EncryptionKey, secret)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat secret MFA"})
+	}
+	if err := s.mfaRepo.SavePending(ctx, userID, encrypted); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan secret MFA"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Scan QR / masukkan secret di aplikasi authenticator lalu konfirmasi dengan kode 6 digit",
+		"data": fiber.Map{
+			"secret":      secret,
+			"otpauth_uri": utils.TOTPProvisioningURI(s.options.Issuer, user.Username, secret),
+		},
+	})
+}
+
+// Confirm godoc
+// @Summary      Konfirmasi aktivasi MFA
+// @Description  Mengaktifkan MFA dengan kode 6 digit pertama dari aplikasi authenticator dan mengembalikan recovery code (hanya ditampilkan sekali). Jika dipanggil dengan mfa_token (mfa_setup), respons juga berisi token login
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object}  map[string]interface{} "MFA aktif beserta recovery code"
+// @Failure      400  {object}  map[string]interface{} "Belum enroll atau kode salah"
+// @Failure      401  {object}  map[string]interface{} "Unauthorized"
+// @Failure      409  {object}  map[string]interface{} "MFA sudah aktif"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengaktifkan MFA"
+// @Router       /auth/mfa/confirm [post]
+func (s *MFAService) Confirm(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode wajib diisi"})
+	}
+
+	ctx := c.Context()
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if mfa == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Belum ada proses enroll MFA"})
+	}
+	if mfa.Enabled {
+		return c.Status(409).JSON(fiber.Map{"error": "MFA sudah aktif"})
+	}
+
+	valid, err := s.verifyTOTP(ctx, mfa, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa kode MFA"})
+	}
+	if !valid {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode MFA salah"})
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat recovery code"})
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan recovery code"})
+	}
+	if err := s.mfaRepo.Enable(ctx, userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengaktifkan MFA"})
+	}
+
+	data := fiber.Map{"recovery_codes": codes}
+
+	// user yang dipaksa enroll saat login langsung mendapat token supaya tidak perlu login ulang
+	if tokenType, _ := c.Locals("token_type").(string); tokenType == utils.TokenTypeMFASetup {
+		tokenID, _ := c.Locals("token_id").(string)
+		expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+		if err := s.sessions.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut token setup MFA"})
+		}
+
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil || user == nil {
+			return c.Status(404).JSON(fiber.Map{"error": "user tidak di temukan"})
+		}
+		loginData, err := loginSuccessData(c, s.sessions, s.permissionRepo, user, "")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+		}
+		for k, v := range loginData {
+			data[k] = v
+		}
+		recordLoginEvent(c, s.loginGuard, user, user.Username, models.LoginOutcomeSuccess)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "MFA berhasil diaktifkan, simpan recovery code di tempat aman",
+		"data":    data,
+	})
+}
+
+// VerifyLogin godoc
+// @Summary      Login langkah kedua (MFA)
+// @Description  Menukar mfa_token dari /auth/Login dengan token login setelah kode TOTP atau recovery code dicek
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MFALoginRequest true "mfa_token dan kode TOTP / recovery code"
+// @Success      200  {object}  map[string]interface{} "Login berhasil dengan token"
+// @Failure      400  {object}  map[string]interface{} "Request tidak valid"
+// @Failure      401  {object}  map[string]interface{} "mfa_token tidak valid atau kode salah"
+// @Failure      403  {object}  map[string]interface{} "Akun nonaktif (code: ACCOUNT_INACTIVE)"
+// @Failure      423  {object}  map[string]interface{} "Akun dikunci sementara (code: ACCOUNT_LOCKED)"
+// @Failure      429  {object}  map[string]interface{} "Terlalu banyak percobaan (code: TOO_MANY_ATTEMPTS)"
+// @Router       /auth/login/mfa [post]
+func (s *MFAService) VerifyLogin(c *fiber.Ctx) error {
+	var req models.MFALoginRequest
+	if err := c.BodyParser(&req); err != nil || req.MFAToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		return c.Status(400).JSON(fiber.Map{"error": "mfa_token dan kode wajib diisi"})
+	}
+
+	claims, err := utils.ValidateTokenType(req.MFAToken, utils.TokenTypeMFAPending)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "mfa_token tidak valid atau kadaluwarsa, silakan login ulang"})
+	}
+
+	ctx := c.Context()
+	if err := s.sessions.CheckOneTimeToken(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenAlreadyUsed) {
+			return c.Status(401).JSON(fiber.Map{"error": "mfa_token sudah dipakai, silakan login ulang"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa mfa_token"})
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+	if !user.IsActive {
+		return c.Status(403).JSON(fiber.Map{"error": "Akun sudah dinonaktifkan", "code": ErrCodeAccountInactive})
+	}
+
+	userKey, ipKey := UserKey(user, ""), IPKey(c.IP())
+	if s.loginGuard != nil {
+		block, err := s.loginGuard.Check(ctx, userKey, ipKey)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa percobaan login"})
+		}
+		if block != nil {
+			return respondLoginBlocked(c, block)
+		}
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if mfa == nil || !mfa.Enabled {
+		return c.Status(401).JSON(fiber.Map{"error": "MFA tidak aktif untuk akun ini, silakan login ulang"})
+	}
+
+	valid, err := s.verifySecondFactor(ctx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa kode MFA"})
+	}
+	if !valid {
+		recordLoginEvent(c, s.loginGuard, user, user.Username, models.LoginOutcomeMFAFailed)
+		if s.loginGuard != nil {
+			if err := s.loginGuard.RecordFailure(ctx, userKey, ipKey); err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat percobaan login"})
+			}
+		}
+		return c.Status(401).JSON(fiber.Map{"error": "Kode MFA salah"})
+	}
+
+	if err := s.sessions.ConsumeOneTimeToken(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenAlreadyUsed) {
+			return c.Status(401).JSON(fiber.Map{"error": "mfa_token sudah dipakai, silakan login ulang"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa mfa_token"})
+	}
+
+	if s.loginGuard != nil {
+		if err := s.loginGuard.RecordSuccess(ctx, userKey); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mencatat percobaan login"})
+		}
+	}
+
+	data, err := loginSuccessData(c, s.sessions, s.permissionRepo, user, req.DeviceLabel)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal generate token"})
+	}
+	recordLoginEvent(c, s.loginGuard, user, user.Username, models.LoginOutcomeSuccess)
+
+	return c.JSON(fiber.Map{"status": "success", "data": data})
+}
+
+// Disable godoc
+// @Summary      Nonaktifkan MFA
+// @Description  Mematikan MFA milik pengguna yang sedang login. Wajib password dan kode TOTP. Ditolak jika role pengguna mewajibkan MFA
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.MFADisableRequest true "Password dan kode TOTP"
+// @Success      200  {object}  map[string]interface{} "MFA dinonaktifkan"
+// @Failure      400  {object}  map[string]interface{} "MFA belum aktif atau request tidak valid"
+// @Failure      401  {object}  map[string]interface{} "Password atau kode salah"
+// @Failure      403  {object}  map[string]interface{} "Role mewajibkan MFA"
+// @Failure      500  {object}  map[string]interface{} "Gagal menonaktifkan MFA"
+// @Router       /auth/mfa/disable [post]
+func (s *MFAService) Disable(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.MFADisableRequest
+	if err := c.BodyParser(&req); err != nil || req.Password == "" || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Password dan kode wajib diisi"})
+	}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "user tidak di temukan"})
+	}
+	if !utils.CheckPassword(req.Password, user.PasswordHash) {
+		return c.Status(401).JSON(fiber.Map{"error": "Password salah"})
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if mfa == nil || !mfa.Enabled {
+		return c.Status(400).JSON(fiber.Map{"error": "MFA belum aktif"})
+	}
+
+	required, err := s.mfaRepo.IsRequiredForRole(ctx, user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if required {
+		return c.Status(403).JSON(fiber.Map{"error": "Role kamu mewajibkan MFA, tidak bisa dinonaktifkan"})
+	}
+
+	valid, err := s.verifyTOTP(ctx, mfa, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa kode MFA"})
+	}
+	if !valid {
+		return c.Status(401).JSON(fiber.Map{"error": "Kode MFA salah"})
+	}
+
+	if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menonaktifkan MFA"})
+	}
+
+	return c.JSON(fiber.Map{"message": "MFA berhasil dinonaktifkan"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Buat ulang recovery code
+// @Description  Mengganti semua recovery code MFA dengan yang baru. Recovery code lama langsung tidak berlaku
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.MFACodeRequest true "Kode TOTP"
+// @Success      200  {object}  map[string]interface{} "Recovery code baru"
+// @Failure      400  {object}  map[string]interface{} "MFA belum aktif"
+// @Failure      401  {object}  map[string]interface{} "Kode salah"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuat recovery code"
+// @Router       /auth/mfa/recovery-codes [post]
+func (s *MFAService) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Kode wajib diisi"})
+	}
+
+	ctx := c.Context()
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil status MFA"})
+	}
+	if mfa == nil || !mfa.Enabled {
+		return c.Status(400).JSON(fiber.Map{"error": "MFA belum aktif"})
+	}
+
+	valid, err := s.verifyTOTP(ctx, mfa, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa kode MFA"})
+	}
+	if !valid {
+		return c.Status(401).JSON(fiber.Map{"error": "Kode MFA salah"})
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat recovery code"})
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan recovery code"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Recovery code baru berhasil dibuat",
+		"data":    fiber.Map{"recovery_codes": codes},
+	})
+}
+
+// ResetUserMFA godoc
+// @Summary      Reset MFA pengguna
+// @Description  Menghapus MFA pengguna lain, misalnya karena HP hilang dan recovery code habis (khusus Admin). Jika role-nya mewajibkan MFA, pengguna diminta enroll ulang saat login berikutnya
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID pengguna (UUID)"
+// @Success      200  {object}  map[string]interface{} "MFA pengguna direset"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "User tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal reset MFA"
+// @Router       /auth/mfa/users/{id} [delete]
+func (s *MFAService) ResetUserMFA(c *fiber.Ctx) error {
+	userUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	ctx := c.Context()
+	if user, err := s.userRepo.GetByID(ctx, userUUID); err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User tidak ditemukan"})
+	}
+
+	if err := s.mfaRepo.Delete(ctx, userUUID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal reset MFA"})
+	}
+
+	return c.JSON(fiber.Map{"message": "MFA pengguna berhasil direset"})
+}
+
+// SetRoleRequirement godoc
+// @Summary      Atur kewajiban MFA per role
+// @Description  Mewajibkan atau tidak mewajibkan MFA untuk semua pengguna dengan role tertentu (khusus Admin). Berlaku mulai login berikutnya
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Param        request body models.RoleMFARequirementRequest true "Wajib MFA atau tidak"
+// @Success      200  {object}  map[string]interface{} "Kewajiban MFA diperbarui"
+// @Failure      400  {object}  map[string]interface{} "ID atau body tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Role tidak ditemukan"
+// @Failure      500  {object}  map[string]interface{} "Gagal menyimpan"
+// @Router       /auth/mfa/roles/{id} [put]
+func (s *MFAService) SetRoleRequirement(c *fiber.Ctx) error {
+	roleUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Role ID tidak valid"})
+	}
+
+	var req models.RoleMFARequirementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Body tidak valid"})
+	}
+
+	found, err := s.mfaRepo.SetRoleRequirement(c.Context(), roleUUID, req.Required)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan kewajiban MFA"})
+	}
+	if !found {
+		return c.Status(404).JSON(fiber.Map{"error": "Role tidak ditemukan"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Kewajiban MFA role berhasil diperbarui",
+		"data":    fiber.Map{"role_id": roleUUID, "mfa_required": req.Required},
+	})
+}
@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// secret ASCII "12345678901234567890" dari lampiran B RFC 6238, diambil 6 digit terakhir
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := utils.TOTPCodeAt(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("T=%d dapat %s, harapan %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func decodeBody(t *testing.T, app *fiber.App, method, path string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Error request: %v", err)
+	}
+
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestMFA_EnrollAndTwoStepLogin(t *testing.T) {
+	t.Setenv("JWT_SECRET", "rahasia-test")
+
+	mockUserRepo := mocks.NewManualMockUserRepo()
+	mockPermRepo := mocks.NewManualMockPermissionRepo()
+	mfaRepo := mocks.NewManualMockMFARepo()
+	sessions := service.NewSessionManager(mocks.NewManualMockRefreshTokenRepo(), repository.NewMemoryTokenRevocationStore(utils.AccessTokenTTL))
+
+	mfaService := service.NewMFAService(mockUserRepo, mockPermRepo, mfaRepo, sessions, nil, service.MFAOptions{
+		Issuer:        "Test",
+		EncryptionKey: []byte("kunci-test"),
+	})
+	authService := service.NewAuthService(mockUserRepo, mockPermRepo, sessions, nil, mfaService)
+
+	dosenRoleID := uuid.New()
+	mfaRepo.RegisterRole(dosenRoleID)
+
+	passHash, _ := utils.HashPassword("rahasia123")
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     "dosen_mfa",
+		PasswordHash: passHash,
+		IsActive:     true,
+		RoleID:       dosenRoleID,
+		Role:         &models.Role{ID: dosenRoleID, Name: "Dosen"},
+	}
+	mockUserRepo.Create(nil, user)
+
+	app := fiber.New()
+	app.Post("/login", authService.Login)
+	app.Post("/login/mfa", mfaService.VerifyLogin)
+	asUser := func(c *fiber.Ctx) error {
+		c.Locals("user_id", user.ID.String())
+		c.Locals("token_type", utils.TokenTypeAccess)
+		return c.Next()
+	}
+	app.Post("/mfa/enroll", asUser, mfaService.Enroll)
+	app.Post("/mfa/confirm", asUser, mfaService.Confirm)
+
+	loginBody := map[string]string{"username": "dosen_mfa", "password": "rahasia123"}
+
+	// belum enroll dan role belum wajib MFA: login langsung dapat token
+	if status, body := decodeBody(t, app, "POST", "/login", loginBody); status != 200 || body["status"] != "success" {
+		t.Fatalf("Login tanpa MFA harus sukses, dapat %d %v", status, body)
+	}
+
+	// role diwajibkan MFA: login mendapat token setup
+	mfaRepo.SetRoleRequirement(nil, dosenRoleID, true)
+	_, body := decodeBody(t, app, "POST", "/login", loginBody)
+	data, _ := body["data"].(map[string]interface{})
+	if body["status"] != "mfa_required" || data["mfa_setup_required"] != true {
+		t.Fatalf("Role wajib MFA harus minta setup, dapat %v", body)
+	}
+
+	_, body = decodeBody(t, app, "POST", "/mfa/enroll", nil)
+	data, _ = body["data"].(map[string]interface{})
+	secret, _ := data["secret"].(string)
+	if secret == "" {
+		t.Fatalf("Enroll harus mengembalikan secret, dapat %v", body)
+	}
+
+	now := time.Now()
+	code, _ := utils.TOTPCodeAt(secret, now)
+	if status, _ := decodeBody(t, app, "POST", "/mfa/confirm", map[string]string{"code": "000000"}); status != 400 {
+		t.Errorf("Konfirmasi dengan kode salah harus 400, dapat %d", status)
+	}
+	status, body := decodeBody(t, app, "POST", "/mfa/confirm", map[string]string{"code": code})
+	if status != 200 {
+		t.Fatalf("Konfirmasi harus 200, dapat %d %v", status, body)
+	}
+	data, _ = body["data"].(map[string]interface{})
+	recoveryCodes, _ := data["recovery_codes"].([]interface{})
+	if len(recoveryCodes) != 10 {
+		t.Fatalf("Harus ada 10 recovery code, dapat %d", len(recoveryCodes))
+	}
+
+	// MFA aktif: login mengembalikan mfa_pending
+	_, body = decodeBody(t, app, "POST", "/login", loginBody)
+	data, _ = body["data"].(map[string]interface{})
+	mfaToken, _ := data["mfa_token"].(string)
+	if body["status"] != "mfa_required" || data["mfa_setup_required"] != false || mfaToken == "" {
+		t.Fatalf("Login harus minta kode MFA, dapat %v", body)
+	}
+
+	if status, _ := decodeBody(t, app, "POST", "/login/mfa", map[string]string{"mfa_token": mfaToken, "code": code}); status != 401 {
+		t.Errorf("Kode yang sudah dipakai saat konfirmasi harus ditolak, dapat %d", status)
+	}
+
+	nextCode, _ := utils.TOTPCodeAt(secret, now.Add(utils.TOTPPeriod*time.Second))
+	status, body = decodeBody(t, app, "POST", "/login/mfa", map[string]string{"mfa_token": mfaToken, "code": nextCode})
+	if status != 200 || body["status"] != "success" {
+		t.Fatalf("Login MFA harus sukses, dapat %d %v", status, body)
+	}
+
+	// mfa_token hanya sekali pakai, recovery code tidak ikut terbuang
+	recovery := recoveryCodes[0].(string)
+	if status, _ := decodeBody(t, app, "POST", "/login/mfa", map[string]string{"mfa_token": mfaToken, "recovery_code": recovery}); status != 401 {
+		t.Errorf("mfa_token yang sudah dipakai harus ditolak, dapat %d", status)
+	}
+
+	// recovery code hanya bisa dipakai sekali
+	for i, want := range []int{200, 401} {
+		_, body = decodeBody(t, app, "POST", "/login", loginBody)
+		data, _ = body["data"].(map[string]interface{})
+		mfaToken, _ = data["mfa_token"].(string)
+		if status, _ := decodeBody(t, app, "POST", "/login/mfa", map[string]string{"mfa_token": mfaToken, "recovery_code": recovery}); status != want {
+			t.Errorf("Pemakaian recovery code ke-%d harus %d, dapat %d", i+1, want, status)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/notification"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PasswordResetOptions struct {
+	TokenTTL time.Duration
+	// ResetURL adalah alamat halaman reset di frontend, token ditempel sebagai query ?token=
+	ResetURL string
+}
+
+type PasswordService struct {
+	userRepo  repository.UserRepository
+	resetRepo repository.PasswordResetRepository
+	sessions  *SessionManager
+	notifier  notification.Notifier
+	policy    utils.PasswordPolicy
+	options   PasswordResetOptions
+}
+
+func NewPasswordService(
+	userRepo repository.UserRepository,
+	resetRepo repository.PasswordResetRepository,
+	sessions *SessionManager,
+	notifier notification.Notifier,
+	policy utils.PasswordPolicy,
+	options PasswordResetOptions,
+) *PasswordService {
+	return &PasswordService{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		sessions:  sessions,
+		notifier:  notifier,
+		policy:    policy,
+		options:   options,
+	}
+}
+
+// ChangePassword godoc
+// @Summary      Ganti password
+// @Description  Mengganti password pengguna yang sedang login. Wajib menyertakan password lama. Semua sesi lain otomatis dicabut
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.ChangePasswordRequest true "Password lama dan password baru"
+// @Success      200  {object}  map[string]interface{} "Password berhasil diganti"
+// @Failure      400  {object}  map[string]interface{} "Password baru tidak memenuhi kebijakan"
+// @Failure      401  {object}  map[string]interface{} "Password lama salah"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengganti password"
+// @Router       /auth/password/change [post]
+func (s *PasswordService) ChangePassword(c *fiber.Ctx) error {
+	userIDStr, _ := c.Locals("user_id").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body tidak valid"})
+	}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "user tidak di temukan"})
+	}
+
+	if !utils.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		return c.Status(401).JSON(fiber.Map{"error": "Password lama salah"})
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return c.Status(400).JSON(fiber.Map{"error": "Password baru harus berbeda dengan password lama"})
+	}
+
+	if violations := s.policy.Validate(req.NewPassword); len(violations) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Password tidak memenuhi kebijakan", "errors": violations})
+	}
+
+	if err := s.setPassword(c, userID, req.NewPassword); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password berhasil diganti, silakan login ulang"})
+}
+
+// ForgotPassword godoc
+// @Summary      Lupa password
+// @Description  Mengirim token reset password ke email pengguna. Respons selalu sama walaupun email tidak terdaftar
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ForgotPasswordRequest true "Email akun"
+// @Success      200  {object}  map[string]interface{} "Instruksi reset dikirim jika email terdaftar"
+// @Failure      400  {object}  map[string]interface{} "Email kosong"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuat token reset"
+// @Router       /auth/password/forgot [post]
+func (s *PasswordService) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email wajib diisi"})
+	}
+
+	genericResponse := fiber.Map{"message": "Jika email terdaftar, instruksi reset password sudah dikirim"}
+
+	ctx := c.Context()
+	user, err := s.userRepo.GetByUsernameOrEmail(ctx, req.Email)
+	if err != nil || user == nil || !user.IsActive || user.Email != req.Email {
+		return c.JSON(genericResponse)
+	}
+
+	// cukup satu token aktif per user
+	if err := s.resetRepo.InvalidateByUserID(ctx, user.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat token reset"})
+	}
+
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat token reset"})
+	}
+
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(s.options.TokenTTL),
+	}
+	if err := s.resetRepo.Create(ctx, resetToken); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat token reset"})
+	}
+
+	msg := notification.Message{
+		To:      user.Email,
+		Subject: "Reset password Sistem Pelaporan Prestasi",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nBuka link berikut untuk membuat password baru (berlaku %s):\n%s?token=%s\n\nAbaikan pesan ini jika kamu tidak meminta reset password.",
+			user.FullName, s.options.TokenTTL, s.options.ResetURL, rawToken,
+		),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengirim instruksi reset password"})
+	}
+
+	return c.JSON(genericResponse)
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Mengganti password memakai token reset sekali pakai dari email. Semua sesi pengguna otomatis dicabut
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ResetPasswordRequest true "Token reset dan password baru"
+// @Success      200  {object}  map[string]interface{} "Password berhasil direset"
+// @Failure      400  {object}  map[string]interface{} "Token tidak valid/expired atau password tidak memenuhi kebijakan"
+// @Failure      500  {object}  map[string]interface{} "Gagal reset password"
+// @Router       /auth/password/reset [post]
+func (s *PasswordService) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Token wajib diisi"})
+	}
+
+	if violations := s.policy.Validate(req.NewPassword); len(violations) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Password tidak memenuhi kebijakan", "errors": violations})
+	}
+
+	ctx := c.Context()
+	resetToken, err := s.resetRepo.GetByHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa token reset"})
+	}
+	if resetToken == nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return c.Status(400).JSON(fiber.Map{"error": "Token reset tidak valid atau sudah kadaluwarsa"})
+	}
+
+	used, err := s.resetRepo.MarkUsed(ctx, resetToken.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa token reset"})
+	}
+	if !used {
+		return c.Status(400).JSON(fiber.Map{"error": "Token reset tidak valid atau sudah kadaluwarsa"})
+	}
+
+	if err := s.setPassword(c, resetToken.UserID, req.NewPassword); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password berhasil direset, silakan login dengan password baru"})
+}
+
+// setPassword menyimpan hash baru lalu mencabut semua refresh token dan access token lama
+func (s *PasswordService) setPassword(c *fiber.Ctx, userID uuid.UUID, password string) error {
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("password gagal di hash")
+	}
+
+	ctx := c.Context()
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return fmt.Errorf("gagal menyimpan password baru")
+	}
+	if err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		return fmt.Errorf("password diganti tapi gagal mencabut sesi lama")
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/notification"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// captureNotifier menyimpan pesan terakhir supaya test bisa mengambil token reset
+type captureNotifier struct {
+	messages []notification.Message
+}
+
+func (n *captureNotifier) Send(ctx context.Context, msg notification.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func setupPasswordService(t *testing.T) (*fiber.App, *mocks.ManualMockUserRepo, *mocks.ManualMockRefreshTokenRepo, *captureNotifier, *models.User) {
+	t.Helper()
+
+	mockUserRepo := mocks.NewManualMockUserRepo()
+	refreshRepo := mocks.NewManualMockRefreshTokenRepo()
+	sessions := service.NewSessionManager(refreshRepo, repository.NewMemoryTokenRevocationStore(utils.AccessTokenTTL))
+	notifier := &captureNotifier{}
+
+	passwordService := service.NewPasswordService(
+		mockUserRepo,
+		mocks.NewManualMockPasswordResetRepo(),
+		sessions,
+		notifier,
+		utils.DefaultPasswordPolicy(),
+		service.PasswordResetOptions{TokenTTL: 30 * time.Minute, ResetURL: "http://localhost/reset"},
+	)
+
+	passHash, _ := utils.HashPassword("rahasia123")
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     "budi",
+		Email:        "budi@univ.ac.id",
+		FullName:     "Budi",
+		PasswordHash: passHash,
+		IsActive:     true,
+		Role:         &models.Role{Name: "Mahasiswa"},
+	}
+	mockUserRepo.Create(nil, user)
+
+	app := fiber.New()
+	app.Post("/password/change", func(c *fiber.Ctx) error {
+		c.Locals("user_id", user.ID.String())
+		return c.Next()
+	}, passwordService.ChangePassword)
+	app.Post("/password/forgot", passwordService.ForgotPassword)
+	app.Post("/password/reset", passwordService.ResetPassword)
+
+	return app, mockUserRepo, refreshRepo, notifier, user
+}
+
+func postJSON(t *testing.T, app *fiber.App, path string, body interface{}) int {
+	t.Helper()
+	bodyBytes, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Error request: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestChangePassword_TableDriven(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputBody      map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "Ganti Password Sukses",
+			inputBody:      map[string]string{"current_password": "rahasia123", "new_password": "baruBanget99"},
+			expectedStatus: 200,
+		},
+		{
+			name:           "Password Lama Salah",
+			inputBody:      map[string]string{"current_password": "salahbanget", "new_password": "baruBanget99"},
+			expectedStatus: 401,
+		},
+		{
+			name:           "Password Baru Terlalu Pendek",
+			inputBody:      map[string]string{"current_password": "rahasia123", "new_password": "a1"},
+			expectedStatus: 400,
+		},
+		{
+			name:           "Password Baru Terlalu Umum",
+			inputBody:      map[string]string{"current_password": "rahasia123", "new_password": "password123"},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, userRepo, refreshRepo, _, user := setupPasswordService(t)
+			refreshRepo.Create(context.Background(), &models.RefreshToken{
+				UserID:    user.ID,
+				FamilyID:  uuid.New(),
+				TokenHash: "lama",
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+
+			status := postJSON(t, app, "/password/change", tt.inputBody)
+			if status != tt.expectedStatus {
+				t.Fatalf("Status salah! Dapat %d, Harapan %d", status, tt.expectedStatus)
+			}
+
+			stored, _ := userRepo.GetByID(context.Background(), user.ID)
+			changed := utils.CheckPassword(tt.inputBody["new_password"], stored.PasswordHash)
+			active, _ := refreshRepo.GetActiveByUserID(context.Background(), user.ID)
+
+			if tt.expectedStatus == 200 {
+				if !changed {
+					t.Error("Password baru seharusnya tersimpan")
+				}
+				if len(active) != 0 {
+					t.Errorf("Semua sesi seharusnya dicabut, masih ada %d", len(active))
+				}
+			} else if changed {
+				t.Error("Password tidak boleh berubah kalau request ditolak")
+			}
+		})
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	app, userRepo, _, notifier, user := setupPasswordService(t)
+
+	// email tidak terdaftar tetap 200 dan tidak mengirim apa-apa
+	if status := postJSON(t, app, "/password/forgot", map[string]string{"email": "hantu@univ.ac.id"}); status != 200 {
+		t.Fatalf("Forgot untuk email asing harus 200, dapat %d", status)
+	}
+	if len(notifier.messages) != 0 {
+		t.Fatal("Tidak boleh ada notifikasi untuk email yang tidak terdaftar")
+	}
+
+	if status := postJSON(t, app, "/password/forgot", map[string]string{"email": user.Email}); status != 200 {
+		t.Fatalf("Forgot harus 200, dapat %d", status)
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("Harus ada 1 notifikasi, dapat %d", len(notifier.messages))
+	}
+
+	body := notifier.messages[0].Body
+	idx := strings.Index(body, "?token=")
+	if idx < 0 {
+		t.Fatalf("Link reset tidak ditemukan di pesan: %s", body)
+	}
+	token := strings.Fields(body[idx+len("?token="):])[0]
+
+	if status := postJSON(t, app, "/password/reset", map[string]string{"token": token, "new_password": "12345678"}); status != 400 {
+		t.Errorf("Password lemah harus ditolak, dapat %d", status)
+	}
+
+	if status := postJSON(t, app, "/password/reset", map[string]string{"token": token, "new_password": "resetBaru2024"}); status != 200 {
+		t.Fatalf("Reset harus 200, dapat %d", status)
+	}
+
+	stored, _ := userRepo.GetByID(context.Background(), user.ID)
+	if !utils.CheckPassword("resetBaru2024", stored.PasswordHash) {
+		t.Error("Password hasil reset tidak tersimpan")
+	}
+
+	// token sekali pakai
+	if status := postJSON(t, app, "/password/reset", map[string]string{"token": token, "new_password": "lainLagi2024"}); status != 400 {
+		t.Errorf("Token yang sudah dipakai harus ditolak, dapat %d", status)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"uas-pelaporan-prestasi-mahasiswa/apps/models"
 	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -111,6 +112,7 @@ func (s *PermissionService) AssignToRole(c *fiber.Ctx) error {
 	if err := s.permissionRepo.AssignToRole(ctx, roleID, permID); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal assign permission"})
 	}
+	middleware.InvalidatePermissionCache()
 
 	return c.Status(200).JSON(fiber.Map{
 		"message": "Permission berhasil ditambahkan ke Role",
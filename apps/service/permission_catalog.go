@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+)
+
+// PermissionCatalog daftar resmi pasangan resource:action yang dipakai di routes.
+// Tambah entri di sini setiap ada RequirePermission baru, lalu jalankan sync.
+var PermissionCatalog = []models.Permission{
+	{Resource: "user", Action: "read", Description: "Melihat data user dan log login"},
+	{Resource: "user", Action: "create", Description: "Membuat user dan undangan"},
+	{Resource: "user", Action: "update", Description: "Mengubah profil user lain"},
+	{Resource: "user", Action: "delete", Description: "Menghapus user"},
+	{Resource: "user", Action: "assign_role", Description: "Mengganti role user"},
+	{Resource: "user", Action: "manage_status", Description: "Menonaktifkan, mengaktifkan dan membuka kunci user"},
+
+	{Resource: "student", Action: "read", Description: "Melihat data mahasiswa"},
+	{Resource: "student", Action: "read_own", Description: "Melihat profil dan transkrip mahasiswa milik sendiri"},
+	{Resource: "student", Action: "create", Description: "Membuat data mahasiswa"},
+	{Resource: "student", Action: "create_own", Description: "Membuat profil mahasiswa untuk akun sendiri"},
+	{Resource: "student", Action: "update", Description: "Mengubah data mahasiswa"},
+	{Resource: "student", Action: "delete", Description: "Menghapus data mahasiswa"},
+	{Resource: "student", Action: "assign_advisor", Description: "Menetapkan dosen wali"},
+
+	{Resource: "lecturer", Action: "read", Description: "Melihat data dosen"},
+	{Resource: "lecturer", Action: "read_own", Description: "Melihat profil dosen milik sendiri"},
+	{Resource: "lecturer", Action: "create", Description: "Membuat data dosen"},
+	{Resource: "lecturer", Action: "create_own", Description: "Membuat profil dosen untuk akun sendiri"},
+	{Resource: "lecturer", Action: "update", Description: "Mengubah data dosen"},
+	{Resource: "lecturer", Action: "delete", Description: "Menghapus data dosen"},
+
+	{Resource: "achievement", Action: "read", Description: "Melihat semua prestasi"},
+	{Resource: "achievement", Action: "read_own", Description: "Melihat prestasi milik sendiri"},
+	{Resource: "achievement", Action: "read_raw", Description: "Melihat raw data prestasi di MongoDB (debugging)"},
+	{Resource: "achievement", Action: "create", Description: "Melaporkan prestasi dan upload lampiran"},
+	{Resource: "achievement", Action: "update", Description: "Mengubah prestasi"},
+	{Resource: "achievement", Action: "delete", Description: "Menghapus prestasi"},
+	{Resource: "achievement", Action: "submit", Description: "Mengajukan prestasi untuk diverifikasi"},
+	{Resource: "achievement", Action: "verify", Description: "Memverifikasi atau menolak prestasi"},
+	{Resource: "achievement", Action: "rescore", Description: "Menghitung ulang poin prestasi verified"},
+
+	{Resource: "achievement_type", Action: "manage", Description: "Menambah dan mengubah tipe prestasi beserta field details"},
+
+	{Resource: "report", Action: "read", Description: "Melihat statistik dan laporan"},
+
+	{Resource: "permission", Action: "read", Description: "Melihat daftar permission"},
+	{Resource: "permission", Action: "manage", Description: "Membuat dan meng-assign permission"},
+
+	{Resource: "role", Action: "read", Description: "Melihat role dan permission-nya"},
+	{Resource: "role", Action: "manage", Description: "Membuat, mengubah dan menghapus role"},
+}
+
+// DefaultRoleBundles permission awal untuk role bawaan. Nilai "*" berarti semua isi katalog.
+// Bundle hanya menambah; permission yang diberikan admin lewat /roles tidak dicabut saat sync.
+func DefaultRoleBundles(adminRole, lecturerRole, studentRole string) map[string][]string {
+	return map[string][]string{
+		adminRole: {"*"},
+		lecturerRole: {
+			"student:read", "lecturer:read", "lecturer:read_own", "lecturer:create_own",
+			"achievement:read", "achievement:verify",
+			"report:read",
+		},
+		studentRole: {
+			"student:read_own", "student:create_own",
+			"achievement:read_own", "achievement:create", "achievement:update",
+			"achievement:delete", "achievement:submit",
+		},
+	}
+}
+
+type PermissionSyncOptions struct {
+	Bundles map[string][]string
+	// Prune menghapus permission di database yang tidak ada di katalog. Tanpa Prune hanya dilaporkan.
+	Prune bool
+	// DryRun hanya menghitung laporan tanpa menulis ke database
+	DryRun bool
+}
+
+// PermissionSyncReport ringkasan perubahan hasil sync
+type PermissionSyncReport struct {
+	Added        []string            `json:"added"`
+	Removed      []string            `json:"removed"`
+	Unknown      []string            `json:"unknown"`
+	RolesCreated []string            `json:"roles_created"`
+	Granted      map[string][]string `json:"granted"`
+}
+
+// Changed false berarti database sudah sama dengan katalog
+func (r *PermissionSyncReport) Changed() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.RolesCreated) > 0 || len(r.Granted) > 0
+}
+
+func (r *PermissionSyncReport) String() string {
+	if !r.Changed() && len(r.Unknown) == 0 {
+		return "permission sudah sesuai katalog, tidak ada perubahan"
+	}
+
+	var b strings.Builder
+	writeList := func(label string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&b, "%s: %s\n", label, strings.Join(items, ", "))
+		}
+	}
+	writeList("permission ditambah", r.Added)
+	writeList("permission dihapus", r.Removed)
+	writeList("permission di luar katalog (jalankan dengan --prune untuk menghapus)", r.Unknown)
+	writeList("role dibuat", r.RolesCreated)
+
+	roles := make([]string, 0, len(r.Granted))
+	for role := range r.Granted {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		writeList("permission baru untuk "+role, r.Granted[role])
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// PermissionSyncer menyamakan tabel permissions dan role_permissions dengan PermissionCatalog.
+// Aman dijalankan berulang kali (setiap deploy); run kedua tidak mengubah apa pun.
+type PermissionSyncer struct {
+	permissionRepo repository.PermissionRepository
+	roleRepo       repository.RoleRepository
+	options        PermissionSyncOptions
+}
+
+func NewPermissionSyncer(permissionRepo repository.PermissionRepository, roleRepo repository.RoleRepository, options PermissionSyncOptions) *PermissionSyncer {
+	return &PermissionSyncer{permissionRepo: permissionRepo, roleRepo: roleRepo, options: options}
+}
+
+func (s *PermissionSyncer) Sync(ctx context.Context) (*PermissionSyncReport, error) {
+	report := &PermissionSyncReport{Granted: make(map[string][]string)}
+
+	existing, err := s.permissionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca permission: %w", err)
+	}
+	byKey := make(map[string]models.Permission, len(existing))
+	for _, p := range existing {
+		byKey[strings.ToLower(p.Key())] = p
+	}
+
+	catalogKeys := make(map[string]bool, len(PermissionCatalog))
+	for _, entry := range PermissionCatalog {
+		key := strings.ToLower(entry.Key())
+		catalogKeys[key] = true
+		if _, ok := byKey[key]; ok {
+			continue
+		}
+
+		perm := entry
+		perm.Name = entry.Key()
+		if !s.options.DryRun {
+			if err := s.permissionRepo.Create(ctx, &perm); err != nil {
+				return nil, fmt.Errorf("gagal membuat permission %s: %w", perm.Key(), err)
+			}
+		}
+		byKey[key] = perm
+		report.Added = append(report.Added, perm.Key())
+	}
+
+	for key, p := range byKey {
+		if catalogKeys[key] {
+			continue
+		}
+		if !s.options.Prune {
+			report.Unknown = append(report.Unknown, p.Key())
+			continue
+		}
+		if !s.options.DryRun {
+			if err := s.permissionRepo.Delete(ctx, p.ID); err != nil {
+				return nil, fmt.Errorf("gagal menghapus permission %s: %w", p.Key(), err)
+			}
+		}
+		delete(byKey, key)
+		report.Removed = append(report.Removed, p.Key())
+	}
+	sort.Strings(report.Removed)
+	sort.Strings(report.Unknown)
+
+	for roleName, keys := range s.options.Bundles {
+		if err := s.syncBundle(ctx, report, roleName, keys, byKey); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(report.RolesCreated)
+
+	return report, nil
+}
+
+func (s *PermissionSyncer) syncBundle(ctx context.Context, report *PermissionSyncReport, roleName string, keys []string, byKey map[string]models.Permission) error {
+	role, err := s.roleRepo.GetByName(ctx, roleName)
+	if err != nil {
+		return fmt.Errorf("gagal membaca role %s: %w", roleName, err)
+	}
+
+	owned := make(map[string]bool)
+	if role == nil {
+		role = &models.Role{Name: roleName, Description: "Role bawaan"}
+		if !s.options.DryRun {
+			if err := s.roleRepo.Create(ctx, role); err != nil {
+				return fmt.Errorf("gagal membuat role %s: %w", roleName, err)
+			}
+		}
+		report.RolesCreated = append(report.RolesCreated, roleName)
+	} else {
+		current, err := s.permissionRepo.GetByRoleID(ctx, role.ID)
+		if err != nil {
+			return fmt.Errorf("gagal membaca permission role %s: %w", roleName, err)
+		}
+		for _, p := range current {
+			owned[strings.ToLower(p.Key())] = true
+		}
+	}
+
+	if len(keys) == 1 && keys[0] == "*" {
+		keys = keys[:0:0]
+		for _, entry := range PermissionCatalog {
+			keys = append(keys, entry.Key())
+		}
+	}
+
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		perm, ok := byKey[key]
+		if !ok {
+			return fmt.Errorf("bundle role %s memakai permission %s yang tidak ada di katalog", roleName, key)
+		}
+		if owned[key] {
+			continue
+		}
+		if !s.options.DryRun {
+			if err := s.permissionRepo.AssignToRole(ctx, role.ID, perm.ID); err != nil {
+				return fmt.Errorf("gagal assign %s ke role %s: %w", key, roleName, err)
+			}
+		}
+		report.Granted[roleName] = append(report.Granted[roleName], perm.Key())
+	}
+	return nil
+}
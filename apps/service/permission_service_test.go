@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
@@ -179,3 +180,52 @@ func TestAssignPermissionToRole(t *testing.T) {
 		})
 	}
 }
+
+func TestPermissionSync_Idempotent(t *testing.T) {
+	permRepo := mocks.NewManualMockPermissionRepo()
+	roleRepo := mocks.NewManualMockRoleRepo()
+	dosen := roleRepo.AddRole("Dosen")
+
+	// permission lama yang tidak ada di katalog
+	stale := &models.Permission{Name: "legacy_export", Resource: "legacy", Action: "export"}
+	permRepo.Create(context.Background(), stale)
+
+	options := service.PermissionSyncOptions{Bundles: service.DefaultRoleBundles("Admin", "Dosen", "Mahasiswa")}
+
+	report, err := service.NewPermissionSyncer(permRepo, roleRepo, options).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync gagal: %v", err)
+	}
+	if len(report.Added) != len(service.PermissionCatalog) {
+		t.Errorf("Expected %d permission ditambah, got %d", len(service.PermissionCatalog), len(report.Added))
+	}
+	if len(report.Unknown) != 1 || report.Unknown[0] != "legacy:export" {
+		t.Errorf("Permission di luar katalog harus dilaporkan, got %v", report.Unknown)
+	}
+	if len(report.RolesCreated) != 2 {
+		t.Errorf("Admin dan Mahasiswa harus dibuat, got %v", report.RolesCreated)
+	}
+
+	perms, _ := permRepo.GetByRoleID(context.Background(), dosen.ID)
+	if len(perms) != len(options.Bundles["Dosen"]) {
+		t.Errorf("Dosen harus dapat %d permission, got %d", len(options.Bundles["Dosen"]), len(perms))
+	}
+
+	// run kedua tidak boleh mengubah apa pun
+	report, err = service.NewPermissionSyncer(permRepo, roleRepo, options).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync kedua gagal: %v", err)
+	}
+	if report.Changed() {
+		t.Errorf("Sync kedua harus tanpa perubahan, got %s", report)
+	}
+
+	options.Prune = true
+	report, _ = service.NewPermissionSyncer(permRepo, roleRepo, options).Sync(context.Background())
+	if len(report.Removed) != 1 || report.Removed[0] != "legacy:export" {
+		t.Errorf("Prune harus menghapus legacy:export, got %v", report.Removed)
+	}
+	if p, _ := permRepo.GetByID(context.Background(), stale.ID); p != nil {
+		t.Error("Permission lama masih ada setelah prune")
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/google/uuid"
+)
+
+// AcademicYearOf tahun akademik untuk tanggal t, dimulai bulan Agustus: 2025-09-01 -> "2025/2026", 2026-03-01 -> "2025/2026"
+func AcademicYearOf(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.August {
+		start--
+	}
+	return strconv.Itoa(start) + "/" + strconv.Itoa(start+1)
+}
+
+// achievementDate tanggal prestasi untuk menentukan tahun akademik: eventDate, akhir period, atau fallback (waktu verifikasi)
+func achievementDate(detail *models.AchievementDetail, fallback time.Time) time.Time {
+	details, _ := normalizeJSON(detail.Details).(map[string]interface{})
+	if t, ok := parseDetailDate(details["eventDate"]); ok {
+		return t
+	}
+	if period, ok := details["period"].(map[string]interface{}); ok {
+		if t, ok := parseDetailDate(period["end"]); ok {
+			return t
+		}
+	}
+	return fallback
+}
+
+// PointLedger menulis poin prestasi ke point_ledger. Saldo satu prestasi = jumlah semua barisnya,
+// setiap perubahan ditulis sebagai baris selisih supaya riwayatnya bisa diaudit
+type PointLedger struct {
+	ledgerRepo repository.PointLedgerRepository
+}
+
+func NewPointLedger(ledgerRepo repository.PointLedgerRepository) *PointLedger {
+	return &PointLedger{ledgerRepo: ledgerRepo}
+}
+
+// PointAllocation bagian poin satu mahasiswa dari satu prestasi. Prestasi perorangan hanya punya satu
+type PointAllocation struct {
+	StudentID uuid.UUID `json:"student_id"`
+	Points    int       `json:"points"`
+}
+
+// balances saldo per mahasiswa untuk satu prestasi beserta baris terakhir masing-masing
+func (l *PointLedger) balances(ctx context.Context, achievementID uuid.UUID) (map[uuid.UUID]int, map[uuid.UUID]models.PointLedgerEntry, []uuid.UUID, error) {
+	entries, err := l.ledgerRepo.GetByAchievementID(ctx, achievementID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	totals := make(map[uuid.UUID]int)
+	last := make(map[uuid.UUID]models.PointLedgerEntry)
+	var order []uuid.UUID
+	for _, e := range entries {
+		if _, seen := last[e.StudentID]; !seen {
+			order = append(order, e.StudentID)
+		}
+		totals[e.StudentID] += e.Points
+		last[e.StudentID] = e
+	}
+	return totals, last, order, nil
+}
+
+// Award menyamakan saldo setiap mahasiswa dengan allocations. Saldo 0 dicatat sebagai award, selain itu adjustment
+// di tahun akademik dan kategori yang sama dengan award-nya.
+// Mahasiswa yang punya saldo tetapi tidak ada di allocations (misal keluar dari tim) dinolkan
+func (l *PointLedger) Award(ctx context.Context, ref *models.AchievementReference, detail *models.AchievementDetail, score *models.ScoreBreakdown, allocations []PointAllocation, actorID uuid.UUID) error {
+	current, last, order, err := l.balances(ctx, ref.ID)
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[uuid.UUID]int, len(allocations))
+	for _, a := range allocations {
+		targets[a.StudentID] = a.Points
+	}
+	for _, studentID := range order {
+		if _, ok := targets[studentID]; !ok {
+			allocations = append(allocations, PointAllocation{StudentID: studentID})
+		}
+	}
+
+	verifiedAt := time.Now()
+	if ref.VerifiedAt != nil {
+		verifiedAt = *ref.VerifiedAt
+	}
+	academicYear := AcademicYearOf(achievementDate(detail, verifiedAt))
+	for _, a := range allocations {
+		delta := a.Points - current[a.StudentID]
+		if delta == 0 {
+			continue
+		}
+
+		entry := &models.PointLedgerEntry{
+			StudentID:     a.StudentID,
+			AchievementID: ref.ID,
+			EntryType:     models.LedgerEntryAward,
+			Points:        delta,
+			Category:      score.AchievementType,
+			AcademicYear:  academicYear,
+			RulesVersion:  score.RulesVersion,
+			CreatedBy:     &actorID,
+		}
+		if current[a.StudentID] != 0 {
+			entry.EntryType = models.LedgerEntryAdjustment
+			entry.Category = last[a.StudentID].Category
+			entry.AcademicYear = last[a.StudentID].AcademicYear
+			entry.Note = fmt.Sprintf("hitung ulang poin %d -> %d", current[a.StudentID], a.Points)
+		}
+		if err := l.ledgerRepo.Create(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reverse menihilkan saldo semua mahasiswa pada prestasi saat verifikasinya dicabut, di tahun akademik dan kategori yang sama dengan award-nya
+func (l *PointLedger) Reverse(ctx context.Context, ref *models.AchievementReference, actorID uuid.UUID, note string) error {
+	current, last, order, err := l.balances(ctx, ref.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, studentID := range order {
+		if current[studentID] == 0 {
+			continue
+		}
+		err := l.ledgerRepo.Create(ctx, &models.PointLedgerEntry{
+			StudentID:     studentID,
+			AchievementID: ref.ID,
+			EntryType:     models.LedgerEntryReversal,
+			Points:        -current[studentID],
+			Category:      last[studentID].Category,
+			AcademicYear:  last[studentID].AcademicYear,
+			RulesVersion:  last[studentID].RulesVersion,
+			Note:          note,
+			CreatedBy:     &actorID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
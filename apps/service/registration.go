@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/notification"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RegistrationOptions struct {
+	// SelfRegistration false berarti POST /auth/Register ditutup, akun hanya dibuat admin / lewat undangan
+	SelfRegistration bool
+	StudentRoleName  string
+	LecturerRoleName string
+	InvitationTTL    time.Duration
+	// InvitationURL halaman terima undangan di frontend, token ditempel sebagai query ?token=
+	InvitationURL string
+}
+
+// RegistrationService mengurus semua jalur pembuatan akun: registrasi mandiri (khusus mahasiswa),
+// pembuatan user oleh admin, dan undangan.
+type RegistrationService struct {
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	invitationRepo repository.InvitationRepository
+	notifier       notification.Notifier
+	policy         utils.PasswordPolicy
+	options        RegistrationOptions
+}
+
+func NewRegistrationService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	invitationRepo repository.InvitationRepository,
+	notifier notification.Notifier,
+	policy utils.PasswordPolicy,
+	options RegistrationOptions,
+) *RegistrationService {
+	return &RegistrationService{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		invitationRepo: invitationRepo,
+		notifier:       notifier,
+		policy:         policy,
+		options:        options,
+	}
+}
+
+// createAccount validasi bersama lalu simpan user baru. Status 0 berarti sukses.
+func (s *RegistrationService) createAccount(ctx context.Context, user *models.User, password string) (int, fiber.Map) {
+	if user.Username == "" || user.Email == "" || password == "" || user.FullName == "" {
+		return 400, fiber.Map{"error": "username, email, password dan full_name wajib diisi"}
+	}
+
+	for _, login := range []string{user.Username, user.Email} {
+		if existUser, _ := s.userRepo.GetByUsernameOrEmail(ctx, login); existUser != nil {
+			return 400, fiber.Map{"error": "username atau email udah ada / terdaftar"}
+		}
+	}
+
+	if violations := s.policy.Validate(password); len(violations) > 0 {
+		return 400, fiber.Map{"error": "Password tidak memenuhi kebijakan", "errors": violations}
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return 500, fiber.Map{"error": "password gagal di hash"}
+	}
+	user.PasswordHash = hashedPassword
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return 500, fiber.Map{"error": "Gagal menyimpan user ke database"}
+	}
+	return 0, nil
+}
+
+// Register godoc
+// @Summary      Registrasi mandiri mahasiswa
+// @Description  Mendaftarkan akun baru dengan role mahasiswa. Role tidak bisa dipilih sendiri; akun dosen/admin dibuat admin atau lewat undangan. Bisa dimatikan lewat konfigurasi SELF_REGISTRATION
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.RegisterRequest true "Data registrasi pengguna"
+// @Success      201  {object}  map[string]interface{} "User berhasil dibuat"
+// @Failure      400  {object}  map[string]interface{} "Request tidak valid, username sudah ada, atau password tidak memenuhi kebijakan"
+// @Failure      403  {object}  map[string]interface{} "Registrasi mandiri ditutup"
+// @Failure      500  {object}  map[string]interface{} "Gagal menyimpan user"
+// @Router       /auth/Register [post]
+func (s *RegistrationService) Register(c *fiber.Ctx) error {
+	if !s.options.SelfRegistration {
+		return c.Status(403).JSON(fiber.Map{"error": "Registrasi mandiri sedang ditutup, hubungi admin"})
+	}
+
+	var req models.RegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	ctx := c.Context()
+	role, err := s.roleRepo.GetByName(ctx, s.options.StudentRoleName)
+	if err != nil || role == nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Role mahasiswa belum dikonfigurasi"})
+	}
+
+	newUser := &models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		FullName: req.FullName,
+		RoleID:   role.ID,
+	}
+	if status, body := s.createAccount(ctx, newUser, req.Password); status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	newUser.Role = role
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "User berhasil dibuat",
+		"data":    newUser,
+	})
+}
+
+// CreateUser godoc
+// @Summary      Buat pengguna (admin)
+// @Description  Membuat akun baru dengan role apa pun (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateUserRequest true "Data pengguna baru"
+// @Success      201  {object}  map[string]interface{} "User berhasil dibuat"
+// @Failure      400  {object}  map[string]interface{} "Request tidak valid, role tidak ada, username sudah ada, atau password tidak memenuhi kebijakan"
+// @Failure      500  {object}  map[string]interface{} "Gagal menyimpan user"
+// @Router       /auth/users [post]
+func (s *RegistrationService) CreateUser(c *fiber.Ctx) error {
+	var req models.CreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body tidak valid"})
+	}
+
+	roleUUID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Role ID tidak valid"})
+	}
+
+	ctx := c.Context()
+	role, err := s.roleRepo.GetByID(ctx, roleUUID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data role"})
+	}
+	if role == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Role tidak ditemukan"})
+	}
+
+	newUser := &models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		FullName: req.FullName,
+		RoleID:   role.ID,
+	}
+	if status, body := s.createAccount(ctx, newUser, req.Password); status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	newUser.Role = role
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "User berhasil dibuat",
+		"data":    newUser,
+	})
+}
+
+// CreateInvitation godoc
+// @Summary      Undang pengguna
+// @Description  Mengirim link undangan pembuatan akun ke email. Tanpa role_id, undangan dibuat untuk role dosen (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateInvitationRequest true "Email dan role yang diundang"
+// @Success      201  {object}  map[string]interface{} "Undangan terkirim"
+// @Failure      400  {object}  map[string]interface{} "Email kosong, sudah terdaftar, atau role tidak valid"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuat undangan"
+// @Router       /auth/invitations [post]
+func (s *RegistrationService) CreateInvitation(c *fiber.Ctx) error {
+	var req models.CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email wajib diisi"})
+	}
+
+	inviterID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	ctx := c.Context()
+	var role *models.Role
+	var err error
+	if req.RoleID == "" {
+		role, err = s.roleRepo.GetByName(ctx, s.options.LecturerRoleName)
+	} else {
+		roleUUID, parseErr := uuid.Parse(req.RoleID)
+		if parseErr != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Role ID tidak valid"})
+		}
+		role, err = s.roleRepo.GetByID(ctx, roleUUID)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data role"})
+	}
+	if role == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Role tidak ditemukan"})
+	}
+
+	if existUser, _ := s.userRepo.GetByUsernameOrEmail(ctx, req.Email); existUser != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Email sudah terdaftar"})
+	}
+
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat undangan"})
+	}
+
+	invitation := &models.Invitation{
+		Email:     req.Email,
+		FullName:  req.FullName,
+		RoleID:    role.ID,
+		TokenHash: utils.HashToken(rawToken),
+		InvitedBy: inviterID,
+		ExpiresAt: time.Now().Add(s.options.InvitationTTL),
+	}
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membuat undangan"})
+	}
+	invitation.Role = role
+
+	msg := notification.Message{
+		To:      invitation.Email,
+		Subject: "Undangan akun Sistem Pelaporan Prestasi",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKamu diundang sebagai %s. Buka link berikut untuk membuat akun (berlaku %s):\n%s?token=%s",
+			invitation.FullName, role.Name, s.options.InvitationTTL, s.options.InvitationURL, rawToken,
+		),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Undangan tersimpan tapi gagal dikirim"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Undangan berhasil dikirim",
+		"data":    invitation,
+	})
+}
+
+// GetInvitations godoc
+// @Summary      Daftar undangan aktif
+// @Description  Mengambil undangan yang belum diterima dan belum kadaluwarsa (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Daftar undangan"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
+// @Router       /auth/invitations [get]
+func (s *RegistrationService) GetInvitations(c *fiber.Ctx) error {
+	invitations, err := s.invitationRepo.GetPending(c.Context())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data undangan"})
+	}
+	return c.JSON(fiber.Map{
+		"message": "Daftar undangan aktif",
+		"data":    invitations,
+	})
+}
+
+// CancelInvitation godoc
+// @Summary      Batalkan undangan
+// @Description  Menghapus undangan yang belum diterima (khusus Admin)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID undangan (UUID)"
+// @Success      200  {object}  map[string]interface{} "Undangan dibatalkan"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Undangan tidak ditemukan atau sudah diterima"
+// @Failure      500  {object}  map[string]interface{} "Gagal membatalkan undangan"
+// @Router       /auth/invitations/{id} [delete]
+func (s *RegistrationService) CancelInvitation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	deleted, err := s.invitationRepo.Delete(c.Context(), id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal membatalkan undangan"})
+	}
+	if !deleted {
+		return c.Status(404).JSON(fiber.Map{"error": "Undangan tidak ditemukan atau sudah diterima"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Undangan berhasil dibatalkan"})
+}
+
+// AcceptInvitation godoc
+// @Summary      Terima undangan
+// @Description  Membuat akun dari link undangan. Email dan role mengikuti undangan
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.AcceptInvitationRequest true "Token undangan dan data akun"
+// @Success      201  {object}  map[string]interface{} "Akun berhasil dibuat"
+// @Failure      400  {object}  map[string]interface{} "Token tidak valid/kadaluwarsa atau data tidak valid"
+// @Failure      500  {object}  map[string]interface{} "Gagal membuat akun"
+// @Router       /auth/invitations/accept [post]
+func (s *RegistrationService) AcceptInvitation(c *fiber.Ctx) error {
+	var req models.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Token wajib diisi"})
+	}
+
+	ctx := c.Context()
+	invitation, err := s.invitationRepo.GetByHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa undangan"})
+	}
+	if invitation == nil || invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return c.Status(400).JSON(fiber.Map{"error": "Undangan tidak valid atau sudah kadaluwarsa"})
+	}
+
+	fullName := req.FullName
+	if fullName == "" {
+		fullName = invitation.FullName
+	}
+
+	newUser := &models.User{
+		Username: req.Username,
+		Email:    invitation.Email,
+		FullName: fullName,
+		RoleID:   invitation.RoleID,
+	}
+	if status, body := s.createAccount(ctx, newUser, req.Password); status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	accepted, err := s.invitationRepo.MarkAccepted(ctx, invitation.ID, newUser.ID)
+	if err != nil || !accepted {
+		// undangan dipakai request lain di saat bersamaan, akun yang baru dibuat dibatalkan
+		_ = s.userRepo.Delete(ctx, newUser.ID)
+		return c.Status(400).JSON(fiber.Map{"error": "Undangan tidak valid atau sudah kadaluwarsa"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Akun berhasil dibuat, silakan login",
+		"data":    newUser,
+	})
+}
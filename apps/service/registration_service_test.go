@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func newRegistrationService(userRepo *mocks.ManualMockUserRepo, roleRepo *mocks.ManualMockRoleRepo, notifier *captureNotifier, selfRegistration bool) *service.RegistrationService {
+	return service.NewRegistrationService(userRepo, roleRepo, mocks.NewManualMockInvitationRepo(), notifier, utils.DefaultPasswordPolicy(), service.RegistrationOptions{
+		SelfRegistration: selfRegistration,
+		StudentRoleName:  "Mahasiswa",
+		LecturerRoleName: "Dosen",
+		InvitationTTL:    time.Hour,
+		InvitationURL:    "http://localhost/undangan",
+	})
+}
+
+func TestRegister_TableDriven(t *testing.T) {
+
+	// Setup mock repositories
+	mockUserRepo := mocks.NewManualMockUserRepo()
+	roleRepo := mocks.NewManualMockRoleRepo()
+	studentRole := roleRepo.AddRole("Mahasiswa")
+	adminRole := roleRepo.AddRole("Admin")
+	registrationService := newRegistrationService(mockUserRepo, roleRepo, &captureNotifier{}, true)
+	app := fiber.New()
+	app.Post("/register", registrationService.Register)
+
+	existingUser := &models.User{
+		ID:       uuid.New(),
+		Username: "sudahada",
+		Email:    "ada@gmail.com",
+	}
+	mockUserRepo.Create(nil, existingUser)
+
+	tests := []struct {
+		name           string
+		inputBody      map[string]string
+		expectedStatus int
+	}{
+		{
+			name: "Register Sukses",
+			inputBody: map[string]string{
+				"username":  "maba_baru",
+				"email":     "maba@univ.ac.id",
+				"password":  "rahasia123",
+				"full_name": "Maba Univ",
+			},
+			expectedStatus: 201,
+		},
+		{
+			name: "Role Admin Diabaikan",
+			inputBody: map[string]string{
+				"username":  "calon_admin",
+				"email":     "admin_palsu@univ.ac.id",
+				"password":  "rahasia123",
+				"full_name": "Calon Admin",
+				"role_id":   adminRole.ID.String(),
+			},
+			expectedStatus: 201,
+		},
+		{
+			name: "Username Sudah Dipakai",
+			inputBody: map[string]string{
+				"username":  "sudahada",
+				"email":     "baru@gmail.com",
+				"password":  "123456",
+				"full_name": "Orang Lama",
+			},
+			expectedStatus: 400,
+		},
+		{
+			name: "Email Sudah Dipakai",
+			inputBody: map[string]string{
+				"username":  "orang_baru",
+				"email":     "ada@gmail.com",
+				"password":  "rahasia123",
+				"full_name": "Orang Baru",
+			},
+			expectedStatus: 400,
+		},
+		{
+			name: "Password Terlalu Umum",
+			inputBody: map[string]string{
+				"username":  "maba_lemah",
+				"email":     "lemah@univ.ac.id",
+				"password":  "password123",
+				"full_name": "Maba Lemah",
+			},
+			expectedStatus: 400,
+		},
+		{
+			name: "Input Tidak Lengkap",
+			inputBody: map[string]string{
+				"username": "kosong",
+			},
+			expectedStatus: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(tt.inputBody)
+			req := httptest.NewRequest("POST", "/register", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+
+			if err != nil {
+				t.Errorf("Error request: %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Status salah! Dapat %d, Harapan %d", resp.StatusCode, tt.expectedStatus)
+			}
+
+			if tt.expectedStatus == 201 {
+				created, _ := mockUserRepo.GetByUsernameOrEmail(context.Background(), tt.inputBody["username"])
+				if created == nil || created.RoleID != studentRole.ID {
+					t.Errorf("User hasil registrasi mandiri harus ber-role Mahasiswa")
+				}
+			}
+		})
+	}
+}
+
+func TestRegister_SelfRegistrationDisabled(t *testing.T) {
+	roleRepo := mocks.NewManualMockRoleRepo()
+	roleRepo.AddRole("Mahasiswa")
+	registrationService := newRegistrationService(mocks.NewManualMockUserRepo(), roleRepo, &captureNotifier{}, false)
+	app := fiber.New()
+	app.Post("/register", registrationService.Register)
+
+	status := postJSON(t, app, "/register", map[string]string{
+		"username":  "maba_baru",
+		"email":     "maba@univ.ac.id",
+		"password":  "rahasia123",
+		"full_name": "Maba Univ",
+	})
+	if status != 403 {
+		t.Errorf("Registrasi mandiri yang ditutup harus 403, dapat %d", status)
+	}
+}
+
+func TestInvitation_LecturerFlow(t *testing.T) {
+	mockUserRepo := mocks.NewManualMockUserRepo()
+	roleRepo := mocks.NewManualMockRoleRepo()
+	lecturerRole := roleRepo.AddRole("Dosen")
+	notifier := &captureNotifier{}
+	registrationService := newRegistrationService(mockUserRepo, roleRepo, notifier, true)
+
+	adminID := uuid.New()
+	app := fiber.New()
+	app.Post("/invitations", func(c *fiber.Ctx) error {
+		c.Locals("user_id", adminID.String())
+		return c.Next()
+	}, registrationService.CreateInvitation)
+	app.Post("/invitations/accept", registrationService.AcceptInvitation)
+
+	if status := postJSON(t, app, "/invitations", map[string]string{"email": "dosen@univ.ac.id", "full_name": "Dr. Dosen"}); status != 201 {
+		t.Fatalf("Buat undangan harus 201, dapat %d", status)
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("Undangan harus dikirim lewat notifier")
+	}
+
+	body := notifier.messages[0].Body
+	token := strings.Fields(body[strings.Index(body, "?token=")+len("?token="):])[0]
+
+	accept := map[string]string{"token": token, "username": "dosen_baru", "password": "dosenBaru2024"}
+	if status := postJSON(t, app, "/invitations/accept", accept); status != 201 {
+		t.Fatalf("Terima undangan harus 201, dapat %d", status)
+	}
+
+	created, _ := mockUserRepo.GetByUsernameOrEmail(context.Background(), "dosen_baru")
+	if created == nil || created.RoleID != lecturerRole.ID || created.Email != "dosen@univ.ac.id" {
+		t.Fatalf("Akun dari undangan harus ber-role Dosen dengan email undangan, dapat %+v", created)
+	}
+
+	accept["username"] = "dosen_kedua"
+	if status := postJSON(t, app, "/invitations/accept", accept); status != 400 {
+		t.Errorf("Undangan hanya bisa dipakai sekali, dapat %d", status)
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"strings"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleServiceOptions struct {
+	// ProtectedRoles role bawaan yang dipakai kode (Admin, Mahasiswa, Dosen), tidak boleh diganti nama atau dihapus
+	ProtectedRoles []string
+}
+
+type RoleService struct {
+	roleRepo       repository.RoleRepository
+	permissionRepo repository.PermissionRepository
+	options        RoleServiceOptions
+}
+
+func NewRoleService(roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository, options RoleServiceOptions) *RoleService {
+	return &RoleService{roleRepo: roleRepo, permissionRepo: permissionRepo, options: options}
+}
+
+func (s *RoleService) isProtected(name string) bool {
+	for _, protected := range s.options.ProtectedRoles {
+		if strings.EqualFold(protected, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findRole membaca :id dari path. Kalau gagal, respons error sudah dikirim dan role bernilai nil.
+func (s *RoleService) findRole(c *fiber.Ctx) (*models.Role, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID role tidak valid"})
+	}
+
+	role, err := s.roleRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data role"})
+	}
+	if role == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Role tidak ditemukan"})
+	}
+	return role, nil
+}
+
+// GetAll godoc
+// @Summary      Dapatkan semua role
+// @Description  Mengambil daftar role beserta permission masing-masing
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Daftar role"
+// @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
+// @Router       /roles [get]
+func (s *RoleService) GetAll(c *fiber.Ctx) error {
+	ctx := c.Context()
+	roles, err := s.roleRepo.GetAll(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data role"})
+	}
+
+	for i := range roles {
+		perms, err := s.permissionRepo.GetByRoleID(ctx, roles[i].ID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil permission role"})
+		}
+		roles[i].Permissions = perms
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "List Role",
+		"data":    roles,
+	})
+}
+
+// GetByID godoc
+// @Summary      Detail role
+// @Description  Mengambil satu role beserta permission-nya
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Success      200  {object}  map[string]interface{} "Detail role"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Role tidak ditemukan"
+// @Router       /roles/{id} [get]
+func (s *RoleService) GetByID(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	perms, err := s.permissionRepo.GetByRoleID(c.Context(), role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil permission role"})
+	}
+	role.Permissions = perms
+
+	return c.JSON(fiber.Map{"data": role})
+}
+
+// Create godoc
+// @Summary      Buat role baru
+// @Description  Membuat role baru tanpa permission. Tambahkan permission lewat POST /roles/{id}/permissions
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.CreateRoleRequest true "Nama dan deskripsi role"
+// @Success      201  {object}  map[string]interface{} "Role berhasil dibuat"
+// @Failure      400  {object}  map[string]interface{} "Nama role kosong"
+// @Failure      409  {object}  map[string]interface{} "Nama role sudah dipakai"
+// @Failure      500  {object}  map[string]interface{} "Gagal menyimpan role"
+// @Router       /roles [post]
+func (s *RoleService) Create(c *fiber.Ctx) error {
+	var req models.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Nama role wajib diisi"})
+	}
+
+	ctx := c.Context()
+	if existing, _ := s.roleRepo.GetByName(ctx, req.Name); existing != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Nama role sudah dipakai"})
+	}
+
+	role := &models.Role{Name: req.Name, Description: req.Description}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyimpan role"})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Role berhasil dibuat",
+		"data":    role,
+	})
+}
+
+// Update godoc
+// @Summary      Ubah role
+// @Description  Mengubah nama dan deskripsi role. Nama role bawaan (Admin, Mahasiswa, Dosen) tidak bisa diganti
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Param        request body models.UpdateRoleRequest true "Nama dan deskripsi role"
+// @Success      200  {object}  map[string]interface{} "Role berhasil diubah"
+// @Failure      400  {object}  map[string]interface{} "Request tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Role bawaan tidak boleh diganti nama"
+// @Failure      404  {object}  map[string]interface{} "Role tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Nama role sudah dipakai"
+// @Router       /roles/{id} [put]
+func (s *RoleService) Update(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	ctx := c.Context()
+	req.Name = strings.TrimSpace(req.Name)
+	renamed := req.Name != "" && req.Name != role.Name
+	if renamed {
+		if s.isProtected(role.Name) {
+			return c.Status(403).JSON(fiber.Map{"error": "Role bawaan tidak boleh diganti nama"})
+		}
+		if existing, _ := s.roleRepo.GetByName(ctx, req.Name); existing != nil && existing.ID != role.ID {
+			return c.Status(409).JSON(fiber.Map{"error": "Nama role sudah dipakai"})
+		}
+		role.Name = req.Name
+	}
+	role.Description = req.Description
+
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengubah role"})
+	}
+	if renamed {
+		// cache permission disimpan per nama role
+		middleware.InvalidatePermissionCache()
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role berhasil diubah",
+		"data":    role,
+	})
+}
+
+// Delete godoc
+// @Summary      Hapus role
+// @Description  Menghapus role beserta relasi permission-nya. Ditolak kalau masih ada user dengan role ini atau role bawaan
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Success      200  {object}  map[string]interface{} "Role berhasil dihapus"
+// @Failure      403  {object}  map[string]interface{} "Role bawaan tidak boleh dihapus"
+// @Failure      404  {object}  map[string]interface{} "Role tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Role masih dipakai user"
+// @Router       /roles/{id} [delete]
+func (s *RoleService) Delete(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	if s.isProtected(role.Name) {
+		return c.Status(403).JSON(fiber.Map{"error": "Role bawaan tidak boleh dihapus"})
+	}
+
+	ctx := c.Context()
+	count, err := s.roleRepo.CountUsers(ctx, role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek pemakaian role"})
+	}
+	if count > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":      "Role masih dipakai user, pindahkan user ke role lain dulu",
+			"user_count": count,
+		})
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menghapus role"})
+	}
+	middleware.InvalidatePermissionCache()
+
+	return c.JSON(fiber.Map{"message": "Role berhasil dihapus"})
+}
+
+// GetPermissions godoc
+// @Summary      Permission milik role
+// @Description  Mengambil daftar permission yang dimiliki role
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Success      200  {object}  map[string]interface{} "Daftar permission role"
+// @Failure      404  {object}  map[string]interface{} "Role tidak ditemukan"
+// @Router       /roles/{id}/permissions [get]
+func (s *RoleService) GetPermissions(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	perms, err := s.permissionRepo.GetByRoleID(c.Context(), role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil permission role"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "List Permission " + role.Name,
+		"data":    perms,
+	})
+}
+
+// findPermission membaca permission_id dari body atau :permissionId dari path
+func (s *RoleService) findPermission(c *fiber.Ctx, rawID string) (*models.Permission, error) {
+	permID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, c.Status(400).JSON(fiber.Map{"error": "ID permission tidak valid"})
+	}
+
+	perm, err := s.permissionRepo.GetByID(c.Context(), permID)
+	if err != nil {
+		return nil, c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data permission"})
+	}
+	if perm == nil {
+		return nil, c.Status(404).JSON(fiber.Map{"error": "Permission tidak ditemukan"})
+	}
+	return perm, nil
+}
+
+// AssignPermission godoc
+// @Summary      Tambah permission ke role
+// @Description  Menambahkan satu permission ke role. Tidak error kalau permission sudah dimiliki
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Param        request body models.RolePermissionRequest true "ID permission"
+// @Success      200  {object}  map[string]interface{} "Permission ditambahkan"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Role atau permission tidak ditemukan"
+// @Router       /roles/{id}/permissions [post]
+func (s *RoleService) AssignPermission(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	var req models.RolePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "request body gk valid"})
+	}
+
+	perm, err := s.findPermission(c, req.PermissionID)
+	if perm == nil {
+		return err
+	}
+
+	ctx := c.Context()
+	current, err := s.permissionRepo.GetByRoleID(ctx, role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil permission role"})
+	}
+	for _, p := range current {
+		if p.ID == perm.ID {
+			return c.JSON(fiber.Map{"message": "Role sudah memiliki permission ini"})
+		}
+	}
+
+	if err := s.permissionRepo.AssignToRole(ctx, role.ID, perm.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal assign permission"})
+	}
+	middleware.InvalidatePermissionCache()
+
+	return c.JSON(fiber.Map{"message": "Permission " + perm.Key() + " ditambahkan ke role " + role.Name})
+}
+
+// RevokePermission godoc
+// @Summary      Cabut permission dari role
+// @Description  Menghapus satu permission dari role. Berlaku langsung untuk semua user dengan role tersebut
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID role (UUID)"
+// @Param        permissionId path string true "ID permission (UUID)"
+// @Success      200  {object}  map[string]interface{} "Permission dicabut"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Role atau permission tidak ditemukan"
+// @Router       /roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) RevokePermission(c *fiber.Ctx) error {
+	role, err := s.findRole(c)
+	if role == nil {
+		return err
+	}
+
+	perm, err := s.findPermission(c, c.Params("permissionId"))
+	if perm == nil {
+		return err
+	}
+
+	if err := s.permissionRepo.RevokeFromRole(c.Context(), role.ID, perm.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mencabut permission"})
+	}
+	middleware.InvalidatePermissionCache()
+
+	return c.JSON(fiber.Map{"message": "Permission " + perm.Key() + " dicabut dari role " + role.Name})
+}
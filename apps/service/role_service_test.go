@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository/mocks"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newRoleApp(roleRepo *mocks.ManualMockRoleRepo, permRepo *mocks.ManualMockPermissionRepo) *fiber.App {
+	roleService := service.NewRoleService(roleRepo, permRepo, service.RoleServiceOptions{
+		ProtectedRoles: []string{"Admin", "Mahasiswa", "Dosen"},
+	})
+
+	app := fiber.New()
+	app.Get("/roles/:id", roleService.GetByID)
+	app.Post("/roles", roleService.Create)
+	app.Put("/roles/:id", roleService.Update)
+	app.Delete("/roles/:id", roleService.Delete)
+	app.Post("/roles/:id/permissions", roleService.AssignPermission)
+	app.Delete("/roles/:id/permissions/:permissionId", roleService.RevokePermission)
+	return app
+}
+
+func TestRole_CreateUpdateDelete(t *testing.T) {
+	roleRepo := mocks.NewManualMockRoleRepo()
+	permRepo := mocks.NewManualMockPermissionRepo()
+	mahasiswa := roleRepo.AddRole("Mahasiswa")
+	tamu := roleRepo.AddRole("Tamu")
+	roleRepo.SetUserCount(tamu.ID, 3)
+	app := newRoleApp(roleRepo, permRepo)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Buat Role Baru", "POST", "/roles", models.CreateRoleRequest{Name: "Kaprodi"}, 201},
+		{"Nama Role Duplikat", "POST", "/roles", models.CreateRoleRequest{Name: "mahasiswa"}, 409},
+		{"Nama Role Kosong", "POST", "/roles", models.CreateRoleRequest{Name: "  "}, 400},
+		{"Rename Role Bawaan Ditolak", "PUT", "/roles/" + mahasiswa.ID.String(), models.UpdateRoleRequest{Name: "Siswa"}, 403},
+		{"Deskripsi Role Bawaan Boleh Diubah", "PUT", "/roles/" + mahasiswa.ID.String(), models.UpdateRoleRequest{Description: "Mahasiswa aktif"}, 200},
+		{"Hapus Role Bawaan Ditolak", "DELETE", "/roles/" + mahasiswa.ID.String(), nil, 403},
+		{"Hapus Role Yang Masih Dipakai", "DELETE", "/roles/" + tamu.ID.String(), nil, 409},
+		{"Role Tidak Ada", "DELETE", "/roles/" + "00000000-0000-0000-0000-000000000000", nil, 404},
+		{"ID Tidak Valid", "GET", "/roles/bukan-uuid", nil, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := decodeBody(t, app, tt.method, tt.path, tt.body)
+			if status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d (%v)", tt.expectedStatus, status, body)
+			}
+		})
+	}
+
+	roleRepo.SetUserCount(tamu.ID, 0)
+	if status, _ := decodeBody(t, app, "DELETE", "/roles/"+tamu.ID.String(), nil); status != 200 {
+		t.Fatalf("Role tanpa user harus bisa dihapus, got %d", status)
+	}
+	if role, _ := roleRepo.GetByID(context.Background(), tamu.ID); role != nil {
+		t.Error("Role masih ada setelah dihapus")
+	}
+}
+
+func TestRole_AssignAndRevokePermission(t *testing.T) {
+	roleRepo := mocks.NewManualMockRoleRepo()
+	permRepo := mocks.NewManualMockPermissionRepo()
+	dosen := roleRepo.AddRole("Dosen")
+	permRepo.RegisterRole(dosen.ID, dosen.Name)
+
+	verify := &models.Permission{Name: "achievement.verify", Resource: "achievement", Action: "verify"}
+	permRepo.Create(context.Background(), verify)
+
+	cache := middleware.NewPermissionCache(permRepo, time.Minute, "Admin")
+	middleware.SetPermissionCache(cache)
+	defer middleware.SetPermissionCache(nil)
+
+	app := newRoleApp(roleRepo, permRepo)
+	rolePath := "/roles/" + dosen.ID.String() + "/permissions"
+
+	if status, body := decodeBody(t, app, "POST", rolePath, models.RolePermissionRequest{PermissionID: verify.ID.String()}); status != 200 {
+		t.Fatalf("Assign gagal: %d %v", status, body)
+	}
+	// assign ulang tidak boleh error
+	if status, _ := decodeBody(t, app, "POST", rolePath, models.RolePermissionRequest{PermissionID: verify.ID.String()}); status != 200 {
+		t.Fatalf("Assign ulang harus idempotent, got %d", status)
+	}
+	if allowed, _ := cache.Allowed(context.Background(), "Dosen", "achievement:verify"); !allowed {
+		t.Fatal("Dosen harus punya achievement:verify setelah assign")
+	}
+
+	if status, body := decodeBody(t, app, "DELETE", rolePath+"/"+verify.ID.String(), nil); status != 200 {
+		t.Fatalf("Revoke gagal: %d %v", status, body)
+	}
+	if allowed, _ := cache.Allowed(context.Background(), "Dosen", "achievement:verify"); allowed {
+		t.Error("Cache permission harus di-reset setelah revoke")
+	}
+
+	if status, _ := decodeBody(t, app, "DELETE", rolePath+"/00000000-0000-0000-0000-000000000000", nil); status != 404 {
+		t.Errorf("Revoke permission yang tidak ada harus 404, got %d", status)
+	}
+}
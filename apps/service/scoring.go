@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+)
+
+const (
+	ScoringFactorLookup = "lookup"
+	ScoringFactorYears  = "years"
+)
+
+// DefaultScoringRules dipakai kalau SCORING_RULES_FILE tidak diset. scoring_rules.json berisi tabel yang sama sebagai titik awal
+func DefaultScoringRules() models.ScoringRules {
+	return models.ScoringRules{
+		Version: "default",
+		Types: map[string]models.TypeScoringRule{
+			"competition": {Factors: []models.ScoringFactor{
+				{Field: "competitionLevel", Kind: ScoringFactorLookup, Values: map[string]float64{"international": 3, "national": 2, "regional": 1.5, "local": 1}, Default: 1},
+				{Field: "rank", Kind: ScoringFactorLookup, Values: map[string]float64{"1": 1.5, "2": 1.25, "3": 1}, Default: 0.75},
+			}},
+			"publication": {Factors: []models.ScoringFactor{
+				{Field: "publicationType", Kind: ScoringFactorLookup, Values: map[string]float64{"journal": 1.5, "conference": 1.2, "book": 2}, Default: 1},
+			}},
+			"organization": {Factors: []models.ScoringFactor{
+				{Field: "position", Kind: ScoringFactorLookup, Values: map[string]float64{"ketua": 2, "wakil ketua": 1.5, "sekretaris": 1.25, "bendahara": 1.25}, Default: 1},
+				{Field: "period", Kind: ScoringFactorYears, Default: 0.5, Max: 3},
+			}},
+		},
+	}
+}
+
+// ValidateScoringRules memastikan file aturan bisa dipakai sebelum aplikasi jalan
+func ValidateScoringRules(rules models.ScoringRules) error {
+	for typeKey, rule := range rules.Types {
+		if rule.BasePoints != nil && *rule.BasePoints < 0 {
+			return fmt.Errorf("types.%s.base_points tidak boleh negatif", typeKey)
+		}
+		for i, factor := range rule.Factors {
+			if factor.Field == "" {
+				return fmt.Errorf("types.%s.factors[%d].field wajib diisi", typeKey, i)
+			}
+			switch factor.Kind {
+			case ScoringFactorLookup:
+			case ScoringFactorYears:
+				if factor.Max > 0 && factor.Max < factor.Default {
+					return fmt.Errorf("types.%s.factors[%d].max lebih kecil dari default", typeKey, i)
+				}
+			default:
+				return fmt.Errorf("types.%s.factors[%d].kind %q tidak dikenal (lookup / years)", typeKey, i, factor.Kind)
+			}
+		}
+	}
+	return nil
+}
+
+// ScoringEngine menghitung poin prestasi dari details memakai ScoringRules
+type ScoringEngine struct {
+	rules    models.ScoringRules
+	typeRepo repository.AchievementTypeRepository
+}
+
+func NewScoringEngine(rules models.ScoringRules, typeRepo repository.AchievementTypeRepository) *ScoringEngine {
+	return &ScoringEngine{rules: rules, typeRepo: typeRepo}
+}
+
+func (e *ScoringEngine) RulesVersion() string {
+	return e.rules.Version
+}
+
+// Score menghitung poin beserta rinciannya. Tipe tanpa aturan hanya mendapat poin dasar
+func (e *ScoringEngine) Score(ctx context.Context, detail *models.AchievementDetail) (*models.ScoreBreakdown, error) {
+	typeKey := NormalizeAchievementType(detail.AchievementType)
+	rule := e.rules.Types[typeKey]
+
+	breakdown := &models.ScoreBreakdown{
+		AchievementType: typeKey,
+		Factors:         []models.ScoreFactor{},
+		RulesVersion:    e.rules.Version,
+		ComputedAt:      time.Now(),
+	}
+
+	if rule.BasePoints != nil {
+		breakdown.BasePoints = *rule.BasePoints
+	} else {
+		typeDef, err := e.typeRepo.GetByKey(ctx, typeKey)
+		if err != nil {
+			return nil, err
+		}
+		if typeDef == nil {
+			return nil, fmt.Errorf("tipe prestasi %q tidak terdaftar", typeKey)
+		}
+		breakdown.BasePoints = float64(typeDef.DefaultPoints)
+	}
+
+	details, _ := normalizeJSON(detail.Details).(map[string]interface{})
+	total := breakdown.BasePoints
+	for _, factor := range rule.Factors {
+		result := applyScoringFactor(factor, details[factor.Field])
+		total *= result.Multiplier
+		breakdown.Factors = append(breakdown.Factors, result)
+	}
+
+	breakdown.Total = int(math.Round(total))
+	return breakdown, nil
+}
+
+func applyScoringFactor(factor models.ScoringFactor, value interface{}) models.ScoreFactor {
+	result := models.ScoreFactor{Field: factor.Field, Value: value, Multiplier: factor.Default}
+
+	switch factor.Kind {
+	case ScoringFactorLookup:
+		key := lookupKey(value)
+		if multiplier, ok := factor.Values[key]; ok {
+			result.Multiplier = multiplier
+		} else {
+			result.Note = "nilai tidak ada di tabel, memakai pengali default"
+		}
+	case ScoringFactorYears:
+		period, _ := value.(map[string]interface{})
+		start, okStart := parseDetailDate(period["start"])
+		end, okEnd := parseDetailDate(period["end"])
+		if !okStart || !okEnd {
+			result.Note = "period tidak lengkap, memakai pengali default"
+			break
+		}
+		years := math.Round(end.Sub(start).Hours()/24/365.25*100) / 100
+		result.Value = years
+		result.Multiplier = math.Max(years, factor.Default)
+		if factor.Max > 0 && result.Multiplier > factor.Max {
+			result.Multiplier = factor.Max
+			result.Note = "dibatasi maksimal " + strconv.FormatFloat(factor.Max, 'f', -1, 64) + " tahun"
+		}
+	}
+	return result
+}
+
+// lookupKey - angka bulat jadi "1", teks jadi huruf kecil tanpa spasi di ujung
+func lookupKey(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.ToLower(strings.TrimSpace(v))
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid atau expired")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah dipakai, sesi ini dicabut")
+	ErrTokenAlreadyUsed    = errors.New("token sudah pernah dipakai")
+)
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// SessionManager mengurus penerbitan, rotasi dan pencabutan token.
+// Dipakai bersama oleh service yang perlu login-kan user atau mencabut sesinya.
+type SessionManager struct {
+	refreshRepo repository.RefreshTokenRepository
+	revocations repository.TokenRevocationStore
+}
+
+func NewSessionManager(refreshRepo repository.RefreshTokenRepository, revocations repository.TokenRevocationStore) *SessionManager {
+	return &SessionManager{refreshRepo: refreshRepo, revocations: revocations}
+}
+
+// Issue membuat pasangan token baru. familyID baru untuk login, familyID lama untuk rotasi.
+func (m *SessionManager) Issue(ctx context.Context, user *models.User, familyID uuid.UUID, deviceLabel string) (*TokenPair, error) {
+	return m.issue(ctx, uuid.New(), user, familyID, deviceLabel)
+}
+
+func (m *SessionManager) issue(ctx context.Context, id uuid.UUID, user *models.User, familyID uuid.UUID, deviceLabel string) (*TokenPair, error) {
+	roleName := ""
+	if user.Role != nil {
+		roleName = user.Role.Name
+	}
+
+	accessToken, refreshToken, err := utils.GenerateToken(user.ID, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.RefreshToken{
+		ID:          id,
+		UserID:      user.ID,
+		FamilyID:    familyID,
+		TokenHash:   utils.HashToken(refreshToken),
+		DeviceLabel: deviceLabel,
+		ExpiresAt:   time.Now().Add(utils.RefreshTokenTTL),
+	}
+	if err := m.refreshRepo.Create(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Validate memastikan refresh token masih aktif. Token yang sudah dicabut tapi dipakai lagi
+// dianggap dicuri, jadi seluruh family-nya ikut dicabut.
+func (m *SessionManager) Validate(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	if _, err := utils.ValidateTokenType(refreshToken, utils.TokenTypeRefresh); err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	record, err := m.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	if record.RevokedAt != nil {
+		if err := m.refreshRepo.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	return record, nil
+}
+
+// Rotate mencabut token lama (sekali pakai) lalu menerbitkan pengganti di family yang sama
+func (m *SessionManager) Rotate(ctx context.Context, current *models.RefreshToken, user *models.User) (*TokenPair, error) {
+	newID := uuid.New()
+
+	rotated, err := m.refreshRepo.MarkRotated(ctx, current.ID, newID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// kalah balapan dengan request lain yang memakai token yang sama
+		if err := m.refreshRepo.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	return m.issue(ctx, newID, user, current.FamilyID, current.DeviceLabel)
+}
+
+// Revoke mencabut satu refresh token milik userID
+func (m *SessionManager) Revoke(ctx context.Context, userID uuid.UUID, refreshToken string) error {
+	record, err := m.refreshRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if record == nil || record.UserID != userID {
+		return ErrRefreshTokenInvalid
+	}
+	return m.refreshRepo.Revoke(ctx, record.ID)
+}
+
+// RevokeAccessToken memasukkan jti access token ke denylist sampai token itu expired
+func (m *SessionManager) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return m.revocations.RevokeToken(ctx, jti, expiresAt)
+}
+
+// CheckOneTimeToken mengembalikan ErrTokenAlreadyUsed kalau token sekali pakai (misal mfa_pending) sudah dipakai
+func (m *SessionManager) CheckOneTimeToken(ctx context.Context, claims *utils.JWTClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrTokenAlreadyUsed
+	}
+	used, err := m.revocations.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrTokenAlreadyUsed
+	}
+	return nil
+}
+
+// ConsumeOneTimeToken menandai token sekali pakai lewat denylist jti
+func (m *SessionManager) ConsumeOneTimeToken(ctx context.Context, claims *utils.JWTClaims) error {
+	if err := m.CheckOneTimeToken(ctx, claims); err != nil {
+		return err
+	}
+	return m.revocations.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// InvalidateAccessTokens membuat semua access token user yang terbit sebelum sekarang tidak berlaku.
+// Dipakai saat role berubah, akun dinonaktifkan atau password diganti.
+func (m *SessionManager) InvalidateAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	return m.revocations.RevokeUserTokensBefore(ctx, userID, time.Now())
+}
+
+// RevokeAll mencabut semua refresh token dan access token milik user
+func (m *SessionManager) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	if err := m.refreshRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		return err
+	}
+	return m.InvalidateAccessTokens(ctx, userID)
+}
+
+func (m *SessionManager) List(ctx context.Context, userID uuid.UUID) ([]models.RefreshToken, error) {
+	return m.refreshRepo.GetActiveByUserID(ctx, userID)
+}
@@ -97,6 +97,45 @@ func TestGetAllStudents(t *testing.T) {
 	}
 }
 
+// TestGetAllStudents_HidesInactive - Mahasiswa dengan akun nonaktif tidak tampil secara default
+func TestGetAllStudents_HidesInactive(t *testing.T) {
+	mockRepo := mocks.NewManualMockStudentRepo()
+	studentService := service.NewStudentService(mockRepo)
+	app := fiber.New()
+	app.Get("/students", studentService.GetAll)
+
+	activeUser, inactiveUser := uuid.New(), uuid.New()
+	mockRepo.Create(nil, &models.Students{UserID: activeUser, StudentID: "111", ProgramStudy: "Informatika"})
+	mockRepo.Create(nil, &models.Students{UserID: inactiveUser, StudentID: "222", ProgramStudy: "Informatika"})
+	mockRepo.SetUserActive(inactiveUser, false)
+
+	tests := []struct {
+		name          string
+		url           string
+		expectedTotal int
+	}{
+		{name: "Default Sembunyikan Nonaktif", url: "/students", expectedTotal: 1},
+		{name: "Include Inactive", url: "/students?include_inactive=true", expectedTotal: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.url, nil))
+			if err != nil {
+				t.Fatalf("Error request: %v", err)
+			}
+
+			var body struct {
+				Data []models.Students `json:"data"`
+			}
+			json.NewDecoder(resp.Body).Decode(&body)
+			if len(body.Data) != tt.expectedTotal {
+				t.Errorf("Jumlah mahasiswa salah! Dapat %d, Harapan %d", len(body.Data), tt.expectedTotal)
+			}
+		})
+	}
+}
+
 // TestGetStudentByID - Test untuk GET /students/:id
 func TestGetStudentByID_TableDriven(t *testing.T) {
 	mockRepo := mocks.NewManualMockStudentRepo()
@@ -227,17 +227,18 @@ func (s *StudentService) AssignAdvisor(c *fiber.Ctx) error {
 
 // GetAll godoc
 // @Summary      Dapatkan semua mahasiswa
-// @Description  Mengambil daftar seluruh mahasiswa (khusus Dosen/Admin)
+// @Description  Mengambil daftar seluruh mahasiswa (khusus Dosen/Admin). Mahasiswa dengan akun nonaktif disembunyikan kecuali include_inactive=true
 // @Tags         Students
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        include_inactive query bool false "Sertakan mahasiswa yang akunnya nonaktif"
 // @Success      200  {object}  map[string]interface{} "Daftar semua mahasiswa"
 // @Failure      500  {object}  map[string]interface{} "Gagal mengambil data"
 // @Router       /students [get]
 func (s *StudentService) GetAll(c *fiber.Ctx) error {
 	ctx := c.Context()
-	students, err := s.studentRepo.GetAll(ctx)
+	students, err := s.studentRepo.GetAll(ctx, c.QueryBool("include_inactive"))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data students"})
 	}
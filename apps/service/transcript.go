@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// TranscriptService ringkasan poin prestasi mahasiswa per tahun akademik dan kategori (dasar dokumen SKPI)
+type TranscriptService struct {
+	studentRepo     repository.StudentsRepository
+	ledgerRepo      repository.PointLedgerRepository
+	achievementRepo repository.AchievementRepository
+	typeRepo        repository.AchievementTypeRepository
+}
+
+func NewTranscriptService(studentRepo repository.StudentsRepository, ledgerRepo repository.PointLedgerRepository, achievementRepo repository.AchievementRepository, typeRepo repository.AchievementTypeRepository) *TranscriptService {
+	return &TranscriptService{
+		studentRepo:     studentRepo,
+		ledgerRepo:      ledgerRepo,
+		achievementRepo: achievementRepo,
+		typeRepo:        typeRepo,
+	}
+}
+
+type TranscriptAchievement struct {
+	AchievementID uuid.UUID `json:"achievement_id"`
+	Title         string    `json:"title"`
+	Category      string    `json:"category"`
+	Points        int       `json:"points"`
+}
+
+type TranscriptCategory struct {
+	Category string `json:"category"`
+	Label    string `json:"label"`
+	Points   int    `json:"points"`
+	Count    int    `json:"count"`
+}
+
+type TranscriptYear struct {
+	AcademicYear string                  `json:"academic_year"`
+	YearOfStudy  int                     `json:"year_of_study,omitempty"` // tahun ke-n sejak angkatan (Students.AcademicYear)
+	Points       int                     `json:"points"`
+	Categories   []TranscriptCategory    `json:"categories"`
+	Achievements []TranscriptAchievement `json:"achievements"`
+}
+
+type Transcript struct {
+	Student     *models.Students `json:"student"`
+	TotalPoints int              `json:"total_points"`
+	Years       []TranscriptYear `json:"years"`
+}
+
+// leadingYear 4 digit pertama, "2023/2024" dan "2023" sama-sama jadi 2023
+func leadingYear(s string) (int, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	year, err := strconv.Atoi(s[:4])
+	return year, err == nil
+}
+
+// Build menyusun transkrip dari point_ledger. Prestasi yang saldonya 0 (dicabut) tidak ditampilkan
+func (s *TranscriptService) Build(ctx context.Context, student *models.Students) (*Transcript, error) {
+	entries, err := s.ledgerRepo.GetByStudentID(ctx, student.ID)
+	if err != nil {
+		return nil, err
+	}
+	types, err := s.typeRepo.GetAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[string]string, len(types))
+	for _, t := range types {
+		labels[t.Key] = t.Label
+	}
+
+	type key struct {
+		year        string
+		achievement uuid.UUID
+	}
+	balances := make(map[key]*TranscriptAchievement)
+	var order []key
+	for _, e := range entries {
+		k := key{e.AcademicYear, e.AchievementID}
+		if balances[k] == nil {
+			balances[k] = &TranscriptAchievement{AchievementID: e.AchievementID, Category: e.Category}
+			order = append(order, k)
+		}
+		balances[k].Points += e.Points
+	}
+
+	cohort, hasCohort := leadingYear(student.AcademicYear)
+	years := make(map[string]*TranscriptYear)
+	transcript := &Transcript{Student: student, Years: []TranscriptYear{}}
+	for _, k := range order {
+		item := balances[k]
+		if item.Points == 0 {
+			continue
+		}
+		item.Title = s.achievementTitle(ctx, item.AchievementID)
+
+		year := years[k.year]
+		if year == nil {
+			year = &TranscriptYear{AcademicYear: k.year, Categories: []TranscriptCategory{}}
+			if start, ok := leadingYear(k.year); ok && hasCohort {
+				year.YearOfStudy = start - cohort + 1
+			}
+			years[k.year] = year
+		}
+		year.Points += item.Points
+		year.Achievements = append(year.Achievements, *item)
+		transcript.TotalPoints += item.Points
+	}
+
+	for _, year := range years {
+		byCategory := make(map[string]*TranscriptCategory)
+		for _, a := range year.Achievements {
+			category := byCategory[a.Category]
+			if category == nil {
+				category = &TranscriptCategory{Category: a.Category, Label: labels[a.Category]}
+				byCategory[a.Category] = category
+			}
+			category.Points += a.Points
+			category.Count++
+		}
+		for _, category := range byCategory {
+			year.Categories = append(year.Categories, *category)
+		}
+		sort.Slice(year.Categories, func(i, j int) bool { return year.Categories[i].Category < year.Categories[j].Category })
+		transcript.Years = append(transcript.Years, *year)
+	}
+	sort.Slice(transcript.Years, func(i, j int) bool { return transcript.Years[i].AcademicYear < transcript.Years[j].AcademicYear })
+
+	return transcript, nil
+}
+
+func (s *TranscriptService) achievementTitle(ctx context.Context, achievementID uuid.UUID) string {
+	ref, err := s.achievementRepo.GetReferenceByID(ctx, achievementID)
+	if err != nil || ref == nil {
+		return ""
+	}
+	detail, err := s.achievementRepo.GetDetailByID(ctx, ref.MongoAchievementID)
+	if err != nil || detail == nil {
+		return ""
+	}
+	return detail.Title
+}
+
+// GetCurrent godoc
+// @Summary      Transkrip poin prestasi saya
+// @Description  Ringkasan poin prestasi mahasiswa yang login per tahun akademik dan kategori, dihitung dari buku poin (point_ledger)
+// @Tags         Students
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "Transkrip poin"
+// @Failure      404  {object}  map[string]interface{} "Profil mahasiswa belum dibuat"
+// @Failure      500  {object}  map[string]interface{} "Gagal menyusun transkrip"
+// @Router       /students/current/transcript [get]
+func (s *TranscriptService) GetCurrent(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	student, err := s.studentRepo.GetByUserID(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal mengambil data"})
+	}
+	if student == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Profil mahasiswa belum dibuat. Silakan lengkapi data."})
+	}
+	return s.respond(c, student)
+}
+
+// GetByStudentID godoc
+// @Summary      Transkrip poin prestasi mahasiswa
+// @Description  Ringkasan poin prestasi seorang mahasiswa per tahun akademik dan kategori
+// @Tags         Students
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "ID mahasiswa (UUID)"
+// @Success      200  {object}  map[string]interface{} "Transkrip poin"
+// @Failure      400  {object}  map[string]interface{} "ID tidak valid"
+// @Failure      404  {object}  map[string]interface{} "Mahasiswa tidak ditemukan"
+// @Router       /students/{id}/transcript [get]
+func (s *TranscriptService) GetByStudentID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "ID tidak valid"})
+	}
+
+	student, err := s.studentRepo.GetByID(c.Context(), id)
+	if err != nil || student == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Mahasiswa tidak ditemukan"})
+	}
+	return s.respond(c, student)
+}
+
+func (s *TranscriptService) respond(c *fiber.Ctx, student *models.Students) error {
+	transcript, err := s.Build(c.Context(), student)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Gagal menyusun transkrip poin"})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Transkrip poin prestasi",
+		"data":    transcript,
+	})
+}
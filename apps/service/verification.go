@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotLecturer = errors.New("profil dosen tidak ditemukan")
+	ErrNotAdvisor  = errors.New("hanya dosen wali mahasiswa ini (atau dosen yang diberi delegasi) yang boleh memverifikasi")
+)
+
+// VerificationAuthority menentukan siapa yang boleh memverifikasi prestasi seorang mahasiswa:
+// dosen wali (students.advisor_id) atau dosen yang sedang menerima delegasi darinya.
+type VerificationAuthority struct {
+	lectureRepo    repository.LectureRepository
+	delegationRepo repository.DelegationRepository
+}
+
+func NewVerificationAuthority(lectureRepo repository.LectureRepository, delegationRepo repository.DelegationRepository) *VerificationAuthority {
+	return &VerificationAuthority{lectureRepo: lectureRepo, delegationRepo: delegationRepo}
+}
+
+// Authorize mengembalikan profil dosen pemverifikasi dan delegasi yang dipakai (nil kalau dosen wali sendiri)
+func (a *VerificationAuthority) Authorize(ctx context.Context, userID uuid.UUID, student *models.Students, at time.Time) (*models.Lecture, *models.VerificationDelegation, error) {
+	lecturer, err := a.lectureRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lecturer == nil {
+		return nil, nil, ErrNotLecturer
+	}
+
+	if student.AdvisorID == nil {
+		return lecturer, nil, ErrNotAdvisor
+	}
+	if *student.AdvisorID == lecturer.ID {
+		return lecturer, nil, nil
+	}
+
+	delegation, err := a.delegationRepo.FindActive(ctx, *student.AdvisorID, lecturer.ID, at)
+	if err != nil {
+		return lecturer, nil, err
+	}
+	if delegation == nil {
+		return lecturer, nil, ErrNotAdvisor
+	}
+	return lecturer, delegation, nil
+}
@@ -14,10 +14,19 @@ func NewApp(
 	lectureService *service.LectureService,
 	achievmentService *service.AchievementService,
 	reportService *service.ReportService,
+	passwordService *service.PasswordService,
+	mfaService *service.MFAService,
+	registrationService *service.RegistrationService,
+	roleService *service.RoleService,
+	delegationService *service.DelegationService,
+	achievementTypeService *service.AchievementTypeService,
+	transcriptService *service.TranscriptService,
+	fileStorage *service.FileStorage,
 ) *fiber.App {
-	app := fiber.New()
+	// body multipart sedikit lebih besar dari file di dalamnya, batas per file dicek lagi di FileStorage.Save
+	app := fiber.New(fiber.Config{BodyLimit: int(fileStorage.MaxFileSize()) + 1<<20})
 
-	routes.SetupRoutes(app, authService, permService, studentService, lectureService, achievmentService, reportService)
+	routes.SetupRoutes(app, authService, permService, studentService, lectureService, achievmentService, reportService, passwordService, mfaService, registrationService, roleService, delegationService, achievementTypeService, transcriptService, fileStorage)
 
 	return app
 }
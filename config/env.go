@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,4 +22,52 @@ func GetPort() string {
 		port = "3000"
 	}
 	return ":" + port
-}
\ No newline at end of file
+}
+
+// GetEnv mengembalikan nilai env atau fallback kalau belum diset
+func GetEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// GetDuration membaca env berformat time.ParseDuration (misal "5m", "24h")
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: %s tidak valid (%s), pakai default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+func GetInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: %s tidak valid (%s), pakai default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func GetBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️  Warning: %s tidak valid (%s), pakai default %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
@@ -0,0 +1,15 @@
+package config
+
+import "uas-pelaporan-prestasi-mahasiswa/apps/service"
+
+// LoadLoginGuardOptions membaca batas percobaan login dari env, default lihat service.DefaultLoginGuardOptions
+func LoadLoginGuardOptions() service.LoginGuardOptions {
+	options := service.DefaultLoginGuardOptions()
+	options.MaxUserFailures = GetInt("LOGIN_MAX_USER_FAILURES", options.MaxUserFailures)
+	options.MaxIPFailures = GetInt("LOGIN_MAX_IP_FAILURES", options.MaxIPFailures)
+	options.Window = GetDuration("LOGIN_FAILURE_WINDOW", options.Window)
+	options.LockoutDuration = GetDuration("LOGIN_LOCKOUT_DURATION", options.LockoutDuration)
+	options.BackoffBase = GetDuration("LOGIN_BACKOFF_BASE", options.BackoffBase)
+	options.BackoffMax = GetDuration("LOGIN_BACKOFF_MAX", options.BackoffMax)
+	return options
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+	"uas-pelaporan-prestasi-mahasiswa/notification"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
+)
+
+// LoadPasswordPolicy membaca kebijakan password dari env, default: minimal 8 karakter dan ada angka
+func LoadPasswordPolicy() utils.PasswordPolicy {
+	policy := utils.DefaultPasswordPolicy()
+	policy.MinLength = GetInt("PASSWORD_MIN_LENGTH", policy.MinLength)
+	policy.RequireUpper = GetBool("PASSWORD_REQUIRE_UPPER", policy.RequireUpper)
+	policy.RequireLower = GetBool("PASSWORD_REQUIRE_LOWER", policy.RequireLower)
+	policy.RequireDigit = GetBool("PASSWORD_REQUIRE_DIGIT", policy.RequireDigit)
+	policy.RequireSymbol = GetBool("PASSWORD_REQUIRE_SYMBOL", policy.RequireSymbol)
+
+	if path := GetEnv("PASSWORD_DENYLIST_FILE", ""); path != "" {
+		if err := policy.LoadDenylistFile(path); err != nil {
+			log.Printf("⚠️  Warning: gagal membaca PASSWORD_DENYLIST_FILE: %v", err)
+		}
+	}
+	return policy
+}
+
+// NewNotifier memilih pengirim notifikasi dari env NOTIFIER (log / file)
+func NewNotifier() notification.Notifier {
+	switch GetEnv("NOTIFIER", "log") {
+	case "file":
+		return notification.NewFileNotifier(GetEnv("NOTIFIER_OUTBOX_DIR", "./outbox"))
+	default:
+		return notification.NewLogNotifier()
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"log"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+)
+
+// RoleNames nama role bawaan, bisa diganti lewat env kalau data lama memakai nama lain
+type RoleNames struct {
+	Admin    string
+	Lecturer string
+	Student  string
+}
+
+func LoadRoleNames() RoleNames {
+	return RoleNames{
+		Admin:    GetEnv("PERMISSION_SUPER_ROLE", "Admin"),
+		Lecturer: GetEnv("LECTURER_ROLE_NAME", "Dosen"),
+		Student:  GetEnv("STUDENT_ROLE_NAME", "Mahasiswa"),
+	}
+}
+
+func LoadPermissionSyncOptions(roles RoleNames) service.PermissionSyncOptions {
+	return service.PermissionSyncOptions{
+		Bundles: service.DefaultRoleBundles(roles.Admin, roles.Lecturer, roles.Student),
+		Prune:   GetBool("PERMISSION_SYNC_PRUNE", false),
+	}
+}
+
+// RunPermissionSync dipakai subcommand `go run . sync-permissions [--prune] [--dry-run]`
+func RunPermissionSync(permissionRepo repository.PermissionRepository, roleRepo repository.RoleRepository, options service.PermissionSyncOptions, args []string) error {
+	fs := flag.NewFlagSet("sync-permissions", flag.ContinueOnError)
+	fs.BoolVar(&options.Prune, "prune", options.Prune, "hapus permission yang tidak ada di katalog")
+	fs.BoolVar(&options.DryRun, "dry-run", false, "tampilkan perubahan tanpa menulis ke database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := service.NewPermissionSyncer(permissionRepo, roleRepo, options).Sync(context.Background())
+	if err != nil {
+		return err
+	}
+	if options.DryRun {
+		log.Println("[dry-run] tidak ada yang ditulis ke database")
+	}
+	log.Println(report)
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"uas-pelaporan-prestasi-mahasiswa/apps/models"
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+)
+
+// LoadScoringRules membaca tabel aturan poin dari SCORING_RULES_FILE, default service.DefaultScoringRules
+func LoadScoringRules() (models.ScoringRules, error) {
+	path := GetEnv("SCORING_RULES_FILE", "")
+	if path == "" {
+		return service.DefaultScoringRules(), nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return models.ScoringRules{}, err
+	}
+
+	var rules models.ScoringRules
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return models.ScoringRules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if rules.Version == "" {
+		rules.Version = path
+	}
+	if err := service.ValidateScoringRules(rules); err != nil {
+		return models.ScoringRules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
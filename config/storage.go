@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/antivirus"
+	"uas-pelaporan-prestasi-mahasiswa/storage"
+)
+
+// NewFileStore memilih penyimpanan lampiran dari env FILE_STORE (local / s3)
+func NewFileStore() storage.FileStore {
+	switch GetEnv("FILE_STORE", "local") {
+	case "s3":
+		return storage.NewS3Store(storage.S3Options{
+			Endpoint:  GetEnv("S3_ENDPOINT", "http://localhost:9000"),
+			Region:    GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    GetEnv("S3_BUCKET", "prestasi"),
+			AccessKey: GetEnv("S3_ACCESS_KEY", ""),
+			SecretKey: GetEnv("S3_SECRET_KEY", ""),
+			PathStyle: GetBool("S3_PATH_STYLE", true),
+		})
+	default:
+		return storage.NewLocalStore(GetEnv("UPLOAD_DIR", "./uploads"))
+	}
+}
+
+// NewVirusScanner memilih pemindai virus dari env VIRUS_SCANNER (none / clamav)
+func NewVirusScanner() antivirus.Scanner {
+	switch GetEnv("VIRUS_SCANNER", "none") {
+	case "clamav":
+		return antivirus.NewClamAVScanner(GetEnv("CLAMAV_ADDR", "localhost:3310"), GetDuration("CLAMAV_TIMEOUT", 30*time.Second))
+	default:
+		return antivirus.NewNopScanner()
+	}
+}
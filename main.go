@@ -4,10 +4,13 @@ import (
 	"context"
 	"log"
 	"os"
+	"time"
 	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
 	"uas-pelaporan-prestasi-mahasiswa/apps/service"
 	"uas-pelaporan-prestasi-mahasiswa/config"
 	"uas-pelaporan-prestasi-mahasiswa/database"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+	"uas-pelaporan-prestasi-mahasiswa/utils"
 
 	"github.com/joho/godotenv"
 )
@@ -43,6 +46,27 @@ func main() {
 	}
 	defer pgDB.Close()
 
+	permissionRepo := repository.NewPostgresPermissionRepository(pgDB)
+	roleRepo := repository.NewPostgresRoleRepository(pgDB)
+	roleNames := config.LoadRoleNames()
+	permissionSyncOptions := config.LoadPermissionSyncOptions(roleNames)
+
+	// go run . sync-permissions [--prune] [--dry-run]
+	if len(os.Args) > 1 && os.Args[1] == "sync-permissions" {
+		if err := config.RunPermissionSync(permissionRepo, roleRepo, permissionSyncOptions, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if config.GetBool("PERMISSION_SYNC_ON_START", true) {
+		report, err := service.NewPermissionSyncer(permissionRepo, roleRepo, permissionSyncOptions).Sync(context.Background())
+		if err != nil {
+			log.Fatal("Gagal sync permission: ", err)
+		}
+		log.Println(report)
+	}
+
 	mongoClient, _, err := database.ConnectMongo()
 	if err != nil {
 		log.Fatal(err)
@@ -56,21 +80,111 @@ func main() {
 	mongoDbInstance := mongoClient.Database("db_prestasi")
 
 	userRepo := repository.NewUserRepository(pgDB)
-	permissionRepo := repository.NewPostgresPermissionRepository(pgDB)
 	studentRepo := repository.NewStudentRepository(pgDB)
 	lectureRepo := repository.NewPostgresLectureRepository(pgDB)
 	achievementRepo := repository.NewAchievementRepo(pgDB, mongoDbInstance)
 	reportRepo := repository.NewReportRepository(pgDB)
+	refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(pgDB)
+	passwordResetRepo := repository.NewPostgresPasswordResetRepository(pgDB)
+	loginEventRepo := repository.NewPostgresLoginEventRepository(pgDB)
+	mfaRepo := repository.NewPostgresMFARepository(pgDB)
+	invitationRepo := repository.NewPostgresInvitationRepository(pgDB)
+	delegationRepo := repository.NewPostgresDelegationRepository(pgDB)
+	achievementTypeRepo := repository.NewPostgresAchievementTypeRepository(pgDB)
+
+	seededTypes, err := service.SeedAchievementTypes(context.Background(), achievementTypeRepo)
+	if err != nil {
+		log.Fatal("Gagal menyiapkan tipe prestasi: ", err)
+	}
+	if len(seededTypes) > 0 {
+		log.Println("Tipe prestasi bawaan ditambahkan:", seededTypes)
+	}
+
+	middleware.SetPermissionCache(middleware.NewPermissionCache(
+		permissionRepo,
+		config.GetDuration("PERMISSION_CACHE_TTL", 5*time.Minute),
+		roleNames.Admin,
+	))
+
+	middleware.SetUserStatusCache(middleware.NewUserStatusCache(
+		userRepo,
+		config.GetDuration("PERMISSION_CACHE_TTL", 5*time.Minute),
+	))
+
+	var revocationStore repository.TokenRevocationStore
+	if config.GetEnv("TOKEN_REVOCATION_STORE", "postgres") == "memory" {
+		revocationStore = repository.NewMemoryTokenRevocationStore(utils.AccessTokenTTL)
+	} else {
+		revocationStore = repository.NewPostgresTokenRevocationStore(pgDB)
+	}
+	middleware.SetTokenRevocationStore(revocationStore)
 
-	authService := service.NewAuthService(userRepo, permissionRepo)
+	sessionManager := service.NewSessionManager(refreshTokenRepo, revocationStore)
+
+	loginGuardOptions := config.LoadLoginGuardOptions()
+	var loginAttemptStore repository.LoginAttemptStore
+	if config.GetEnv("LOGIN_ATTEMPT_STORE", "postgres") == "memory" {
+		loginAttemptStore = repository.NewMemoryLoginAttemptStore(loginGuardOptions.Window)
+	} else {
+		loginAttemptStore = repository.NewPostgresLoginAttemptStore(pgDB)
+	}
+	loginGuard := service.NewLoginGuard(loginAttemptStore, loginEventRepo, loginGuardOptions)
+
+	passwordPolicy := config.LoadPasswordPolicy()
+	notifier := config.NewNotifier()
+
+	mfaService := service.NewMFAService(userRepo, permissionRepo, mfaRepo, sessionManager, loginGuard, service.MFAOptions{
+		Issuer:            config.GetEnv("MFA_ISSUER", "Sistem Pelaporan Prestasi"),
+		EncryptionKey:     []byte(config.GetEnv("MFA_ENCRYPTION_KEY", os.Getenv("JWT_SECRET"))),
+		RecoveryCodeCount: config.GetInt("MFA_RECOVERY_CODE_COUNT", 10),
+	})
+
+	authService := service.NewAuthService(userRepo, permissionRepo, sessionManager, loginGuard, mfaService)
+	registrationService := service.NewRegistrationService(userRepo, roleRepo, invitationRepo, notifier, passwordPolicy, service.RegistrationOptions{
+		SelfRegistration: config.GetBool("SELF_REGISTRATION", true),
+		StudentRoleName:  roleNames.Student,
+		LecturerRoleName: roleNames.Lecturer,
+		InvitationTTL:    config.GetDuration("INVITATION_TTL", 72*time.Hour),
+		InvitationURL:    config.GetEnv("INVITATION_URL", "http://localhost:3000/accept-invitation"),
+	})
 	permService := service.NewPermissionService(permissionRepo)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, service.RoleServiceOptions{
+		ProtectedRoles: []string{roleNames.Admin, roleNames.Student, roleNames.Lecturer},
+	})
 	studentService := service.NewStudentService(studentRepo)
 	lectureService := service.NewLectureService(lectureRepo)
-	achievementService := service.NewAchievementService(achievementRepo, studentRepo)
+	verificationAuthority := service.NewVerificationAuthority(lectureRepo, delegationRepo)
+	scoringRules, err := config.LoadScoringRules()
+	if err != nil {
+		log.Fatal("Gagal membaca aturan scoring: ", err)
+	}
+	scoringEngine := service.NewScoringEngine(scoringRules, achievementTypeRepo)
+	achievementRevisionRepo := repository.NewMongoAchievementRevisionRepository(mongoDbInstance)
+	achievementVersionRepo := repository.NewMongoAchievementVersionRepository(mongoDbInstance)
+	pointLedgerRepo := repository.NewPostgresPointLedgerRepository(pgDB)
+	achievementMemberRepo := repository.NewPostgresAchievementMemberRepository(pgDB)
+	fileStorage := service.NewFileStorage(config.NewFileStore(), repository.NewPostgresFileRepository(pgDB), config.NewVirusScanner(), service.FileStorageOptions{
+		BaseURL:       config.GetEnv("FILE_BASE_URL", "http://localhost:3000/api/v1"),
+		SigningKey:    []byte(config.GetEnv("FILE_SIGNING_KEY", os.Getenv("JWT_SECRET"))),
+		SignedURLTTL:  config.GetDuration("FILE_SIGNED_URL_TTL", 15*time.Minute),
+		MaxFileSize:   int64(config.GetInt("FILE_MAX_SIZE_MB", 10)) << 20,
+		UserQuota:     int64(config.GetInt("FILE_USER_QUOTA_MB", 100)) << 20,
+		ThumbnailSize: config.GetInt("FILE_THUMBNAIL_SIZE", 320),
+	})
+	fileStorage.StartOrphanSweeper(context.Background(), config.GetDuration("FILE_ORPHAN_SWEEP_INTERVAL", time.Hour), config.GetDuration("FILE_ORPHAN_TTL", 24*time.Hour))
+	achievementService := service.NewAchievementService(achievementRepo, studentRepo, verificationAuthority, service.NewAchievementValidator(achievementTypeRepo), scoringEngine, service.NewPointLedger(pointLedgerRepo), achievementRevisionRepo, achievementVersionRepo, achievementMemberRepo, fileStorage, service.AchievementOptions{
+		MaxResubmissions: config.GetInt("MAX_RESUBMISSIONS", 3),
+	})
+	delegationService := service.NewDelegationService(lectureRepo, delegationRepo)
+	achievementTypeService := service.NewAchievementTypeService(achievementTypeRepo)
+	transcriptService := service.NewTranscriptService(studentRepo, pointLedgerRepo, achievementRepo, achievementTypeRepo)
 	reportService := service.NewReportService(reportRepo, studentRepo, achievementRepo)
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, sessionManager, notifier, passwordPolicy, service.PasswordResetOptions{
+		TokenTTL: config.GetDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		ResetURL: config.GetEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+	})
 
-	app := config.NewApp(authService, permService, studentService, lectureService, achievementService, reportService)
-	app.Static("/uploads", "./uploads")
+	app := config.NewApp(authService, permService, studentService, lectureService, achievementService, reportService, passwordService, mfaService, registrationService, roleService, delegationService, achievementTypeService, transcriptService, fileStorage)
 
 	port := os.Getenv("APP_PORT")
 	if port == "" {
@@ -1,13 +1,58 @@
 package middleware
 
 import (
+	"slices"
 	"strings"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
 	"uas-pelaporan-prestasi-mahasiswa/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+var revocationStore repository.TokenRevocationStore
+
+// SetTokenRevocationStore dipanggil sekali di main. Kalau tidak diset, AuthProtected
+// hanya mengecek tanda tangan dan expiry token.
+func SetTokenRevocationStore(store repository.TokenRevocationStore) {
+	revocationStore = store
+}
+
+// isRevoked mengecek denylist jti dan batas "token diterbitkan sebelum" milik user
+func isRevoked(c *fiber.Ctx, claims *utils.JWTClaims) (bool, error) {
+	if revocationStore == nil {
+		return false, nil
+	}
+
+	ctx := c.Context()
+	if claims.ID != "" {
+		revoked, err := revocationStore.IsTokenRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	notBefore, err := revocationStore.GetUserTokensNotBefore(ctx, claims.UserID)
+	if err != nil || notBefore == nil {
+		return false, err
+	}
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	// token yang diterbitkan tepat di batas ikut dicabut; token lama dengan iat per detik jatuh di atau sebelum batas
+	return !claims.IssuedAt.Time.After(*notBefore), nil
+}
+
 func AuthProtected() fiber.Handler {
+	return authenticate(utils.TokenTypeAccess)
+}
+
+// AuthMFASetup seperti AuthProtected tapi juga menerima token mfa_setup, khusus untuk endpoint
+// enroll MFA yang harus bisa diakses user yang role-nya wajib MFA tapi belum pernah enroll
+func AuthMFASetup() fiber.Handler {
+	return authenticate(utils.TokenTypeAccess, utils.TokenTypeMFASetup)
+}
+
+func authenticate(tokenTypes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		
 		authHeader := c.Get("Authorization")
@@ -25,12 +70,35 @@ func AuthProtected() fiber.Handler {
 
 		
 		claims, err := utils.ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || !slices.Contains(tokenTypes, claims.TokenType) {
 			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: Token tidak valid atau kadaluwarsa"})
 		}
 
+		revoked, err := isRevoked(c, claims)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa status token"})
+		}
+		if revoked {
+			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: Token sudah dicabut, silakan login ulang"})
+		}
+
+		if userStatusCache != nil {
+			active, err := userStatusCache.Active(c.Context(), claims.UserID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Gagal memeriksa status akun"})
+			}
+			if !active {
+				return c.Status(403).JSON(fiber.Map{"error": "Akun sudah dinonaktifkan", "code": "ACCOUNT_INACTIVE"})
+			}
+		}
+
 		c.Locals("user_id", claims.UserID.String())
 		c.Locals("role", claims.RoleName)
+		c.Locals("token_id", claims.ID)
+		c.Locals("token_type", claims.TokenType)
+		if claims.ExpiresAt != nil {
+			c.Locals("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		return c.Next()
 	}
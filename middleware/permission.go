@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionCache menyimpan permission tiap role di memori supaya
+// RequirePermission tidak query ke PostgreSQL di setiap request.
+type PermissionCache struct {
+	repo      repository.PermissionRepository
+	ttl       time.Duration
+	superRole string
+
+	mu      sync.RWMutex
+	entries map[string]permissionEntry
+}
+
+type permissionEntry struct {
+	keys      map[string]bool
+	expiresAt time.Time
+}
+
+// NewPermissionCache - superRole (misal "Admin") selalu lolos pengecekan,
+// kosongkan kalau semua role harus lewat tabel role_permissions.
+func NewPermissionCache(repo repository.PermissionRepository, ttl time.Duration, superRole string) *PermissionCache {
+	return &PermissionCache{
+		repo:      repo,
+		ttl:       ttl,
+		superRole: superRole,
+		entries:   make(map[string]permissionEntry),
+	}
+}
+
+// Allowed mengecek apakah role punya salah satu permission (format resource:action)
+func (pc *PermissionCache) Allowed(ctx context.Context, role string, permissions ...string) (bool, error) {
+	if pc.superRole != "" && strings.EqualFold(role, pc.superRole) {
+		return true, nil
+	}
+
+	keys, err := pc.lookup(ctx, role)
+	if err != nil {
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if keys[strings.ToLower(p)] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (pc *PermissionCache) lookup(ctx context.Context, role string) (map[string]bool, error) {
+	cacheKey := strings.ToLower(role)
+
+	pc.mu.RLock()
+	entry, ok := pc.entries[cacheKey]
+	pc.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.keys, nil
+	}
+
+	perms, err := pc.repo.GetByRoleName(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		keys[strings.ToLower(p.Key())] = true
+	}
+
+	pc.mu.Lock()
+	pc.entries[cacheKey] = permissionEntry{keys: keys, expiresAt: time.Now().Add(pc.ttl)}
+	pc.mu.Unlock()
+
+	return keys, nil
+}
+
+// InvalidateAll dipanggil setiap kali relasi role <-> permission berubah
+func (pc *PermissionCache) InvalidateAll() {
+	pc.mu.Lock()
+	pc.entries = make(map[string]permissionEntry)
+	pc.mu.Unlock()
+}
+
+var permissionCache *PermissionCache
+
+// SetPermissionCache dipanggil sekali di main sebelum app jalan
+func SetPermissionCache(pc *PermissionCache) {
+	permissionCache = pc
+}
+
+// InvalidatePermissionCache aman dipanggil walaupun cache belum diset (misal di unit test)
+func InvalidatePermissionCache() {
+	if permissionCache != nil {
+		permissionCache.InvalidateAll()
+	}
+}
+
+// HasPermission dipakai di service untuk cek akses tambahan (misal: edit data milik orang lain)
+func HasPermission(c *fiber.Ctx, permissions ...string) bool {
+	role, ok := c.Locals("role").(string)
+	if !ok || permissionCache == nil {
+		return false
+	}
+
+	allowed, err := permissionCache.Allowed(c.Context(), role, permissions...)
+	return err == nil && allowed
+}
+
+// RequirePermission menolak request kalau role user tidak punya salah satu permission yang diminta.
+// Harus dipasang setelah AuthProtected.
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := c.Locals("role").(string)
+		if !ok {
+			return c.Status(401).JSON(fiber.Map{"error": "Unauthorized: Role tidak ditemukan"})
+		}
+
+		if permissionCache == nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Permission cache belum dikonfigurasi"})
+		}
+
+		allowed, err := permissionCache.Allowed(c.Context(), role, permissions...)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Gagal mengecek permission"})
+		}
+
+		if !allowed {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "Forbidden: Anda tidak memiliki akses untuk fitur ini",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// IsSuperRole true kalau role user adalah PERMISSION_SUPER_ROLE (admin), untuk akses yang tidak cukup dicek per permission
+func IsSuperRole(c *fiber.Ctx) bool {
+	role, ok := c.Locals("role").(string)
+	if !ok || permissionCache == nil || permissionCache.superRole == "" {
+		return false
+	}
+	return strings.EqualFold(role, permissionCache.superRole)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+	"uas-pelaporan-prestasi-mahasiswa/apps/repository"
+
+	"github.com/google/uuid"
+)
+
+// UserStatusCache menyimpan status is_active tiap user supaya AuthProtected bisa menolak akun nonaktif
+// tanpa query ke PostgreSQL di setiap request. Tidak bergantung pada batas token di TokenRevocationStore,
+// jadi tetap berlaku walaupun store memory hilang saat restart.
+type UserStatusCache struct {
+	repo repository.UserRepository
+	ttl  time.Duration
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]userStatusEntry
+}
+
+type userStatusEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+func NewUserStatusCache(repo repository.UserRepository, ttl time.Duration) *UserStatusCache {
+	return &UserStatusCache{
+		repo:    repo,
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]userStatusEntry),
+	}
+}
+
+// Active false untuk user yang dinonaktifkan atau sudah tidak ada
+func (uc *UserStatusCache) Active(ctx context.Context, userID uuid.UUID) (bool, error) {
+	uc.mu.RLock()
+	entry, ok := uc.entries[userID]
+	uc.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	user, err := uc.repo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	active := user != nil && user.IsActive
+
+	uc.mu.Lock()
+	uc.entries[userID] = userStatusEntry{active: active, expiresAt: time.Now().Add(uc.ttl)}
+	uc.mu.Unlock()
+
+	return active, nil
+}
+
+// Invalidate dipanggil setiap kali status aktif user berubah
+func (uc *UserStatusCache) Invalidate(userID uuid.UUID) {
+	uc.mu.Lock()
+	delete(uc.entries, userID)
+	uc.mu.Unlock()
+}
+
+var userStatusCache *UserStatusCache
+
+// SetUserStatusCache dipanggil sekali di main. Kalau tidak diset, AuthProtected tidak mengecek is_active
+func SetUserStatusCache(uc *UserStatusCache) {
+	userStatusCache = uc
+}
+
+// InvalidateUserStatus aman dipanggil walaupun cache belum diset (misal di unit test)
+func InvalidateUserStatus(userID uuid.UUID) {
+	if userStatusCache != nil {
+		userStatusCache.Invalidate(userID)
+	}
+}
@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier mengirim pesan ke user (reset password, undangan, dll).
+// Implementasi email/SMS tinggal memenuhi interface ini.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogNotifier mencetak pesan ke log, cukup untuk development lokal
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, msg Message) error {
+	log.Printf("📧 [notifikasi] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileNotifier menulis setiap pesan sebagai file .txt di satu folder (semacam outbox lokal)
+type FileNotifier struct {
+	dir string
+}
+
+func NewFileNotifier(dir string) *FileNotifier {
+	return &FileNotifier{dir: dir}
+}
+
+func (n *FileNotifier) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(n.dir, 0755); err != nil {
+		return err
+	}
+
+	recipient := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, msg.To)
+	name := fmt.Sprintf("%d-%s.txt", time.Now().UnixNano(), recipient)
+
+	content := fmt.Sprintf("To: %s\nSubject: %s\nDate: %s\n\n%s\n", msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	return os.WriteFile(filepath.Join(n.dir, name), []byte(content), 0600)
+}
@@ -11,15 +11,38 @@ func AchievementRoutes(router fiber.Router, Achievservice *service.AchievementSe
 
 	achievements := router.Group("/achievements", middleware.AuthProtected())
 
-	achievements.Post("/upload", middleware.VerifyRole("Mahasiswa"), Achievservice.UploadAttachment)
+	achievements.Post("/upload", middleware.RequirePermission("achievement:create"), Achievservice.UploadAttachment)
 
-	achievements.Get("/raw-mongo", Achievservice.GetAllMongoData)
-	achievements.Get("/achiev", middleware.VerifyRole("Mahasiswa"), Achievservice.GetMyAchievment)
-	achievements.Post("/", middleware.VerifyRole("Mahasiswa"), Achievservice.Create)
-	achievements.Get("/", Achievservice.GetAll)
-	achievements.Get("/:id", Achievservice.GetByID)
-	achievements.Put("/:id", middleware.VerifyRole("Mahasiswa"), Achievservice.Update)
-	achievements.Delete("/:id", middleware.VerifyRole("Mahasiswa"), Achievservice.Delete)
-	achievements.Patch("/:id/verify", middleware.VerifyRole("Dosen"), Achievservice.Verify)
-	achievements.Patch("/:id/submit", middleware.VerifyRole("Mahasiswa"), Achievservice.Submit)
+	achievements.Get("/raw-mongo", middleware.RequirePermission("achievement:read_raw"), Achievservice.GetAllMongoData)
+	achievements.Get("/achiev", middleware.RequirePermission("achievement:read_own"), Achievservice.GetMyAchievment)
+	achievements.Get("/invitations", middleware.RequirePermission("achievement:create"), Achievservice.GetInvitations)
+	achievements.Post("/", middleware.RequirePermission("achievement:create"), Achievservice.Create)
+	achievements.Post("/scores/recompute", middleware.RequirePermission("achievement:rescore"), Achievservice.RecomputeScores)
+	achievements.Get("/", middleware.RequirePermission("achievement:read"), Achievservice.GetAll)
+	achievements.Get("/:id", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetByID)
+	achievements.Put("/:id", middleware.RequirePermission("achievement:update"), Achievservice.Update)
+	achievements.Delete("/:id", middleware.RequirePermission("achievement:delete"), Achievservice.Delete)
+	achievements.Patch("/:id/verify", middleware.RequirePermission("achievement:verify"), Achievservice.Verify)
+	achievements.Patch("/:id/revoke", middleware.RequirePermission("achievement:verify"), Achievservice.Revoke)
+	achievements.Patch("/:id/submit", middleware.RequirePermission("achievement:submit"), Achievservice.Submit)
+	achievements.Patch("/:id/withdraw", middleware.RequirePermission("achievement:submit"), Achievservice.Withdraw)
+	achievements.Patch("/:id/reopen", middleware.RequirePermission("achievement:submit"), Achievservice.Reopen)
+	achievements.Get("/:id/possible-duplicates", middleware.RequirePermission("achievement:verify"), Achievservice.GetPossibleDuplicates)
+	achievements.Get("/:id/members", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetMembers)
+	achievements.Post("/:id/members", middleware.RequirePermission("achievement:update"), Achievservice.InviteMember)
+	achievements.Put("/:id/members", middleware.RequirePermission("achievement:update"), Achievservice.UpdateMembers)
+	achievements.Delete("/:id/members/:studentId", middleware.RequirePermission("achievement:update"), Achievservice.RemoveMember)
+	achievements.Patch("/:id/invitation/accept", middleware.RequirePermission("achievement:create"), Achievservice.AcceptInvitation)
+	achievements.Patch("/:id/invitation/decline", middleware.RequirePermission("achievement:create"), Achievservice.DeclineInvitation)
+	achievements.Post("/:id/attachments", middleware.RequirePermission("achievement:update"), Achievservice.AddAttachment)
+	achievements.Delete("/:id/attachments/:fileId", middleware.RequirePermission("achievement:update"), Achievservice.RemoveAttachment)
+	achievements.Get("/:id/attachments/:fileId", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.DownloadAttachment)
+	achievements.Get("/:id/attachments/:fileId/thumbnail", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.DownloadAttachmentThumbnail)
+	achievements.Get("/:id/attachments/:fileId/signed-url", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetAttachmentSignedURL)
+	achievements.Get("/:id/history", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetHistory)
+	achievements.Get("/:id/revisions", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetRevisions)
+	achievements.Get("/:id/revisions/diff", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.DiffRevisions)
+	achievements.Get("/:id/versions", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetVersions)
+	achievements.Get("/:id/versions/diff", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.DiffVersions)
+	achievements.Get("/:id/versions/:n", middleware.RequirePermission("achievement:read", "achievement:read_own"), Achievservice.GetVersion)
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AchievementTypeRoutes(router fiber.Router, typeService *service.AchievementTypeService) {
+
+	types := router.Group("/achievement-types", middleware.AuthProtected())
+
+	// dibaca semua user login untuk menampilkan form prestasi
+	types.Get("/", typeService.GetAll)
+	types.Get("/:key", typeService.GetByKey)
+	types.Post("/", middleware.RequirePermission("achievement_type:manage"), typeService.Create)
+	types.Put("/:key", middleware.RequirePermission("achievement_type:manage"), typeService.Update)
+	types.Delete("/:key", middleware.RequirePermission("achievement_type:manage"), typeService.Delete)
+}
@@ -14,18 +14,24 @@ func RegisterAuthRoutes(router fiber.Router, authService *service.AuthService) {
 	auth := router.Group("/auth")
 
 	
-	auth.Post("Register",authService.Register)
 	auth.Post("Login",authService.Login)
-	auth.Put("user/:id",authService.UpdateUser)
-	auth.Get("/",authService.GetAllUser)
+	// pemilik profil boleh update sendiri, selain itu butuh user:update (dicek di handler)
+	auth.Put("user/:id",middleware.AuthProtected(),authService.UpdateUser)
+	auth.Get("/",middleware.AuthProtected(),middleware.RequirePermission("user:read"),authService.GetAllUser)
 
 	// update role
-	auth.Put("/:id",authService.UpdateRoleUser)
+	auth.Put("/:id",middleware.AuthProtected(),middleware.RequirePermission("user:assign_role"),authService.UpdateRoleUser)
 
 	auth.Get("profile",middleware.AuthProtected(),authService.GetProfile)
 	auth.Post("/refresh", authService.RefreshToken)
-	auth.Delete("/:id", middleware.VerifyRole("Admin"),authService.DeleteUser)
+	auth.Delete("/:id", middleware.AuthProtected(),middleware.RequirePermission("user:delete"),authService.DeleteUser)
+	auth.Patch("/:id/deactivate", middleware.AuthProtected(),middleware.RequirePermission("user:manage_status"),authService.DeactivateUser)
+	auth.Patch("/:id/activate", middleware.AuthProtected(),middleware.RequirePermission("user:manage_status"),authService.ActivateUser)
 	auth.Post("logout",middleware.AuthProtected(),authService.Logout)
+	auth.Post("logout-all",middleware.AuthProtected(),authService.LogoutAll)
+	auth.Get("sessions",middleware.AuthProtected(),authService.GetSessions)
+	auth.Get("login-events",middleware.AuthProtected(),middleware.RequirePermission("user:read"),authService.GetLoginEvents)
+	auth.Post("/:id/unlock", middleware.AuthProtected(),middleware.RequirePermission("user:manage_status"),authService.UnlockUser)
 
 
 }
\ No newline at end of file
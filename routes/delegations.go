@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DelegationRoutes(router fiber.Router, delegationService *service.DelegationService) {
+
+	// hanya dosen yang punya hak verifikasi yang bisa mendelegasikannya
+	delegations := router.Group("/delegations", middleware.AuthProtected(), middleware.RequirePermission("achievement:verify"))
+
+	delegations.Post("/", delegationService.Create)
+	delegations.Get("/", delegationService.GetMine)
+	delegations.Delete("/:id", delegationService.Revoke)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func FileRoutes(router fiber.Router, fileStorage *service.FileStorage) {
+
+	files := router.Group("/files")
+
+	// signed URL dipakai tanpa login, aksesnya dijamin oleh signature
+	files.Get("/:id/signed", fileStorage.DownloadSigned)
+	files.Get("/:id", middleware.AuthProtected(), fileStorage.Download)
+	files.Get("/:id/thumbnail", middleware.AuthProtected(), fileStorage.DownloadThumbnail)
+}
@@ -10,11 +10,11 @@ import (
 func LectureRoutes(router fiber.Router, lectService *service.LectureService) {
 
 	lecture := router.Group("/lectures", middleware.AuthProtected())
-	lecture.Post("/", lectService.Create)
-	lecture.Get("/", lectService.GetAll)
-	lecture.Get("/current", lectService.GetCurrentLecture)
-	lecture.Get("/:id", lectService.GetByID)
-	lecture.Put("/:id", lectService.Update)
-	lecture.Delete("/:id", middleware.VerifyRole("Admin"), lectService.Delete)
+	lecture.Post("/", middleware.RequirePermission("lecturer:create", "lecturer:create_own"), lectService.Create)
+	lecture.Get("/", middleware.RequirePermission("lecturer:read"), lectService.GetAll)
+	lecture.Get("/current", middleware.RequirePermission("lecturer:read_own"), lectService.GetCurrentLecture)
+	lecture.Get("/:id", middleware.RequirePermission("lecturer:read"), lectService.GetByID)
+	lecture.Put("/:id", middleware.RequirePermission("lecturer:update"), lectService.Update)
+	lecture.Delete("/:id", middleware.RequirePermission("lecturer:delete"), lectService.Delete)
 
 }
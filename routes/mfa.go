@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MFARoutes(router fiber.Router, mfaService *service.MFAService) {
+
+	router.Post("/auth/login/mfa", mfaService.VerifyLogin)
+
+	mfa := router.Group("/auth/mfa")
+
+	mfa.Get("/", middleware.AuthProtected(), mfaService.GetStatus)
+	// enroll & confirm juga menerima token mfa_setup dari login user yang role-nya wajib MFA
+	mfa.Post("/enroll", middleware.AuthMFASetup(), mfaService.Enroll)
+	mfa.Post("/confirm", middleware.AuthMFASetup(), mfaService.Confirm)
+	mfa.Post("/disable", middleware.AuthProtected(), mfaService.Disable)
+	mfa.Post("/recovery-codes", middleware.AuthProtected(), mfaService.RegenerateRecoveryCodes)
+
+	mfa.Delete("/users/:id", middleware.AuthProtected(), middleware.RequirePermission("user:manage_status"), mfaService.ResetUserMFA)
+	mfa.Put("/roles/:id", middleware.AuthProtected(), middleware.RequirePermission("permission:manage"), mfaService.SetRoleRequirement)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PasswordRoutes(router fiber.Router, passwordService *service.PasswordService) {
+
+	password := router.Group("/auth/password")
+
+	password.Post("/change", middleware.AuthProtected(), passwordService.ChangePassword)
+	password.Post("/forgot", passwordService.ForgotPassword)
+	password.Post("/reset", passwordService.ResetPassword)
+}
@@ -3,6 +3,7 @@ package routes
 
 import (
 	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,12 +12,12 @@ import (
 func PermissionRoutes(router fiber.Router, permService *service.PermissionService) {
 	
 	
-	permission := router.Group("/permissions")
+	permission := router.Group("/permissions", middleware.AuthProtected())
 	
 
-	permission.Post("/", permService.Create)
-	permission.Get("/", permService.GetAll)
-	permission.Post("/assign", permService.AssignToRole)
+	permission.Post("/", middleware.RequirePermission("permission:manage"), permService.Create)
+	permission.Get("/", middleware.RequirePermission("permission:read"), permService.GetAll)
+	permission.Post("/assign", middleware.RequirePermission("permission:manage"), permService.AssignToRole)
 	
 
 	
@@ -24,4 +25,4 @@ func PermissionRoutes(router fiber.Router, permService *service.PermissionServic
 	
 
 
-}
\ No newline at end of file
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RegistrationRoutes(router fiber.Router, registrationService *service.RegistrationService) {
+
+	auth := router.Group("/auth")
+
+	// registrasi mandiri selalu jadi mahasiswa, role lain lewat admin atau undangan
+	auth.Post("Register", registrationService.Register)
+	auth.Post("users", middleware.AuthProtected(), middleware.RequirePermission("user:create"), registrationService.CreateUser)
+
+	auth.Post("invitations/accept", registrationService.AcceptInvitation)
+	auth.Post("invitations", middleware.AuthProtected(), middleware.RequirePermission("user:create"), registrationService.CreateInvitation)
+	auth.Get("invitations", middleware.AuthProtected(), middleware.RequirePermission("user:create"), registrationService.GetInvitations)
+	auth.Delete("invitations/:id", middleware.AuthProtected(), middleware.RequirePermission("user:create"), registrationService.CancelInvitation)
+}
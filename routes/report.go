@@ -10,6 +10,6 @@ import (
 func ReportRoutes(router fiber.Router, reportService *service.ReportService) {
 	reports := router.Group("/reports", middleware.AuthProtected())
 
-	reports.Get("/statistics", middleware.VerifyRole("Admin", "Dosen"), reportService.GetStatistics)
-	reports.Get("/student/:id", middleware.VerifyRole("Admin", "Dosen"), reportService.GetStudentReport)
+	reports.Get("/statistics", middleware.RequirePermission("report:read"), reportService.GetStatistics)
+	reports.Get("/student/:id", middleware.RequirePermission("report:read"), reportService.GetStudentReport)
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"uas-pelaporan-prestasi-mahasiswa/apps/service"
+	"uas-pelaporan-prestasi-mahasiswa/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RoleRoutes(router fiber.Router, roleService *service.RoleService) {
+
+	roles := router.Group("/roles", middleware.AuthProtected())
+
+	roles.Get("/", middleware.RequirePermission("role:read"), roleService.GetAll)
+	roles.Post("/", middleware.RequirePermission("role:manage"), roleService.Create)
+	roles.Get("/:id", middleware.RequirePermission("role:read"), roleService.GetByID)
+	roles.Put("/:id", middleware.RequirePermission("role:manage"), roleService.Update)
+	roles.Delete("/:id", middleware.RequirePermission("role:manage"), roleService.Delete)
+
+	roles.Get("/:id/permissions", middleware.RequirePermission("role:read"), roleService.GetPermissions)
+	roles.Post("/:id/permissions", middleware.RequirePermission("role:manage"), roleService.AssignPermission)
+	roles.Delete("/:id/permissions/:permissionId", middleware.RequirePermission("role:manage"), roleService.RevokePermission)
+}
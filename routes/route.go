@@ -16,16 +16,31 @@ func SetupRoutes(app *fiber.App,
 	lectureService *service.LectureService,
 	achievService *service.AchievementService,
 	reportService *service.ReportService,
+	passwordService *service.PasswordService,
+	mfaService *service.MFAService,
+	registrationService *service.RegistrationService,
+	roleService *service.RoleService,
+	delegationService *service.DelegationService,
+	achievementTypeService *service.AchievementTypeService,
+	transcriptService *service.TranscriptService,
+	fileStorage *service.FileStorage,
 ) {
 	// app.Use(logger.new())
 	app.Use(cors.New())
 
 	api := app.Group("/api/v1")
+	PasswordRoutes(api, passwordService)
+	MFARoutes(api, mfaService)
+	RegistrationRoutes(api, registrationService)
 	RegisterAuthRoutes(api, authService)
 	PermissionRoutes(api, permService)
-	StudentRoutes(api, studentService)
+	RoleRoutes(api, roleService)
+	StudentRoutes(api, studentService, transcriptService)
 	LectureRoutes(api, lectureService)
+	AchievementTypeRoutes(api, achievementTypeService)
 	AchievementRoutes(api, achievService)
+	FileRoutes(api, fileStorage)
+	DelegationRoutes(api, delegationService)
 	ReportRoutes(api, reportService)
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
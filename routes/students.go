@@ -7,17 +7,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func StudentRoutes(router fiber.Router, studentService *service.StudentService) {
+func StudentRoutes(router fiber.Router, studentService *service.StudentService, transcriptService *service.TranscriptService) {
 
 	students := router.Group("/students", middleware.AuthProtected())
 
-	students.Get("/", middleware.VerifyRole("Dosen","Admin",), studentService.GetAll)
-	students.Get("/advisor/:id", middleware.VerifyRole("Admin"), studentService.GetByAdvisorID)
-	students.Post("/", studentService.Create)
-	students.Get("/current", studentService.GetCurrentStudent)
-	students.Get("/:id", studentService.GetByID)
-	students.Put("/:id", studentService.Update)
-	students.Put("/:id/advisor", middleware.VerifyRole("Admin"), studentService.AssignAdvisor)
-	students.Delete("/:id", middleware.VerifyRole("Admin"), studentService.Delete)
+	students.Get("/", middleware.RequirePermission("student:read"), studentService.GetAll)
+	students.Get("/advisor/:id", middleware.RequirePermission("student:read"), studentService.GetByAdvisorID)
+	students.Post("/", middleware.RequirePermission("student:create", "student:create_own"), studentService.Create)
+	students.Get("/current", middleware.RequirePermission("student:read_own"), studentService.GetCurrentStudent)
+	students.Get("/current/transcript", middleware.RequirePermission("student:read_own"), transcriptService.GetCurrent)
+	students.Get("/:id", middleware.RequirePermission("student:read"), studentService.GetByID)
+	students.Get("/:id/transcript", middleware.RequirePermission("student:read"), transcriptService.GetByStudentID)
+	students.Put("/:id", middleware.RequirePermission("student:update"), studentService.Update)
+	students.Put("/:id/advisor", middleware.RequirePermission("student:assign_advisor"), studentService.AssignAdvisor)
+	students.Delete("/:id", middleware.RequirePermission("student:delete"), studentService.Delete)
 
 }
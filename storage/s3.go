@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+type S3Options struct {
+	Endpoint  string // misal https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000 (MinIO)
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle memakai endpoint/bucket/key (MinIO), false memakai bucket.endpoint/key (AWS)
+	PathStyle bool
+}
+
+// S3Store menyimpan file di storage yang kompatibel dengan S3 (AWS S3, MinIO, dll).
+// Request ditandatangani AWS Signature Version 4 tanpa SDK
+type S3Store struct {
+	opts   S3Options
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Store(opts S3Options) *S3Store {
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	return &S3Store{
+		opts:   opts,
+		client: &http.Client{Timeout: 60 * time.Second},
+		now:    time.Now,
+	}
+}
+
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("key file tidak valid: %q", key)
+	}
+	endpoint, err := url.Parse(s.opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.PathStyle {
+		endpoint.Path = "/" + s.opts.Bucket + "/" + key
+	} else {
+		endpoint.Host = s.opts.Bucket + "." + endpoint.Host
+		endpoint.Path = "/" + key
+	}
+	return endpoint, nil
+}
+
+func (s *S3Store) do(ctx context.Context, method string, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, payloadHash)
+	return s.client.Do(req)
+}
+
+// sign menambahkan header Authorization AWS SigV4. Header yang ditandatangani: host, x-amz-content-sha256, x-amz-date
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Error membaca potongan body error S3 (XML) untuk pesan yang bisa dibaca
+func s3Error(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s gagal: %s %s", op, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, body, size, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return s3Error("put", resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		defer resp.Body.Close()
+		return nil, s3Error("get", resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, "")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode/100 != 2:
+		return false, s3Error("head", resp)
+	}
+	return true, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete", resp)
+	}
+	return nil
+}
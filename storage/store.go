@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("file tidak ditemukan di storage")
+
+// FileStore tempat menyimpan isi file lampiran. Key dibuat pemanggil (lihat ContentKey), isi file tidak pernah
+// disajikan langsung ke publik (download lewat service.FileStorage),
+// implementasi lain (GCS, Azure, dll) tinggal memenuhi interface ini.
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentKey key berdasarkan SHA-256 isi file, dipecah 2 karakter pertama supaya satu folder tidak terlalu besar:
+// "ab12..." + ".pdf" -> "ab/ab12....pdf". File yang isinya sama selalu mendapat key yang sama
+func ContentKey(sha256Hex string, ext string) string {
+	return sha256Hex[:2] + "/" + sha256Hex + strings.ToLower(ext)
+}
+
+// ThumbnailKey key thumbnail JPEG, disimpan di samping file aslinya: "ab/ab12....thumb.jpg"
+func ThumbnailKey(sha256Hex string) string {
+	return sha256Hex[:2] + "/" + sha256Hex + ".thumb.jpg"
+}
+
+// validKey menolak key yang bisa keluar dari folder / bucket (path traversal)
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -8,9 +9,31 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+	// TokenTypeMFAPending diberikan setelah password benar, hanya bisa ditukar di /auth/login/mfa
+	TokenTypeMFAPending = "mfa_pending"
+	// TokenTypeMFASetup diberikan ke user yang role-nya wajib MFA tapi belum enroll, hanya bisa dipakai di /auth/mfa/enroll & confirm
+	TokenTypeMFASetup = "mfa_setup"
+
+	AccessTokenTTL  = 24 * time.Hour
+	RefreshTokenTTL = 7 * 24 * time.Hour
+	MFATokenTTL     = 10 * time.Minute
+)
+
+var ErrInvalidTokenType = errors.New("tipe token tidak sesuai")
+
+func init() {
+	// iat disimpan sampai mikrodetik (presisi timestamp Postgres) supaya token yang diterbitkan
+	// di detik yang sama dengan batas pencabutan tetap bisa dibedakan
+	jwt.TimePrecision = time.Microsecond
+}
+
 type JWTClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	RoleName string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	RoleName  string    `json:"role"`
+	TokenType string    `json:"type"`
 	jwt.RegisteredClaims
 }
 
@@ -18,40 +41,45 @@ func getSecret() []byte {
 	return []byte(os.Getenv("JWT_SECRET"))
 }
 
-func GenerateToken(userID uuid.UUID, roleName string) (string, string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"role":    roleName,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), 
+func newClaims(userID uuid.UUID, roleName string, tokenType string, ttl time.Duration) JWTClaims {
+	now := time.Now()
+	return JWTClaims{
+		UserID:    userID,
+		RoleName:  roleName,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			// jti unik supaya dua token yang dibuat di detik yang sama tetap berbeda
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessTokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+func GenerateToken(userID uuid.UUID, roleName string) (string, string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(userID, roleName, TokenTypeAccess, AccessTokenTTL))
+	accessTokenString, err := token.SignedString(getSecret())
 	if err != nil {
 		return "", "", err
 	}
-	refreshClaims := jwt.MapClaims{
-		"user_id": userID,
-		"role":    roleName,
-		"type":    "refresh_token", 
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), 
-	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString([]byte(os.Getenv("JWT_SECRET")))
+
+	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(userID, roleName, TokenTypeRefresh, RefreshTokenTTL))
+	refreshTokenString, err := refreshToken.SignedString(getSecret())
 	if err != nil {
 		return "", "", err
 	}
 
 	return accessTokenString, refreshTokenString, nil
 }
+// GenerateMFAToken membuat token pendek untuk langkah kedua login (mfa_pending / mfa_setup)
+func GenerateMFAToken(userID uuid.UUID, roleName string, tokenType string) (string, error) {
+	claims := newClaims(userID, roleName, tokenType, MFATokenTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(getSecret())
+}
+
 func GenerateAccessToken(userID uuid.UUID, roleName string) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		RoleName: roleName,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)), // Expire 1 Jam
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
+	claims := newClaims(userID, roleName, TokenTypeAccess, 1*time.Hour) // Expire 1 Jam
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(getSecret())
 }
@@ -60,8 +88,8 @@ func GenerateAccessToken(userID uuid.UUID, roleName string) (string, error) {
 func ValidateToken(tokenString string) (*JWTClaims, error) {
 	
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+		return getSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return nil, err
@@ -75,15 +103,21 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, jwt.ErrSignatureInvalid
 }
 
-func GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		RoleName: "", 
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), 
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// ValidateTokenType sama seperti ValidateToken tapi juga memastikan claim "type" sesuai,
+// jadi refresh token tidak bisa dipakai sebagai access token dan sebaliknya
+func ValidateTokenType(tokenString string, tokenType string) (*JWTClaims, error) {
+	claims, err := ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidTokenType
 	}
+	return claims, nil
+}
+
+func GenerateRefreshToken(userID uuid.UUID) (string, error) {
+	claims := newClaims(userID, "", TokenTypeRefresh, RefreshTokenTTL)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(getSecret())
-}
\ No newline at end of file
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// PasswordPolicy aturan minimal password untuk register, ganti password dan reset password
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Denylist      map[string]bool
+}
+
+// commonPasswords daftar password paling sering dipakai, selalu ditolak
+var commonPasswords = []string{
+	"password", "password1", "password123", "12345678", "123456789", "1234567890",
+	"qwerty123", "qwertyuiop", "11111111", "00000000", "abcd1234", "iloveyou",
+	"admin123", "welcome1", "letmein1", "sunshine1", "football1", "monkey123",
+	"rahasia", "rahasia1", "bismillah", "bismillah1", "indonesia", "indonesia1",
+	"mahasiswa", "mahasiswa1", "universitas", "kampus123",
+}
+
+func DefaultPasswordPolicy() PasswordPolicy {
+	policy := PasswordPolicy{
+		MinLength:    8,
+		RequireDigit: true,
+		Denylist:     make(map[string]bool),
+	}
+	for _, p := range commonPasswords {
+		policy.Denylist[p] = true
+	}
+	return policy
+}
+
+// LoadDenylistFile menambah denylist dari file (satu password per baris)
+func (p *PasswordPolicy) LoadDenylistFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			p.Denylist[strings.ToLower(line)] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// Validate mengembalikan daftar pelanggaran, kosong berarti password lolos
+func (p PasswordPolicy) Validate(password string) []string {
+	var violations []string
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("password minimal %d karakter", p.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "password wajib mengandung huruf besar")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "password wajib mengandung huruf kecil")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "password wajib mengandung angka")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "password wajib mengandung simbol")
+	}
+
+	if p.Denylist[strings.ToLower(password)] {
+		violations = append(violations, "password terlalu umum, gunakan password lain")
+	}
+
+	return violations
+}